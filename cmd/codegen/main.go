@@ -26,24 +26,28 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Connect to database
-	db, err := database.Open("", cfg.Database.URL)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Database connection failed: %v\n", err)
-		os.Exit(1)
+	pluginCfg := map[string]any{
+		"config": cfg,
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close database: %v\n", err)
+
+	// Connect to database, unless the command reads the schema from files
+	if codegen.NeedsDatabase(os.Args[1], os.Args[2:]) {
+		db, err := database.Open("", cfg.Database.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Database connection failed: %v\n", err)
+			os.Exit(1)
 		}
-	}()
+		defer func() {
+			if err := db.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to close database: %v\n", err)
+			}
+		}()
+		pluginCfg["database"] = db
+	}
 
 	// Initialize plugin
 	p := codegen.NewPlugin()
-	err = p.Initialize(map[string]any{
-		"database": db,
-		"config":   cfg,
-	})
+	err = p.Initialize(pluginCfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize plugin: %v\n", err)
 		os.Exit(1)
@@ -70,6 +74,9 @@ func main() {
 			})
 
 			if !result.Success {
+				if result.Message != "" {
+					fmt.Println(result.Message)
+				}
 				fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
 				os.Exit(1)
 			}
@@ -88,16 +95,41 @@ func printUsage() {
 	fmt.Println("GoREST Code Generator")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  codegen <command>")
+	fmt.Println("  codegen <command> [flags]")
 	fmt.Println()
 	fmt.Println("Available Commands:")
-	fmt.Println("  models      Generate model structs from database schema")
-	fmt.Println("  resources   Generate REST API resources and DTOs from models")
-	fmt.Println("  openapi     Generate OpenAPI schema file")
-	fmt.Println("  all         Run all code generation steps")
+	fmt.Println("  models       Generate model structs from database schema")
+	fmt.Println("  resources    Generate REST API resources and DTOs from models")
+	fmt.Println("  openapi      Generate OpenAPI schema file")
+	fmt.Println("  verify       Type-check the generated models, DTOs and resources")
+	fmt.Println("  all          Run all code generation steps, and verify the generated code with --verify")
+	fmt.Println("  schema dump  Dump the database schema to a snapshot file")
+	fmt.Println("  migrate      Generate SQL migrations taking the database schema to the models")
+	fmt.Println("  check        Check that the models match the database schema, fail on drift")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --dry-run          Show which files would be created, updated or removed without writing them")
+	fmt.Println("  --diff             Print a unified diff against the files on disk, fail if any differ")
+	fmt.Println("  --force            Overwrite generated files modified by hand")
+	fmt.Println("  --tables           Comma-separated table patterns to generate, like users,post*")
+	fmt.Println("  --schema-files     Comma-separated SQL files to read the schema from instead of the database")
+	fmt.Println("  --migrations       Comma-separated migration directories to apply to an in-memory SQLite database")
+	fmt.Println("  --schema-snapshot  Schema snapshot to generate from, or to write with schema dump")
+	fmt.Println("  --name             Name of the migration written by migrate")
+	fmt.Println("  --drop-tables      Let migrate drop the tables without a model")
+	fmt.Println("  --verify           Type-check the generated code at the end of all")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  codegen models")
 	fmt.Println("  codegen resources")
 	fmt.Println("  codegen all")
+	fmt.Println("  codegen all --diff")
+	fmt.Println("  codegen all --verify")
+	fmt.Println("  codegen all --schema-files db/schema.sql")
+	fmt.Println("  codegen all --migrations migrations")
+	fmt.Println("  codegen schema dump --schema-snapshot schema.snapshot.json")
+	fmt.Println("  codegen all --schema-snapshot schema.snapshot.json")
+	fmt.Println("  codegen migrate --name add_posts")
+	fmt.Println("  codegen verify")
+	fmt.Println("  codegen check")
 }
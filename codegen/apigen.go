@@ -1,113 +1,143 @@
 package codegen
 
 import (
-	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
-func GenerateAPI(authCfg *AuthConfig) {
-	GenerateAPIWithSkip(authCfg, make(map[string]bool))
+func GenerateAPI(authCfg *AuthConfig) error {
+	return GenerateAPIWithSkip(authCfg, make(map[string]bool))
 }
 
-func GenerateAPIWithSkip(authCfg *AuthConfig, resourcesToSkip map[string]bool) {
-	cfg, err := LoadConfig()
+func GenerateAPIWithSkip(authCfg *AuthConfig, resourcesToSkip map[string]bool) error {
+	g, err := newDefaultGenerator()
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		return err
 	}
+	return g.GenerateAPIWithSkip(authCfg, resourcesToSkip)
+}
 
-	modelsDir, err := GetModelsPath(cfg)
-	if err != nil {
-		log.Fatalf("failed to get models path: %v", err)
-	}
+func (g *Generator) GenerateAPI(authCfg *AuthConfig) error {
+	return g.GenerateAPIWithSkip(authCfg, make(map[string]bool))
+}
 
-	apiDir, err := GetResourcesPath(cfg)
+func (g *Generator) GenerateAPIWithSkip(authCfg *AuthConfig, resourcesToSkip map[string]bool) error {
+	modelsDir, err := g.modelsDir()
 	if err != nil {
-		log.Fatalf("failed to get resources path: %v", err)
+		return err
 	}
 
-	dtosDir, err := GetDTOsPath(cfg)
+	apiDir, err := g.resourcesDir()
 	if err != nil {
-		log.Fatalf("failed to get DTOs path: %v", err)
+		return err
 	}
 
-	if err := os.MkdirAll(apiDir, 0755); err != nil {
-		log.Fatalf("failed to create resources dir: %v", err)
-	}
-
-	if err := os.MkdirAll(dtosDir, 0755); err != nil {
-		log.Fatalf("failed to create api/dtos dir: %v", err)
+	dtosDir, err := g.dtosDir()
+	if err != nil {
+		return err
 	}
 
-	files, err := os.ReadDir(modelsDir)
+	files, err := g.Output.ReadDir(modelsDir)
 	if err != nil {
-		log.Fatalf("failed to read models dir: %v", err)
+		return &ParseError{Path: modelsDir, Err: err}
 	}
 
 	// Track generated resources for route registration
 	var generatedResources []string
+	opts := g.tableOptions()
 
 	for _, file := range files {
-		if !strings.HasSuffix(file.Name(), ".go") {
+		if !strings.HasSuffix(file, ".go") {
 			continue
 		}
 
-		filePath := filepath.Join(modelsDir, file.Name())
-		structs := parseStructs(filePath)
+		filePath := filepath.Join(modelsDir, file)
+		src, err := g.Output.ReadFile(filePath)
+		if err != nil {
+			return &ParseError{Path: filePath, Err: err}
+		}
+		structs, err := parseStructsSource(filePath, src)
+		if err != nil {
+			return err
+		}
+		tables, err := extractTableNamesSource(filePath, src)
+		if err != nil {
+			return err
+		}
 
 		for _, s := range structs {
 			resourceName := strings.ToLower(s)
-			if resourcesToSkip[resourceName] {
+			if resourcesToSkip[resourceName] || !opts.Includes(tables[s]) {
 				log.Printf("⏭️  Skipping resource: %s", resourceName)
 				continue
 			}
-			generateDTOForStruct(dtosDir, s)
-			generateResourceForStruct(apiDir, s, authCfg)
+			if !g.selected(tables[s]) {
+				// Resources left out of the selection stay registered
+				if _, err := g.Output.ReadFile(filepath.Join(apiDir, resourceName+".go")); err == nil {
+					generatedResources = append(generatedResources, s)
+				}
+				continue
+			}
+			if err := g.generateDTOForStruct(dtosDir, s); err != nil {
+				return err
+			}
+			if err := g.generateResourceForStruct(apiDir, s, authCfg); err != nil {
+				return err
+			}
 			generatedResources = append(generatedResources, s)
 		}
 	}
 
+	if err := g.generateValidationFile(filepath.Join(apiDir, "validation.go")); err != nil {
+		return err
+	}
+	if g.sqlNullFields {
+		if err := g.generateNullableFile(filepath.Join(apiDir, "nullable.go")); err != nil {
+			return err
+		}
+	}
+
 	// Generate routes.go
-	generateRoutesFile(generatedResources, authCfg)
+	if err := g.generateRoutesFile(filepath.Join(apiDir, "routes.go"), generatedResources); err != nil {
+		return err
+	}
+	return g.syncManifest(dtosDir, apiDir)
 }
 
-// generateRoutesFile generates the routes.go file with auto-registered routes
-func generateRoutesFile(resources []string, authCfg *AuthConfig) {
-	cfg, err := LoadConfig()
+// generateValidationFile generates the validation.go file shared by the
+// resources to report request validation errors
+func (g *Generator) generateValidationFile(validationPath string) error {
+	code, err := g.renderTemplate(ValidationTemplate, nil)
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		return err
 	}
 
-	routesPath, err := GetRoutesPath(cfg)
+	written, err := g.writeGenerated(validationPath, []byte(code))
 	if err != nil {
-		log.Fatalf("failed to get routes path: %v", err)
+		return err
 	}
-
-	var registrations strings.Builder
-	for _, resource := range resources {
-		registrations.WriteString(fmt.Sprintf("\tRegister%sRoutes(app, db, paginationLimit, paginationMaxLimit, pluginRegistry)\n", resource))
+	if written && !g.Output.DryRun {
+		log.Printf("✅ Generated request validation → %s", validationPath)
 	}
+	return nil
+}
 
-	code := fmt.Sprintf(`// Code generated by GoREST. DO NOT EDIT.
-
-package resources
-
-import (
-	"github.com/gofiber/fiber/v2"
-	"github.com/nicolasbonnici/gorest/database"
-	"github.com/nicolasbonnici/gorest/plugin"
-)
-
-func RegisterGeneratedRoutes(app *fiber.App, db database.Database, paginationLimit, paginationMaxLimit int, pluginRegistry *plugin.PluginRegistry) {
-%s}
-`, registrations.String())
+// generateRoutesFile generates the routes.go file with auto-registered routes
+func (g *Generator) generateRoutesFile(routesPath string, resources []string) error {
+	code, err := g.renderTemplate(RoutesTemplate, RoutesTemplateData{Resources: resources})
+	if err != nil {
+		return err
+	}
 
-	if err := os.WriteFile(routesPath, []byte(code), 0644); err != nil {
-		log.Fatalf("failed to write routes.go: %v", err)
+	written, err := g.writeGenerated(routesPath, []byte(code))
+	if err != nil {
+		return err
+	}
+	if written && !g.Output.DryRun {
+		log.Printf("🔀 Generated route registration → %s", routesPath)
 	}
-	log.Printf("🔀 Generated route registration → %s", routesPath)
+	return nil
 }
 
 // All functions moved to separate files:
@@ -10,9 +10,16 @@ import (
 )
 
 func TestGenerateAPI(t *testing.T) {
-	tables := LoadSchema(db)
-	GenerateStructs(tables)
-	GenerateAPI(NoAuthConfig())
+	tables, err := LoadSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if err := GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate structs: %v", err)
+	}
+	if err := GenerateAPI(NoAuthConfig()); err != nil {
+		t.Fatalf("Failed to generate API: %v", err)
+	}
 
 	projectRoot, err := findProjectRoot()
 	if err != nil {
@@ -92,7 +99,10 @@ func SomeFunction() {}
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	structs := parseStructs(testFile)
+	structs, err := parseStructs(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse structs: %v", err)
+	}
 	if len(structs) != 2 {
 		t.Errorf("Expected 2 structs, got %d", len(structs))
 	}
@@ -127,7 +137,15 @@ func TestGenerateResourceFromModel(t *testing.T) {
 		{Name: "CreatedAt", Type: "time.Time", JSONTag: "created_at,omitempty", DBTag: "created_at", IsPointer: true},
 	}
 
-	result := generateResourceFromModel("User", testFields, NoAuthConfig())
+	g, err := newDefaultGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	result, err := g.generateResourceFromModel("User", testFields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
 
 	expectedStrings := []string{
 		"package resources",
@@ -192,8 +210,15 @@ func (TestModel) TableName() string {
 	}
 	defer os.Remove(modelFile) // Clean up after test
 
+	g, err := newDefaultGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
 	resourcesDir := filepath.Join(projectRoot, "test/generated/resources")
-	generateResourceForStruct(resourcesDir, "TestModel", NoAuthConfig())
+	if err := g.generateResourceForStruct(resourcesDir, "TestModel", NoAuthConfig()); err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
 
 	resourceFile := filepath.Join(resourcesDir, "testmodel.go")
 	if _, err := os.Stat(resourceFile); os.IsNotExist(err) {
@@ -218,9 +243,16 @@ func (TestModel) TableName() string {
 
 func TestGeneratedResourcesCRUDIntegration(t *testing.T) {
 
-	tables := LoadSchema(db)
-	GenerateStructs(tables)
-	GenerateAPI(NoAuthConfig())
+	tables, err := LoadSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if err := GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate structs: %v", err)
+	}
+	if err := GenerateAPI(NoAuthConfig()); err != nil {
+		t.Fatalf("Failed to generate API: %v", err)
+	}
 
 	projectRoot, err := findProjectRoot()
 	if err != nil {
@@ -306,7 +338,10 @@ type TestModel struct {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	fields := extractStructFields(testFile, "TestModel")
+	fields, err := extractStructFields(testFile, "TestModel")
+	if err != nil {
+		t.Fatalf("Failed to extract fields: %v", err)
+	}
 
 	if len(fields) != 5 {
 		t.Errorf("Expected 5 fields, got %d", len(fields))
@@ -382,6 +417,12 @@ func TestExtractTag(t *testing.T) {
 			key:      "dto",
 			expected: "read,write",
 		},
+		{
+			name:     "tag value with spaces",
+			tagStr:   "`json:\"status\" validate:\"oneof=draft 'in review'\"`",
+			key:      "validate",
+			expected: "oneof=draft 'in review'",
+		},
 		{
 			name:     "missing tag",
 			tagStr:   "`json:\"id\" db:\"id\"`",
@@ -4,25 +4,45 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"log"
+	"go/types"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
 type StructField struct {
-	Name      string
-	Type      string
-	JSONTag   string
-	DBTag     string
-	DTOTag    string
-	IsPointer bool
+	Name         string
+	Type         string
+	JSONTag      string
+	DBTag        string
+	DTOTag       string
+	IsPointer    bool
+	IsPrimaryKey bool
+	// ForeignKey is the parent table.column referenced by the field, read
+	// from its fk tag
+	ForeignKey string
+	// Relation is the foreign key column of a relation field, read from its
+	// rel tag
+	Relation string
+	// ValidateTag holds the validation rules of the field, copied to the write
+	// DTOs
+	ValidateTag string
+	// NullType is the model type of a nullable field stored as a sql.Null* or
+	// Optional type, like sql.NullString, Type holding the type of its value
+	NullType string
 }
 
-func parseStructs(path string) []string {
+func parseStructs(path string) ([]string, error) {
+	return parseStructsSource(path, nil)
+}
+
+// parseStructsSource parses src, or the file at path when src is nil, following
+// the parser.ParseFile convention
+func parseStructsSource(path string, src any) ([]string, error) {
 	fs := token.NewFileSet()
-	node, err := parser.ParseFile(fs, path, nil, parser.AllErrors)
+	node, err := parser.ParseFile(fs, path, src, parser.AllErrors)
 	if err != nil {
-		log.Printf("parse error in %s: %v", path, err)
-		return nil
+		return nil, &ParseError{Path: path, Err: err}
 	}
 
 	structs := []string{}
@@ -36,20 +56,94 @@ func parseStructs(path string) []string {
 			if !ok {
 				continue
 			}
-			if _, ok := ts.Type.(*ast.StructType); ok {
+			// Generic types, like Optional, are not models
+			if _, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil {
 				structs = append(structs, ts.Name.Name)
 			}
 		}
 	}
-	return structs
+	return structs, nil
 }
 
-func extractStructFields(path string, structName string) []StructField {
+// extractTableNamesSource returns the table names returned by the TableName
+// methods declared in src, keyed by receiver type. Structs without one map to
+// their pluralized snake case name.
+func extractTableNamesSource(path string, src any) (map[string]string, error) {
+	structs, err := parseStructsSource(path, src)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]string, len(structs))
+	for _, s := range structs {
+		tables[s] = defaultTableName(s)
+	}
+
 	fs := token.NewFileSet()
-	node, err := parser.ParseFile(fs, path, nil, parser.AllErrors)
+	node, err := parser.ParseFile(fs, path, src, parser.AllErrors)
 	if err != nil {
-		log.Printf("parse error in %s: %v", path, err)
-		return nil
+		return nil, &ParseError{Path: path, Err: err}
+	}
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil || len(fn.Body.List) != 1 {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		ident, ok := recv.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		lit, ok := ret.Results[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		if table, err := strconv.Unquote(lit.Value); err == nil {
+			tables[ident.Name] = table
+		}
+	}
+	return tables, nil
+}
+
+// extractImportsSource returns the import paths of src keyed by the name they
+// are referred to in the file
+func extractImportsSource(path string, src any) (map[string]string, error) {
+	fs := token.NewFileSet()
+	node, err := parser.ParseFile(fs, path, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, &ParseError{Path: path, Err: err}
+	}
+
+	imports := make(map[string]string, len(node.Imports))
+	for _, spec := range node.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports, nil
+}
+
+func extractStructFields(path string, structName string) ([]StructField, error) {
+	return extractStructFieldsSource(path, nil, structName)
+}
+
+func extractStructFieldsSource(path string, src any, structName string) ([]StructField, error) {
+	fs := token.NewFileSet()
+	node, err := parser.ParseFile(fs, path, src, parser.AllErrors)
+	if err != nil {
+		return nil, &ParseError{Path: path, Err: err}
 	}
 
 	var fields []StructField
@@ -72,66 +166,56 @@ func extractStructFields(path string, structName string) []StructField {
 					continue
 				}
 				fieldName := field.Names[0].Name
-				fieldType := ""
-				isPointer := false
-
-				switch t := field.Type.(type) {
-				case *ast.Ident:
-					fieldType = t.Name
-				case *ast.StarExpr:
-					isPointer = true
-					if ident, ok := t.X.(*ast.Ident); ok {
-						fieldType = ident.Name
-					} else if sel, ok := t.X.(*ast.SelectorExpr); ok {
-						if pkg, ok := sel.X.(*ast.Ident); ok {
-							fieldType = pkg.Name + "." + sel.Sel.Name
-						}
-					}
-				case *ast.SelectorExpr:
-					if pkg, ok := t.X.(*ast.Ident); ok {
-						fieldType = pkg.Name + "." + t.Sel.Name
-					}
-				case *ast.InterfaceType:
-					fieldType = "interface{}"
-				case *ast.MapType:
-					fieldType = "map[string]interface{}"
-				}
+				fieldType, isPointer := fieldTypeString(field.Type)
 
 				jsonTag := ""
 				dbTag := ""
 				dtoTag := ""
+				isPrimaryKey := false
+				foreignKey := ""
+				relation := ""
+				validateTag := ""
 				if field.Tag != nil {
 					tag := field.Tag.Value
 					jsonTag = extractTag(tag, "json")
 					jsonTag = strings.Split(jsonTag, ",")[0]
 					dbTag = extractTag(tag, "db")
 					dtoTag = extractTag(tag, "dto")
+					isPrimaryKey = extractTag(tag, "pk") == "true"
+					foreignKey = extractTag(tag, "fk")
+					relation = extractTag(tag, "rel")
+					validateTag = extractTag(tag, "validate")
 				}
 
 				fields = append(fields, StructField{
-					Name:      fieldName,
-					Type:      fieldType,
-					JSONTag:   jsonTag,
-					DBTag:     dbTag,
-					DTOTag:    dtoTag,
-					IsPointer: isPointer,
+					Name:         fieldName,
+					Type:         fieldType,
+					JSONTag:      jsonTag,
+					DBTag:        dbTag,
+					DTOTag:       dtoTag,
+					IsPointer:    isPointer,
+					IsPrimaryKey: isPrimaryKey,
+					ForeignKey:   foreignKey,
+					Relation:     relation,
+					ValidateTag:  validateTag,
 				})
 			}
 		}
 	}
-	return fields
+	return fields, nil
 }
 
-func extractTag(tagString, key string) string {
-	tagString = strings.Trim(tagString, "`")
-	for _, tag := range strings.Fields(tagString) {
-		if strings.HasPrefix(tag, key+":") {
-			value := strings.TrimPrefix(tag, key+":")
-			value = strings.Trim(value, `"`)
-			return value
-		}
+// fieldTypeString returns the type of a struct field as written in the source,
+// like []uuid.UUID or map[string]interface{}, without its outer pointer
+func fieldTypeString(expr ast.Expr) (fieldType string, isPointer bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, isPointer = star.X, true
 	}
-	return ""
+	return types.ExprString(expr), isPointer
+}
+
+func extractTag(tagString, key string) string {
+	return reflect.StructTag(strings.Trim(tagString, "`")).Get(key)
 }
 
 func extractStructFieldsFromAST(st *ast.StructType) []StructField {
@@ -143,61 +227,44 @@ func extractStructFieldsFromAST(st *ast.StructType) []StructField {
 		}
 
 		fieldName := field.Names[0].Name
-		fieldType := ""
-		isPointer := false
-
-		switch t := field.Type.(type) {
-		case *ast.Ident:
-			fieldType = t.Name
-		case *ast.StarExpr:
-			isPointer = true
-			if ident, ok := t.X.(*ast.Ident); ok {
-				fieldType = ident.Name
-			} else if sel, ok := t.X.(*ast.SelectorExpr); ok {
-				if pkg, ok := sel.X.(*ast.Ident); ok {
-					fieldType = pkg.Name + "." + sel.Sel.Name
-				}
-			}
-		case *ast.SelectorExpr:
-			if pkg, ok := t.X.(*ast.Ident); ok {
-				fieldType = pkg.Name + "." + t.Sel.Name
-			}
-		case *ast.InterfaceType:
-			fieldType = "interface{}"
-		case *ast.MapType:
-			fieldType = "map[string]interface{}"
-		}
+		fieldType, isPointer := fieldTypeString(field.Type)
 
 		jsonTag := ""
 		dbTag := ""
 		dtoTag := ""
+		validateTag := ""
 		if field.Tag != nil {
 			tag := field.Tag.Value
 			jsonTag = extractTag(tag, "json")
 			jsonTag = strings.Split(jsonTag, ",")[0]
 			dbTag = extractTag(tag, "db")
 			dtoTag = extractTag(tag, "dto")
+			validateTag = extractTag(tag, "validate")
 		}
 
 		fields = append(fields, StructField{
-			Name:      fieldName,
-			Type:      fieldType,
-			JSONTag:   jsonTag,
-			DBTag:     dbTag,
-			DTOTag:    dtoTag,
-			IsPointer: isPointer,
+			Name:        fieldName,
+			Type:        fieldType,
+			JSONTag:     jsonTag,
+			DBTag:       dbTag,
+			DTOTag:      dtoTag,
+			IsPointer:   isPointer,
+			ValidateTag: validateTag,
 		})
 	}
 
 	return fields
 }
 
-func extractDTOsFromResourceFile(path string) map[string]DTOSchema {
+func extractDTOsFromResourceFile(path string) (map[string]DTOSchema, error) {
+	return extractDTOsFromSource(path, nil)
+}
+
+func extractDTOsFromSource(path string, src any) (map[string]DTOSchema, error) {
 	fs := token.NewFileSet()
-	node, err := parser.ParseFile(fs, path, nil, parser.AllErrors)
+	node, err := parser.ParseFile(fs, path, src, parser.AllErrors)
 	if err != nil {
-		log.Printf("parse error in %s: %v", path, err)
-		return nil
+		return nil, &ParseError{Path: path, Err: err}
 	}
 
 	dtos := make(map[string]DTOSchema)
@@ -231,5 +298,5 @@ func extractDTOsFromResourceFile(path string) map[string]DTOSchema {
 		}
 	}
 
-	return dtos
+	return dtos, nil
 }
@@ -0,0 +1,78 @@
+package codegen
+
+import "testing"
+
+func TestExtractStructFieldsPrimaryKeyTag(t *testing.T) {
+	src := "package models\n\ntype OrderItem struct {\n" +
+		"\tOrderId int `json:\"orderId,omitempty\" db:\"order_id\" pk:\"true\"`\n" +
+		"\tQuantity int `json:\"quantity\" db:\"quantity\"`\n" +
+		"}\n"
+
+	fields, err := extractStructFieldsSource("orderitem.go", src, "OrderItem")
+	if err != nil {
+		t.Fatalf("Failed to extract fields: %v", err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("Expected 2 fields, got %d", len(fields))
+	}
+	if !fields[0].IsPrimaryKey || fields[0].DBTag != "order_id" {
+		t.Errorf("Expected order_id to be a primary key field, got %+v", fields[0])
+	}
+	if fields[1].IsPrimaryKey {
+		t.Errorf("Expected quantity not to be a primary key field, got %+v", fields[1])
+	}
+}
+
+func TestExtractStructFieldsRelationTags(t *testing.T) {
+	src := "package models\n\ntype Post struct {\n" +
+		"\tAuthorId int `json:\"authorId\" db:\"author_id\" fk:\"users.id\"`\n" +
+		"\tAuthor *User `json:\"author,omitempty\" rel:\"author_id\" dto:\"-\"`\n" +
+		"}\n"
+
+	fields, err := extractStructFieldsSource("post.go", src, "Post")
+	if err != nil {
+		t.Fatalf("Failed to extract fields: %v", err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("Expected 2 fields, got %d", len(fields))
+	}
+	if fields[0].ForeignKey != "users.id" {
+		t.Errorf("Expected author_id to reference users.id, got %q", fields[0].ForeignKey)
+	}
+	if fields[1].Relation != "author_id" || fields[1].Type != "User" || !fields[1].IsPointer || fields[1].DTOTag != "-" {
+		t.Errorf("Expected Author to be a relation field on author_id, got %+v", fields[1])
+	}
+}
+
+func TestExtractStructFieldsSliceTypes(t *testing.T) {
+	src := "package models\n\nimport (\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)\n\ntype Document struct {\n" +
+		"\tTags []string `json:\"tags\" db:\"tags\"`\n" +
+		"\tRefs []uuid.UUID `json:\"refs\" db:\"refs\"`\n" +
+		"\tStamps *[]time.Time `json:\"stamps\" db:\"stamps\"`\n" +
+		"\tGrid [][]int `json:\"grid\" db:\"grid\"`\n" +
+		"}\n"
+
+	tests := []struct {
+		name      string
+		fieldType string
+		isPointer bool
+	}{
+		{"Tags", "[]string", false},
+		{"Refs", "[]uuid.UUID", false},
+		{"Stamps", "[]time.Time", true},
+		{"Grid", "[][]int", false},
+	}
+
+	fields, err := extractStructFieldsSource("document.go", src, "Document")
+	if err != nil {
+		t.Fatalf("Failed to extract fields: %v", err)
+	}
+	if len(fields) != len(tests) {
+		t.Fatalf("Expected %d fields, got %d", len(tests), len(fields))
+	}
+	for i, tt := range tests {
+		if fields[i].Name != tt.name || fields[i].Type != tt.fieldType || fields[i].IsPointer != tt.isPointer {
+			t.Errorf("Expected %s of type %s (pointer %v), got %+v", tt.name, tt.fieldType, tt.isPointer, fields[i])
+		}
+	}
+}
@@ -120,8 +120,8 @@ func DefaultAuthConfig() *AuthConfig {
 	return &AuthConfig{
 		Enabled: true,
 		RequireAuth: map[string][]string{
-			"users": {"GET", "POST", "PUT", "DELETE"},
-			"todos": {"GET", "POST", "PUT", "DELETE"},
+			"users": {"GET", "POST", "PUT", "DELETE", "PATCH"},
+			"todos": {"GET", "POST", "PUT", "DELETE", "PATCH"},
 		},
 	}
 }
@@ -0,0 +1,105 @@
+package codegen
+
+import "fmt"
+
+// DriftProblem is a difference between the schema and the models on disk
+type DriftProblem struct {
+	Table string
+	// Column is empty when the whole table drifted
+	Column  string
+	Message string
+}
+
+func (p DriftProblem) String() string {
+	if p.Column != "" {
+		return fmt.Sprintf("%s.%s: %s", p.Table, p.Column, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Table, p.Message)
+}
+
+// CheckSchema compares tables, the current schema, with the models of the
+// models directory and returns a DriftError listing the tables without a
+// model or without a table, the columns missing on either side, and the
+// columns whose field no longer has the type or nullability the models
+// command would generate for them. Tables excluded or left out of the
+// selection are ignored.
+func (g *Generator) CheckSchema(tables map[string]TableSchema) error {
+	models, err := g.loadModelTables()
+	if err != nil {
+		return err
+	}
+	current := g.modelSchemaTables(tables)
+
+	var problems []DriftProblem
+	for _, name := range sortedKeys(current) {
+		model, ok := models[name]
+		if !ok {
+			problems = append(problems, DriftProblem{Table: name, Message: "table has no model"})
+			continue
+		}
+		tableProblems, err := g.checkModelTable(model, current[name])
+		if err != nil {
+			return err
+		}
+		problems = append(problems, tableProblems...)
+	}
+	for _, name := range sortedKeys(models) {
+		if _, ok := current[name]; !ok {
+			problems = append(problems, DriftProblem{Table: name, Message: fmt.Sprintf("table of model %s is missing from the schema", models[name].Struct)})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &DriftError{Problems: problems}
+	}
+	fmt.Println("✅ Models match the schema")
+	return nil
+}
+
+// checkModelTable compares the fields of a model with the columns of its table
+func (g *Generator) checkModelTable(model modelTable, table TableSchema) ([]DriftProblem, error) {
+	fields := make(map[string]StructField, len(model.Fields))
+	for _, field := range model.Fields {
+		fields[field.DBTag] = field
+	}
+
+	var problems []DriftProblem
+	columns := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[col.Name] = true
+		field, ok := fields[col.Name]
+		if !ok {
+			problems = append(problems, DriftProblem{Table: model.Table, Column: col.Name, Message: fmt.Sprintf("column has no field in model %s", model.Struct)})
+			continue
+		}
+
+		expected, _, err := g.fieldGoType(table.TableName, col)
+		if err != nil {
+			return nil, err
+		}
+		fieldType := fieldGoTypeString(field)
+		if expected == fieldType {
+			continue
+		}
+		expectedBase, expectedNullable := splitNullableGoType(expected)
+		base, nullable := splitNullableGoType(fieldType)
+		switch {
+		case base != expectedBase:
+			problems = append(problems, DriftProblem{Table: model.Table, Column: col.Name, Message: fmt.Sprintf("field %s.%s is %s, want %s", model.Struct, field.Name, fieldType, expected)})
+		// Nil slices and maps cannot tell a nullable column apart
+		case nullable != expectedNullable && !holdsNil(base):
+			nullability := "NOT NULL"
+			if col.IsNullable {
+				nullability = "nullable"
+			}
+			problems = append(problems, DriftProblem{Table: model.Table, Column: col.Name, Message: fmt.Sprintf("column is %s but field %s.%s is %s", nullability, model.Struct, field.Name, fieldType)})
+		}
+	}
+
+	for _, field := range model.Fields {
+		if !columns[field.DBTag] {
+			problems = append(problems, DriftProblem{Table: model.Table, Column: field.DBTag, Message: fmt.Sprintf("field %s.%s has no column", model.Struct, field.Name)})
+		}
+	}
+	return problems, nil
+}
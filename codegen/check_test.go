@@ -0,0 +1,97 @@
+package codegen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckSchema(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE users (
+	id serial PRIMARY KEY,
+	email varchar(255),
+	age integer NOT NULL,
+	bio text NOT NULL,
+	legacy text
+);
+CREATE TABLE tags (id serial PRIMARY KEY, labels text[]);
+CREATE TABLE audits (id serial PRIMARY KEY);
+`)
+	g := newMigrateGenerator(t, DialectPostgres, map[string]string{
+		"user.go": migrateUserModel,
+		"post.go": migratePostModel,
+		"tag.go": "package models\n\ntype Tag struct {\n" +
+			"\tId     int      `db:\"id\" pk:\"true\"`\n" +
+			"\tLabels []string `db:\"labels\"`\n" +
+			"}\n",
+	})
+	g.Options.Tables.Exclude = []string{"audits"}
+
+	err := g.CheckSchema(tables)
+	var driftErr *DriftError
+	if !errors.As(err, &driftErr) {
+		t.Fatalf("Expected DriftError, got %v", err)
+	}
+	want := []DriftProblem{
+		{Table: "users", Column: "age", Message: "field User.Age is int64, want int"},
+		{Table: "users", Column: "bio", Message: "column is NOT NULL but field User.Bio is *string"},
+		{Table: "users", Column: "legacy", Message: "column has no field in model User"},
+		{Table: "posts", Message: "table of model Post is missing from the schema"},
+	}
+	if !reflect.DeepEqual(driftErr.Problems, want) {
+		t.Errorf("Expected problems\n%+v\ngot\n%+v", want, driftErr.Problems)
+	}
+}
+
+func TestCheckSchemaMissingTableAndColumn(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE users (id serial PRIMARY KEY, email varchar(255), age integer NOT NULL, bio text);
+CREATE TABLE comments (id serial PRIMARY KEY);
+`)
+	g := newMigrateGenerator(t, DialectPostgres, map[string]string{
+		"user.go": "package models\n\ntype User struct {\n" +
+			"\tId    int     `db:\"id\" pk:\"true\"`\n" +
+			"\tEmail *string `db:\"email\"`\n" +
+			"\tAge   int     `db:\"age\"`\n" +
+			"\tBio   *string `db:\"bio\"`\n" +
+			"\tPhone *string `db:\"phone\"`\n" +
+			"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+	})
+
+	err := g.CheckSchema(tables)
+	var driftErr *DriftError
+	if !errors.As(err, &driftErr) {
+		t.Fatalf("Expected DriftError, got %v", err)
+	}
+	want := []DriftProblem{
+		{Table: "comments", Message: "table has no model"},
+		{Table: "users", Column: "phone", Message: "field User.Phone has no column"},
+	}
+	if !reflect.DeepEqual(driftErr.Problems, want) {
+		t.Errorf("Expected problems\n%+v\ngot\n%+v", want, driftErr.Problems)
+	}
+
+	// Tables left out of the selection are not reported
+	g.Tables = []string{"users"}
+	err = g.CheckSchema(tables)
+	if !errors.As(err, &driftErr) || len(driftErr.Problems) != 1 {
+		t.Errorf("Expected only the users drift, got %v", err)
+	}
+}
+
+func TestCheckSchemaUpToDate(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TYPE status AS ENUM ('draft', 'published');
+CREATE TABLE users (id serial PRIMARY KEY, email varchar(255) NOT NULL, created_at timestamptz NOT NULL DEFAULT now());
+CREATE TABLE posts (id serial PRIMARY KEY, user_id integer REFERENCES users, status status NOT NULL, tags text[]);
+`)
+	g := newMigrateGenerator(t, DialectPostgres, nil)
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	if err := g.CheckSchema(tables); err != nil {
+		t.Errorf("Expected no drift for models generated from the schema, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generatedHeader marks generated files, following the convention recognised
+// by Go tools and linters
+const generatedHeader = "Code generated by GoREST. DO NOT EDIT."
+
+// checksumPrefix starts the header line holding the checksum of the content
+// following the header
+const checksumPrefix = "Checksum: sha256:"
+
+// commentPrefix returns the line comment marker of the file type of path
+func commentPrefix(path string) string {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return "#"
+	}
+	return "//"
+}
+
+// stampGenerated prefixes content with the generated header and the checksum of
+// the content. A header already present in content, like one left in an
+// overridden template, is replaced.
+func stampGenerated(path string, content []byte) []byte {
+	prefix := commentPrefix(path)
+	if _, body, ok := splitGeneratedHeader(prefix, content); ok {
+		content = body
+	} else if line, rest, _ := bytes.Cut(content, []byte("\n")); isGeneratedLine(prefix, string(line)) {
+		content = rest
+	}
+	body := append([]byte("\n"), bytes.TrimLeft(content, "\n")...)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s\n%s %s%s\n", prefix, generatedHeader, prefix, checksumPrefix, contentHash(body))
+	b.Write(body)
+	return b.Bytes()
+}
+
+// verifyGenerated reports whether content starts with the generated header and,
+// if so, whether the content still matches its checksum
+func verifyGenerated(path string, content []byte) (stamped, intact bool) {
+	checksum, body, ok := splitGeneratedHeader(commentPrefix(path), content)
+	if !ok {
+		return false, false
+	}
+	return true, checksum == contentHash(body)
+}
+
+// splitGeneratedHeader returns the checksum recorded in the generated header of
+// content and the content following the header
+func splitGeneratedHeader(prefix string, content []byte) (checksum string, body []byte, ok bool) {
+	first, rest, found := bytes.Cut(content, []byte("\n"))
+	if !found || !isGeneratedLine(prefix, string(first)) {
+		return "", nil, false
+	}
+	second, body, found := bytes.Cut(rest, []byte("\n"))
+	if !found {
+		return "", nil, false
+	}
+	checksum, ok = strings.CutPrefix(string(second), prefix+" "+checksumPrefix)
+	return checksum, body, ok
+}
+
+func isGeneratedLine(prefix, line string) bool {
+	return strings.HasPrefix(line, prefix+" Code generated ") && strings.HasSuffix(line, " DO NOT EDIT.")
+}
+
+// writeGenerated formats Go content, stamps it with the generated header and
+// writes it to path, unless the file on disk was modified since it was
+// generated and Force is not set. It reports whether the file was written.
+func (g *Generator) writeGenerated(path string, content []byte) (bool, error) {
+	path = filepath.Clean(path)
+	if !g.Force {
+		modified, err := g.modifiedSinceGenerated(path)
+		if err != nil {
+			return false, err
+		}
+		if modified {
+			if g.conflicts == nil {
+				g.conflicts = make(map[string]bool)
+			}
+			g.conflicts[path] = true
+			log.Printf("⚠️  Skipping %s: modified since it was generated, use --force to overwrite", path)
+			return false, nil
+		}
+	}
+	if filepath.Ext(path) == ".go" {
+		formatted, err := formatSource(path, content)
+		if err != nil {
+			return false, err
+		}
+		content = formatted
+	}
+	return true, g.Output.WriteFile(path, stampGenerated(path, content))
+}
+
+// modifiedSinceGenerated reports whether the file at path was edited after it
+// was generated: its content no longer matches the checksum of its header or,
+// for files generated without one, the hash recorded in the manifest
+func (g *Generator) modifiedSinceGenerated(path string) (bool, error) {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, &ParseError{Path: path, Err: err}
+	}
+	if stamped, intact := verifyGenerated(path, current); stamped {
+		return !intact, nil
+	}
+
+	manifest, err := g.loadManifest()
+	if err != nil {
+		return false, err
+	}
+	manifestPath, err := g.manifestPath()
+	if err != nil {
+		return false, err
+	}
+	entry, ok := manifest.Files[relativePath(filepath.Dir(manifestPath), path)]
+	return ok && entry.Hash != contentHash(current), nil
+}
@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStampGenerated(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		header  string
+	}{
+		{name: "go file", path: "user.go", content: "package models\n", header: "// Code generated by GoREST. DO NOT EDIT.\n// Checksum: sha256:"},
+		{name: "template header replaced", path: "routes.go", content: "// Code generated by GoREST. DO NOT EDIT.\n\npackage resources\n", header: "// Code generated by GoREST. DO NOT EDIT.\n// Checksum: sha256:"},
+		{name: "yaml file", path: "schema.yaml", content: "openapi: 3.1.0\n", header: "# Code generated by GoREST. DO NOT EDIT.\n# Checksum: sha256:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stamped := stampGenerated(tt.path, []byte(tt.content))
+			if !strings.HasPrefix(string(stamped), tt.header) {
+				t.Errorf("Expected header %q, got:\n%s", tt.header, stamped)
+			}
+			if strings.Count(string(stamped), "Code generated") != 1 {
+				t.Errorf("Expected a single generated header, got:\n%s", stamped)
+			}
+			if stamped, intact := verifyGenerated(tt.path, stamped); !stamped || !intact {
+				t.Errorf("Expected stamped content to verify, got stamped=%v intact=%v", stamped, intact)
+			}
+			if string(stampGenerated(tt.path, stamped)) != string(stamped) {
+				t.Error("Expected stamping to be idempotent")
+			}
+
+			edited := append(stamped, []byte("// edited\n")...)
+			if stamped, intact := verifyGenerated(tt.path, edited); !stamped || intact {
+				t.Errorf("Expected edited content to fail verification, got stamped=%v intact=%v", stamped, intact)
+			}
+		})
+	}
+
+	if stamped, _ := verifyGenerated("user.go", []byte("package models\n")); stamped {
+		t.Error("Expected content without header not to be reported as stamped")
+	}
+}
+
+func TestWriteGeneratedConflicts(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	userPath := filepath.Join(dir, "models", "user.go")
+	src, err := os.ReadFile(userPath)
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	edited := string(src) + "\nfunc (User) Greeting() string { return \"hi\" }\n"
+	if err := os.WriteFile(userPath, []byte(edited), 0644); err != nil {
+		t.Fatalf("Failed to edit model: %v", err)
+	}
+
+	g := newManifestTestGenerator(t, dir, false)
+	err = g.GenerateStructs(tables)
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected a ConflictError, got %v", err)
+	}
+	if len(conflictErr.Paths) != 1 || conflictErr.Paths[0] != userPath {
+		t.Errorf("Expected conflict on %s, got %v", userPath, conflictErr.Paths)
+	}
+	if current, _ := os.ReadFile(userPath); string(current) != edited {
+		t.Error("Expected the edited model to be left untouched")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "post.go")); err != nil {
+		t.Errorf("Expected other models to be generated: %v", err)
+	}
+
+	g = newManifestTestGenerator(t, dir, false)
+	g.Force = true
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to force generation: %v", err)
+	}
+	if current, _ := os.ReadFile(userPath); string(current) != string(src) {
+		t.Error("Expected the edited model to be overwritten when forced")
+	}
+}
+
+func TestWriteGeneratedUnstampedFiles(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	// Files generated before checksums were introduced are checked against
+	// the manifest
+	legacy := []byte("package models\n\ntype User struct{}\n")
+	userPath := filepath.Join(dir, "models", "user.go")
+	if err := os.WriteFile(userPath, legacy, 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+	manifest := readTestManifest(t, dir)
+	manifest.Files["models/user.go"] = ManifestEntry{Hash: contentHash(legacy)}
+	if err := writeManifest(filepath.Join(dir, ManifestFile), &manifest); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Errorf("Expected an unedited file without checksum to be overwritten, got %v", err)
+	}
+	if current, _ := os.ReadFile(userPath); string(current) == string(legacy) {
+		t.Error("Expected the model to be regenerated")
+	}
+}
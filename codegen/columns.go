@@ -0,0 +1,146 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ColumnOverride replaces the defaults derived from a column when writing the
+// model field. Overrides are written to the model tags, so they survive
+// regeneration unlike hand edits.
+type ColumnOverride struct {
+	// GoType is the Go type of the field. Types from other packages are
+	// written with their import path, like github.com/google/uuid.UUID, or
+	// with the package name for database/sql, encoding/json and well-known
+	// types like uuid.UUID.
+	GoType string `yaml:"go_type"`
+	// JSON is the JSON name of the field
+	JSON string `yaml:"json"`
+	// DTO is the visibility of the field in the DTOs: read, write or -
+	DTO string `yaml:"dto"`
+}
+
+var dtoVisibilities = []string{"read", "write", "-"}
+
+// knownPackages maps the package names accepted in go_type without their
+// import path
+var knownPackages = map[string]string{
+	"time": "time",
+	"sql":  "database/sql",
+	"json": "encoding/json",
+}
+
+// validateColumnOverrides checks the overrides keyed by table.column
+func validateColumnOverrides(overrides map[string]ColumnOverride) error {
+	for key, override := range overrides {
+		if table, column, ok := strings.Cut(key, "."); !ok || table == "" || column == "" {
+			return fmt.Errorf("invalid column %q, expected table.column", key)
+		}
+		if override.DTO != "" && !slices.Contains(dtoVisibilities, override.DTO) {
+			return fmt.Errorf("invalid dto visibility %q for column %s, expected one of %s", override.DTO, key, strings.Join(dtoVisibilities, ", "))
+		}
+		if override.GoType != "" {
+			if _, _, err := parseGoType(override.GoType); err != nil {
+				return fmt.Errorf("invalid go_type for column %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseGoType splits a configured Go type into the type written in the model,
+// like *uuid.UUID, and the import path it needs, empty for builtin types
+func parseGoType(goType string) (typ, importPath string, err error) {
+	base := strings.TrimLeft(goType, "*[]")
+	prefix := goType[:len(goType)-len(base)]
+	if base == "" {
+		return "", "", fmt.Errorf("missing type in %q", goType)
+	}
+
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return goType, "", nil
+	}
+	importPath, name := base[:dot], base[dot+1:]
+	if name == "" {
+		return "", "", fmt.Errorf("missing type name in %q", goType)
+	}
+	if !strings.Contains(importPath, "/") {
+		if info, ok := goTypes[base]; ok {
+			importPath = info.importPath
+		} else if known, ok := knownPackages[importPath]; ok {
+			importPath = known
+		} else {
+			return "", "", fmt.Errorf("unknown package %s in %q, use the full import path like github.com/google/uuid.UUID", importPath, goType)
+		}
+	}
+	return prefix + importName(importPath) + "." + name, importPath, nil
+}
+
+// importName returns the package name of an import path, leaving out major
+// version suffixes like /v2
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// typeImports returns the sorted import paths of the packages qualifying
+// types, resolved from known by package name
+func typeImports(types []string, known map[string]string) []string {
+	seen := make(map[string]bool)
+	for _, typ := range types {
+		for _, pkg := range typePackages(typ) {
+			if importPath, ok := known[pkg]; ok {
+				seen[importPath] = true
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for importPath := range seen {
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// typePackages returns the names of the packages qualifying a type, like sql
+// and uuid for sql.Null[uuid.UUID]
+func typePackages(typ string) []string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		base := strings.TrimLeft(typ, "*[]")
+		if pkg, _, ok := strings.Cut(base, "."); ok {
+			return []string{pkg}
+		}
+		return nil
+	}
+
+	var pkgs []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				pkgs = append(pkgs, pkg.Name)
+			}
+		}
+		return true
+	})
+	return pkgs
+}
+
+// columnOverride returns the override of a column, empty when none is
+// configured
+func (g *Generator) columnOverride(table, column string) ColumnOverride {
+	if g.Options == nil {
+		return ColumnOverride{}
+	}
+	return g.Options.Columns[table+"."+column]
+}
@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestParseGoType(t *testing.T) {
+	tests := []struct {
+		goType     string
+		typ        string
+		importPath string
+		wantErr    bool
+	}{
+		{goType: "int64", typ: "int64"},
+		{goType: "*string", typ: "*string"},
+		{goType: "sql.NullString", typ: "sql.NullString", importPath: "database/sql"},
+		{goType: "encoding/json.RawMessage", typ: "json.RawMessage", importPath: "encoding/json"},
+		{goType: "*github.com/google/uuid.UUID", typ: "*uuid.UUID", importPath: "github.com/google/uuid"},
+		{goType: "github.com/jackc/pgx/v5/pgtype.Text", typ: "pgtype.Text", importPath: "github.com/jackc/pgx/v5/pgtype"},
+		{goType: "github.com/shopspring/decimal/v2.Decimal", typ: "decimal.Decimal", importPath: "github.com/shopspring/decimal/v2"},
+		{goType: "uuid.UUID", typ: "uuid.UUID", importPath: "github.com/google/uuid"},
+		{goType: "[]decimal.Decimal", typ: "[]decimal.Decimal", importPath: "github.com/shopspring/decimal"},
+		{goType: "pgtype.Text", wantErr: true},
+		{goType: "sql.", wantErr: true},
+		{goType: "*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goType, func(t *testing.T) {
+			typ, importPath, err := parseGoType(tt.goType)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %s %s", typ, importPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if typ != tt.typ || importPath != tt.importPath {
+				t.Errorf("Expected %s from %q, got %s from %q", tt.typ, tt.importPath, typ, importPath)
+			}
+		})
+	}
+}
+
+func TestValidateColumnOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]ColumnOverride
+		wantErr   string
+	}{
+		{name: "valid", overrides: map[string]ColumnOverride{"users.password": {DTO: "write", JSON: "pwd", GoType: "sql.NullString"}}},
+		{name: "missing column", overrides: map[string]ColumnOverride{"users": {DTO: "read"}}, wantErr: `invalid column "users"`},
+		{name: "unknown visibility", overrides: map[string]ColumnOverride{"users.password": {DTO: "hidden"}}, wantErr: `invalid dto visibility "hidden"`},
+		{name: "unknown package", overrides: map[string]ColumnOverride{"users.id": {GoType: "pgtype.UUID"}}, wantErr: "invalid go_type for column users.id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateColumnOverrides(tt.overrides)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTypeImports(t *testing.T) {
+	known := map[string]string{"sql": "database/sql", "uuid": "github.com/google/uuid", "time": "time"}
+	got := typeImports([]string{"string", "*uuid.UUID", "sql.NullString", "[]uuid.UUID", "time.Time", "pgtype.Text"}, known)
+	want := []string{"database/sql", "github.com/google/uuid", "time"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected imports %v, got %v", want, got)
+	}
+}
+
+func TestGenerateStructsColumnOverrides(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	cfg.Codegen.Output.DTOs = filepath.Join(dir, "dtos")
+	g := NewGenerator(cfg, nil)
+	g.Options.Columns = map[string]ColumnOverride{
+		"users.password":   {DTO: "write", JSON: "pwd"},
+		"users.bio":        {GoType: "sql.NullString"},
+		"users.created_at": {GoType: "*github.com/google/uuid.UUID"},
+		"users.secret":     {JSON: "-"},
+	}
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{
+			{Name: "id", Type: "integer"},
+			{Name: "password", Type: "text"},
+			{Name: "bio", Type: "text", IsNullable: true},
+			{Name: "created_at", Type: "timestamp"},
+			{Name: "secret", Type: "text", IsNullable: true},
+		}},
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	model, err := os.ReadFile(filepath.Join(dir, "models", "user.go"))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	for _, want := range []string{
+		"import (\n\t\"database/sql\"\n\n\t\"github.com/google/uuid\"\n)",
+		"Password  string         `json:\"pwd\" db:\"password\" validate:\"required\" dto:\"write\"`",
+		"Bio       sql.NullString `json:\"bio,omitempty\" db:\"bio\"`",
+		"CreatedAt *uuid.UUID     `json:\"createdAt,omitempty\" db:\"created_at\"",
+		"Secret    *string        `json:\"-\" db:\"secret\" dto:\"-\"`",
+	} {
+		if !strings.Contains(string(model), want) {
+			t.Errorf("Expected model to contain %q, got:\n%s", want, model)
+		}
+	}
+	if strings.Contains(string(model), `"time"`) {
+		t.Errorf("Expected no time import once the timestamp type is overridden, got:\n%s", model)
+	}
+
+	fields, err := g.modelFields("User")
+	if err != nil {
+		t.Fatalf("Failed to read model fields: %v", err)
+	}
+	dto, err := g.generateDTOsFromModel("User", fields)
+	if err != nil {
+		t.Fatalf("Failed to generate DTOs: %v", err)
+	}
+	for _, want := range []string{
+		"import (\n\t\"database/sql\"\n\t\"github.com/google/uuid\"\n)",
+		"type UserDTO struct {\n\tId int `json:\"id\"`\n\tBio sql.NullString `json:\"bio\"`\n\tCreatedAt *uuid.UUID `json:\"createdAt\"`\n}",
+		"Password string `json:\"pwd\" validate:\"required\"`",
+	} {
+		if !strings.Contains(dto, want) {
+			t.Errorf("Expected DTOs to contain %q, got:\n%s", want, dto)
+		}
+	}
+	if strings.Contains(dto, "Secret") {
+		t.Errorf("Expected a field left out of JSON to be left out of the DTOs, got:\n%s", dto)
+	}
+}
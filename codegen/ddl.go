@@ -0,0 +1,1268 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LoadDDLSchema reads the schema from SQL files instead of a live database.
+// The CREATE TABLE, ALTER TABLE, CREATE TYPE ... AS ENUM, CREATE [UNIQUE]
+// INDEX, COMMENT ON COLUMN and DROP TABLE statements of the files are applied
+// in order, other statements being ignored, and column types are
+// reported the way the dialect introspects them, so that the generated code
+// matches a database the files were run against.
+func LoadDDLSchema(dialect string, paths ...string) (map[string]TableSchema, error) {
+	schema := newDDLSchema(dialect)
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		if err := schema.parse(path, string(src)); err != nil {
+			return nil, err
+		}
+	}
+	return schema.tables(), nil
+}
+
+// ddlSchema accumulates the tables declared by DDL statements
+type ddlSchema struct {
+	dialect string
+	// order lists the table names in declaration order, tables being keyed
+	// by name
+	order  []string
+	byName map[string]*ddlTable
+	// enums holds the labels of the enum types, keyed by type name
+	enums map[string][]string
+}
+
+type ddlTable struct {
+	schema  TableSchema
+	checks  []string
+	unique  map[string]bool
+	foreign []ddlForeignKey
+}
+
+func (t *ddlTable) hasColumn(name string) bool {
+	return t.columnIndex(name) >= 0
+}
+
+// columnIndex returns the position of a column, -1 when the table has none
+// of that name
+func (t *ddlTable) columnIndex(name string) int {
+	return slices.IndexFunc(t.schema.Columns, func(col Column) bool { return col.Name == name })
+}
+
+// ddlForeignKey is a foreign key whose parent columns are resolved once every
+// table is declared, REFERENCES users meaning the primary key of users
+type ddlForeignKey struct {
+	columns       []string
+	parentTable   string
+	parentColumns []string
+}
+
+func newDDLSchema(dialect string) *ddlSchema {
+	return &ddlSchema{
+		dialect: dialect,
+		byName:  make(map[string]*ddlTable),
+		enums:   make(map[string][]string),
+	}
+}
+
+// isPostgres reports whether the statements are read as PostgreSQL, which is
+// the default like for type mapping
+func (s *ddlSchema) isPostgres() bool {
+	return s.dialect != DialectMySQL && s.dialect != DialectSQLite
+}
+
+// parse applies the statements of src, read from path
+func (s *ddlSchema) parse(path, src string) error {
+	tokens, err := lexDDL(src)
+	if err != nil {
+		return &ParseError{Path: path, Err: err}
+	}
+	for _, stmt := range splitStatements(tokens) {
+		p := &ddlParser{schema: s, src: src, tokens: stmt}
+		if err := p.statement(); err != nil {
+			line := stmt[min(p.pos, len(stmt)-1)].line
+			return &ParseError{Path: path, Err: fmt.Errorf("line %d: %w", line, err)}
+		}
+	}
+	return nil
+}
+
+// tables returns the declared tables with their enum types and foreign keys
+// resolved
+func (s *ddlSchema) tables() map[string]TableSchema {
+	tables := make(map[string]TableSchema, len(s.order))
+	for _, name := range s.order {
+		t := s.byName[name]
+		table := t.schema
+		table.Columns = slices.Clone(table.Columns)
+		table.Indexes = slices.Clone(table.Indexes)
+		for i := range table.Columns {
+			col := &table.Columns[i]
+			col.IsPrimaryKey = slices.Contains(table.PrimaryKey, col.Name)
+			// Like the introspected schema, primary keys are never nullable,
+			// even in SQLite
+			if col.IsPrimaryKey {
+				col.IsNullable = false
+			}
+			col.IsUnique = t.unique[col.Name]
+			if labels, ok := s.enums[col.Type]; ok && s.isPostgres() {
+				col.EnumType = col.Type
+				col.EnumValues = labels
+				col.TypeDef = "enum(" + quoteSQLStrings(labels) + ")"
+			}
+			if col.EnumValues == nil {
+				col.EnumValues = parseCheckEnum(col.Name, strings.Join(t.checks, " "))
+			}
+		}
+
+		table.Relations = nil
+		for _, fk := range t.foreign {
+			parentColumns := fk.parentColumns
+			if len(parentColumns) == 0 {
+				if parent, ok := s.byName[fk.parentTable]; ok {
+					parentColumns = parent.schema.PrimaryKey
+				}
+			}
+			// Composite keys pair their columns by position
+			for i, column := range fk.columns {
+				if i >= len(parentColumns) {
+					break
+				}
+				table.Relations = append(table.Relations, Relation{
+					ChildTable:   table.TableName,
+					ChildColumn:  column,
+					ParentTable:  fk.parentTable,
+					ParentColumn: parentColumns[i],
+				})
+			}
+		}
+		tables[name] = table
+	}
+	return tables
+}
+
+func quoteSQLStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ",")
+}
+
+// DDL token kinds
+const (
+	tokenWord = iota
+	tokenQuoted
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type ddlToken struct {
+	kind int
+	// text is the value of the token, without the quotes of identifiers and
+	// strings
+	text string
+	// start and end are the offsets of the token in the source
+	start, end int
+	line       int
+}
+
+// lexDDL splits SQL source into tokens, skipping whitespace and comments
+func lexDDL(src string) ([]ddlToken, error) {
+	var tokens []ddlToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		start, startLine := i, line
+		switch {
+		case c == '\n':
+			line++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+			continue
+		case strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			text, n, ok := readQuoted(src[i:], c)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated %c quote", line, c)
+			}
+			kind := tokenQuoted
+			if c == '\'' {
+				kind = tokenString
+			}
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+			tokens = append(tokens, ddlToken{kind: kind, text: text, start: start, end: i, line: startLine})
+			continue
+		case c == '$':
+			// Dollar quoted strings, like function bodies: $$...$$ or $tag$...$tag$
+			tagEnd := strings.IndexByte(src[i+1:], '$')
+			if tagEnd < 0 || !isWordTag(src[i+1:i+1+tagEnd]) {
+				break
+			}
+			tag := src[i : i+tagEnd+2]
+			end := strings.Index(src[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar quote", line)
+			}
+			text := src[i+len(tag) : i+len(tag)+end]
+			line += strings.Count(text, "\n")
+			i += len(tag) + end + len(tag)
+			tokens = append(tokens, ddlToken{kind: tokenString, text: text, start: start, end: i, line: startLine})
+			continue
+		case isWordStart(c):
+			for i < len(src) && (isWordStart(src[i]) || isDigit(src[i]) || src[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: tokenWord, text: src[start:i], start: start, end: i, line: line})
+			continue
+		case isDigit(c):
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: tokenNumber, text: src[start:i], start: start, end: i, line: line})
+			continue
+		case strings.HasPrefix(src[i:], "::"):
+			i += 2
+			tokens = append(tokens, ddlToken{kind: tokenPunct, text: "::", start: start, end: i, line: line})
+			continue
+		}
+		i++
+		tokens = append(tokens, ddlToken{kind: tokenPunct, text: string(c), start: start, end: i, line: line})
+	}
+	return tokens, nil
+}
+
+// readQuoted reads the quoted text at the start of s, a doubled quote standing
+// for the quote itself, and returns it with the length read
+func readQuoted(s string, quote byte) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != quote {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			b.WriteByte(quote)
+			i++
+			continue
+		}
+		return b.String(), i + 1, true
+	}
+	return "", 0, false
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		if !isWordStart(tag[i]) && !isDigit(tag[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitStatements splits tokens on the semicolons ending statements, dropping
+// empty statements
+func splitStatements(tokens []ddlToken) [][]ddlToken {
+	var stmts [][]ddlToken
+	start := 0
+	for i, tok := range tokens {
+		if tok.kind == tokenPunct && tok.text == ";" {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		stmts = append(stmts, tokens[start:])
+	}
+	return stmts
+}
+
+// ddlParser reads a single statement
+type ddlParser struct {
+	schema *ddlSchema
+	src    string
+	tokens []ddlToken
+	pos    int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// isKeyword reports whether the token at offset n from the current one is one
+// of the keywords, compared case-insensitively
+func (p *ddlParser) isKeyword(n int, keywords ...string) bool {
+	if p.pos+n >= len(p.tokens) || p.tokens[p.pos+n].kind != tokenWord {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(p.tokens[p.pos+n].text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptKeywords consumes the sequence of keywords if the next tokens match it
+func (p *ddlParser) acceptKeywords(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if !p.isKeyword(i, keyword) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *ddlParser) isPunct(punct string) bool {
+	return !p.done() && p.tokens[p.pos].kind == tokenPunct && p.tokens[p.pos].text == punct
+}
+
+func (p *ddlParser) acceptPunct(punct string) bool {
+	if p.isPunct(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) expectPunct(punct string) error {
+	if !p.acceptPunct(punct) {
+		return p.unexpected(fmt.Sprintf("%q", punct))
+	}
+	return nil
+}
+
+func (p *ddlParser) unexpected(expected string) error {
+	if p.done() {
+		return fmt.Errorf("expected %s, got end of statement", expected)
+	}
+	return fmt.Errorf("expected %s, got %q", expected, p.tokens[p.pos].text)
+}
+
+// identifier reads a name. PostgreSQL folds unquoted names to lower case.
+func (p *ddlParser) identifier() (string, error) {
+	if p.done() || (p.tokens[p.pos].kind != tokenWord && p.tokens[p.pos].kind != tokenQuoted) {
+		return "", p.unexpected("a name")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	if tok.kind == tokenWord && p.schema.isPostgres() {
+		return strings.ToLower(tok.text), nil
+	}
+	return tok.text, nil
+}
+
+// qualifiedName reads a name optionally qualified by its schema and reports
+// whether it belongs to the introspected schema: public for PostgreSQL, any
+// schema otherwise
+func (p *ddlParser) qualifiedName() (string, bool, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return "", false, err
+	}
+	schema := ""
+	for p.acceptPunct(".") {
+		schema = name
+		if name, err = p.identifier(); err != nil {
+			return "", false, err
+		}
+	}
+	return name, schema == "" || schema == "public" || !p.schema.isPostgres(), nil
+}
+
+// identifierList reads a parenthesized list of column names. Index
+// expressions, sort orders and prefix lengths are skipped.
+func (p *ddlParser) identifierList() ([]string, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name := ""
+		if !p.isPunct("(") {
+			var err error
+			if name, err = p.identifier(); err != nil {
+				return nil, err
+			}
+		}
+		// Expressions, like lower(email), name no column
+		if p.isPunct("(") {
+			name = ""
+		}
+		names = append(names, name)
+		p.skipUntil(",", ")")
+		if p.acceptPunct(")") {
+			return names, nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipUntil skips tokens up to one of the punctuations outside parentheses
+func (p *ddlParser) skipUntil(puncts ...string) {
+	for depth := 0; !p.done(); p.pos++ {
+		tok := p.tokens[p.pos]
+		if tok.kind != tokenPunct {
+			continue
+		}
+		if depth == 0 && slices.Contains(puncts, tok.text) {
+			return
+		}
+		switch tok.text {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// skipGroup skips a parenthesized group when the next token opens one
+func (p *ddlParser) skipGroup() {
+	if p.acceptPunct("(") {
+		p.skipUntil(")")
+		p.acceptPunct(")")
+	}
+}
+
+// text returns the source of the tokens from start to the current one, with
+// its whitespace collapsed
+func (p *ddlParser) text(start int) string {
+	if start >= p.pos {
+		return ""
+	}
+	src := p.src[p.tokens[start].start:p.tokens[p.pos-1].end]
+	return strings.Join(strings.Fields(src), " ")
+}
+
+func (p *ddlParser) statement() error {
+	switch {
+	case p.acceptKeywords("create"):
+		p.acceptKeywords("or", "replace")
+		switch {
+		case p.acceptKeywords("type"):
+			return p.createType()
+		case p.acceptKeywords("unique"):
+			p.acceptKeywords("clustered")
+			p.acceptKeywords("nonclustered")
+			if p.acceptKeywords("index") {
+				return p.createIndex(true)
+			}
+			return nil
+		case p.acceptKeywords("index"):
+			return p.createIndex(false)
+		}
+		for p.isKeyword(0, "temp", "temporary", "unlogged", "global", "local") {
+			p.pos++
+		}
+		if p.acceptKeywords("table") {
+			return p.createTable()
+		}
+	case p.acceptKeywords("alter", "table"):
+		return p.alterTable()
+	case p.acceptKeywords("comment", "on", "column"):
+		return p.commentOnColumn()
+	case p.acceptKeywords("drop", "table"):
+		return p.dropTable()
+	}
+	return nil
+}
+
+// createType reads CREATE TYPE name AS ENUM ('a', 'b'), other types being
+// ignored
+func (p *ddlParser) createType() error {
+	name, ok, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeywords("as", "enum") {
+		return nil
+	}
+	if err := p.expectPunct("("); err != nil {
+		return err
+	}
+	labels := []string{}
+	for !p.acceptPunct(")") {
+		if p.done() || p.tokens[p.pos].kind != tokenString {
+			return p.unexpected("an enum label")
+		}
+		labels = append(labels, p.tokens[p.pos].text)
+		p.pos++
+		if !p.isPunct(")") {
+			if err := p.expectPunct(","); err != nil {
+				return err
+			}
+		}
+	}
+	if ok {
+		p.schema.enums[name] = labels
+	}
+	return nil
+}
+
+func (p *ddlParser) createTable() error {
+	ifNotExists := p.acceptKeywords("if", "not", "exists")
+	name, ok, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	// Tables created from a query or as a partition have no column list
+	if !p.acceptPunct("(") {
+		return nil
+	}
+	if _, exists := p.schema.byName[name]; exists && ok {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("table %s already exists", name)
+	}
+
+	t := &ddlTable{schema: TableSchema{TableName: name}, unique: make(map[string]bool)}
+	for !p.acceptPunct(")") {
+		if err := p.tableElement(t); err != nil {
+			return err
+		}
+		if !p.isPunct(")") {
+			if err := p.expectPunct(","); err != nil {
+				return err
+			}
+		}
+	}
+	if ok {
+		p.schema.order = append(p.schema.order, name)
+		p.schema.byName[name] = t
+	}
+	return nil
+}
+
+// tableElement reads a column definition or a table constraint
+func (p *ddlParser) tableElement(t *ddlTable) error {
+	if p.done() {
+		return p.unexpected("a column definition")
+	}
+	if p.isKeyword(0, "constraint", "primary", "unique", "foreign", "check",
+		"key", "index", "fulltext", "spatial", "exclude", "like") {
+		return p.tableConstraint(t)
+	}
+	return p.columnDefinition(t)
+}
+
+// tableConstraint reads a table constraint, keys and indexes which constrain
+// nothing being skipped
+func (p *ddlParser) tableConstraint(t *ddlTable) error {
+	if p.acceptKeywords("constraint") {
+		if _, err := p.identifier(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.acceptKeywords("primary", "key"):
+		columns, err := p.identifierList()
+		if err != nil {
+			return err
+		}
+		t.schema.PrimaryKey = columns
+	case p.acceptKeywords("unique"):
+		if p.isKeyword(0, "key", "index") {
+			p.pos++
+		}
+		_ = p.acceptKeywords("nulls", "not", "distinct") || p.acceptKeywords("nulls", "distinct")
+		// MySQL names its unique keys
+		if !p.isPunct("(") {
+			if _, err := p.identifier(); err != nil {
+				return err
+			}
+		}
+		columns, err := p.identifierList()
+		if err != nil {
+			return err
+		}
+		if len(columns) == 1 {
+			t.unique[columns[0]] = true
+		}
+	case p.acceptKeywords("foreign", "key"):
+		if !p.isPunct("(") {
+			if _, err := p.identifier(); err != nil {
+				return err
+			}
+		}
+		columns, err := p.identifierList()
+		if err != nil {
+			return err
+		}
+		if !p.acceptKeywords("references") {
+			return p.unexpected("REFERENCES")
+		}
+		if err := p.references(t, columns); err != nil {
+			return err
+		}
+	case p.acceptKeywords("check"):
+		start := p.pos
+		p.skipGroup()
+		t.checks = append(t.checks, p.text(start))
+	}
+	p.skipUntil(",", ")")
+	return nil
+}
+
+// columnDefinition reads a column name, its type and its constraints
+func (p *ddlParser) columnDefinition(t *ddlTable) error {
+	name, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	if t.hasColumn(name) {
+		return fmt.Errorf("column %s of table %s already exists", name, t.schema.TableName)
+	}
+
+	start := p.pos
+	for !p.done() && !p.isPunct(",") && !p.isPunct(")") && !p.isColumnConstraint() {
+		if p.acceptPunct("(") || p.acceptPunct("[") {
+			p.skipUntil(")", "]")
+			p.pos++
+			continue
+		}
+		p.pos++
+	}
+	col := p.schema.column(t.schema.TableName, name, p.text(start))
+	for !p.done() && !p.isPunct(",") && !p.isPunct(")") {
+		switch {
+		case p.acceptKeywords("constraint"):
+			if _, err := p.identifier(); err != nil {
+				return err
+			}
+		case p.acceptKeywords("not", "null"):
+			col.IsNullable = false
+		case p.acceptKeywords("null"):
+		case p.acceptKeywords("primary", "key"):
+			t.schema.PrimaryKey = []string{name}
+		case p.acceptKeywords("unique"):
+			p.acceptKeywords("key")
+			t.unique[name] = true
+		case p.acceptKeywords("default"):
+			start := p.pos
+			for !p.done() && !p.isPunct(",") && !p.isPunct(")") && !p.isColumnConstraint() {
+				if p.acceptPunct("(") {
+					p.skipUntil(")")
+				}
+				p.pos++
+			}
+			col.Default = p.text(start)
+		case p.acceptKeywords("references"):
+			if err := p.references(t, []string{name}); err != nil {
+				return err
+			}
+		case p.acceptKeywords("check"):
+			start := p.pos
+			p.skipGroup()
+			t.checks = append(t.checks, p.text(start))
+		case p.acceptKeywords("comment"):
+			if !p.done() && p.tokens[p.pos].kind == tokenString {
+				col.Comment = p.tokens[p.pos].text
+				p.pos++
+			}
+		case p.acceptKeywords("generated", "always", "as", "identity"), p.acceptKeywords("generated", "by", "default", "as", "identity"):
+			p.skipGroup()
+			col.IsNullable = false
+		case p.acceptKeywords("autoincrement"), p.acceptKeywords("auto_increment"):
+			col.AutoIncrement = true
+		default:
+			// COLLATE, ON UPDATE and the like constrain nothing the
+			// generators use
+			p.pos++
+			p.skipGroup()
+		}
+	}
+	t.schema.Columns = append(t.schema.Columns, col)
+	return nil
+}
+
+// isColumnConstraint reports whether the next token starts a column
+// constraint, ending the column type or default expression
+func (p *ddlParser) isColumnConstraint() bool {
+	if p.isKeyword(0, "character") && p.isKeyword(1, "set") {
+		return true
+	}
+	if p.isKeyword(0, "not") {
+		return p.isKeyword(1, "null")
+	}
+	return p.isKeyword(0, "constraint", "null", "primary", "unique", "default", "references", "check",
+		"collate", "generated", "auto_increment", "autoincrement", "comment", "on", "charset", "identity")
+}
+
+// references reads the parent table and columns of a foreign key, skipping
+// its actions
+func (p *ddlParser) references(t *ddlTable, columns []string) error {
+	parent, _, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	fk := ddlForeignKey{columns: columns, parentTable: parent}
+	if p.isPunct("(") {
+		if fk.parentColumns, err = p.identifierList(); err != nil {
+			return err
+		}
+	}
+	t.foreign = append(t.foreign, fk)
+
+	for {
+		switch {
+		case p.acceptKeywords("on", "delete"), p.acceptKeywords("on", "update"):
+			switch {
+			case p.acceptKeywords("set", "null"), p.acceptKeywords("set", "default"), p.acceptKeywords("no", "action"):
+			default:
+				p.pos++
+			}
+			p.skipGroup()
+		case p.acceptKeywords("match"), p.acceptKeywords("initially"):
+			p.pos++
+		case p.acceptKeywords("not", "deferrable"), p.acceptKeywords("deferrable"):
+		default:
+			return nil
+		}
+	}
+}
+
+// table returns the table named by the statement, nil for tables of another
+// schema
+func (p *ddlParser) table() (*ddlTable, error) {
+	name, ok, err := p.qualifiedName()
+	if err != nil || !ok {
+		return nil, err
+	}
+	t, exists := p.schema.byName[name]
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist", name)
+	}
+	return t, nil
+}
+
+// alterTable reads ALTER TABLE name followed by ADD constraints and columns,
+// DROP COLUMN, ALTER COLUMN ... SET or DROP NOT NULL, TYPE and SET or DROP
+// DEFAULT, MySQL MODIFY and CHANGE, and RENAME actions, other actions, like
+// OWNER TO, being ignored
+func (p *ddlParser) alterTable() error {
+	p.acceptKeywords("if", "exists")
+	p.acceptKeywords("only")
+	t, err := p.table()
+	if err != nil || t == nil {
+		return err
+	}
+
+	for !p.done() {
+		switch {
+		case p.acceptKeywords("add"):
+			if p.isKeyword(0, "constraint", "primary", "unique", "foreign", "check", "key", "index") {
+				if err := p.tableConstraint(t); err != nil {
+					return err
+				}
+				break
+			}
+			p.acceptKeywords("column")
+			if p.acceptKeywords("if", "not", "exists") {
+				start := p.pos
+				if name, err := p.identifier(); err == nil && t.hasColumn(name) {
+					break
+				}
+				p.pos = start
+			}
+			if err := p.columnDefinition(t); err != nil {
+				return err
+			}
+		case p.acceptKeywords("drop"):
+			if !p.acceptKeywords("column") && p.isKeyword(0, "constraint", "primary", "foreign", "index", "key", "check") {
+				break
+			}
+			ifExists := p.acceptKeywords("if", "exists")
+			name, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			if !t.hasColumn(name) {
+				if ifExists {
+					break
+				}
+				return fmt.Errorf("column %s of table %s does not exist", name, t.schema.TableName)
+			}
+			p.schema.dropColumn(t, name)
+		case p.acceptKeywords("alter"):
+			p.acceptKeywords("column")
+			if err := p.alterColumn(t); err != nil {
+				return err
+			}
+		case p.acceptKeywords("modify"):
+			p.acceptKeywords("column")
+			start := p.pos
+			name, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			p.pos = start
+			if err := p.redefineColumn(t, name); err != nil {
+				return err
+			}
+		case p.acceptKeywords("change"):
+			p.acceptKeywords("column")
+			name, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			start := p.pos
+			newName, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			if err := p.schema.renameColumn(t, name, newName); err != nil {
+				return err
+			}
+			p.pos = start
+			if err := p.redefineColumn(t, newName); err != nil {
+				return err
+			}
+		case p.acceptKeywords("rename"):
+			if err := p.rename(t); err != nil {
+				return err
+			}
+		}
+		p.skipUntil(",")
+		if !p.acceptPunct(",") {
+			return nil
+		}
+	}
+	return nil
+}
+
+// alterColumn reads the name and the action of ALTER COLUMN
+func (p *ddlParser) alterColumn(t *ddlTable) error {
+	name, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	i := t.columnIndex(name)
+	if i < 0 {
+		return fmt.Errorf("column %s of table %s does not exist", name, t.schema.TableName)
+	}
+	col := &t.schema.Columns[i]
+
+	switch {
+	case p.acceptKeywords("drop", "not", "null"):
+		col.IsNullable = true
+	case p.acceptKeywords("set", "not", "null"):
+		col.IsNullable = false
+	case p.acceptKeywords("drop", "default"):
+		col.Default = ""
+	case p.acceptKeywords("set", "default"):
+		start := p.pos
+		p.skipUntil(",")
+		col.Default = p.text(start)
+	case p.acceptKeywords("type"), p.acceptKeywords("set", "data", "type"):
+		start := p.pos
+		for !p.done() && !p.isPunct(",") && !p.isKeyword(0, "using", "collate") {
+			if p.acceptPunct("(") || p.acceptPunct("[") {
+				p.skipUntil(")", "]")
+			}
+			p.pos++
+		}
+		retyped := p.schema.column(t.schema.TableName, name, p.text(start))
+		retyped.IsNullable, retyped.Default, retyped.Comment = col.IsNullable, col.Default, col.Comment
+		*col = retyped
+	}
+	return nil
+}
+
+// redefineColumn reads the new definition of a column, as given to MySQL
+// MODIFY and CHANGE, keeping its position
+func (p *ddlParser) redefineColumn(t *ddlTable, name string) error {
+	i := t.columnIndex(name)
+	if i < 0 {
+		return fmt.Errorf("column %s of table %s does not exist", name, t.schema.TableName)
+	}
+	t.schema.Columns = slices.Delete(t.schema.Columns, i, i+1)
+	if err := p.columnDefinition(t); err != nil {
+		return err
+	}
+	last := len(t.schema.Columns) - 1
+	col := t.schema.Columns[last]
+	t.schema.Columns = slices.Insert(t.schema.Columns[:last], i, col)
+	return nil
+}
+
+// rename reads RENAME [COLUMN] old TO new, renaming a column, or RENAME
+// [TO | AS] new, renaming the table. Constraints and indexes are not tracked
+// by name.
+func (p *ddlParser) rename(t *ddlTable) error {
+	if p.isKeyword(0, "constraint", "index", "key") {
+		return nil
+	}
+	column := p.acceptKeywords("column")
+	if !column && (p.acceptKeywords("to") || p.acceptKeywords("as")) {
+		name, _, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		return p.schema.renameTable(t, name)
+	}
+
+	name, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeywords("to") {
+		if column {
+			return p.unexpected("TO")
+		}
+		// MySQL RENAME new, without TO
+		return p.schema.renameTable(t, name)
+	}
+	newName, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	return p.schema.renameColumn(t, name, newName)
+}
+
+// renameTable renames a table, along with the foreign keys referencing it
+func (s *ddlSchema) renameTable(t *ddlTable, name string) error {
+	old := t.schema.TableName
+	if _, exists := s.byName[name]; exists {
+		return fmt.Errorf("table %s already exists", name)
+	}
+	delete(s.byName, old)
+	s.byName[name] = t
+	s.order[slices.Index(s.order, old)] = name
+	t.schema.TableName = name
+	for _, other := range s.byName {
+		for i := range other.foreign {
+			if other.foreign[i].parentTable == old {
+				other.foreign[i].parentTable = name
+			}
+		}
+	}
+	return nil
+}
+
+// renameColumn renames a column of a table, in its keys, constraints and
+// SQLite indexes and in the foreign keys referencing it
+func (s *ddlSchema) renameColumn(t *ddlTable, name, newName string) error {
+	i := t.columnIndex(name)
+	if i < 0 {
+		return fmt.Errorf("column %s of table %s does not exist", name, t.schema.TableName)
+	}
+	if name == newName {
+		return nil
+	}
+	if t.hasColumn(newName) {
+		return fmt.Errorf("column %s of table %s already exists", newName, t.schema.TableName)
+	}
+
+	t.schema.Columns[i].Name = newName
+	replaceName(t.schema.PrimaryKey, name, newName)
+	if t.unique[name] {
+		delete(t.unique, name)
+		t.unique[newName] = true
+	}
+	for _, fk := range t.foreign {
+		replaceName(fk.columns, name, newName)
+	}
+	for _, other := range s.byName {
+		for _, fk := range other.foreign {
+			if fk.parentTable == t.schema.TableName {
+				replaceName(fk.parentColumns, name, newName)
+			}
+		}
+	}
+	// CHECK constraints and index statements name the column as a word
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for j := range t.checks {
+		t.checks[j] = word.ReplaceAllLiteralString(t.checks[j], newName)
+	}
+	for j := range t.schema.Indexes {
+		t.schema.Indexes[j] = word.ReplaceAllLiteralString(t.schema.Indexes[j], newName)
+	}
+	return nil
+}
+
+// dropColumn drops a column of a table, along with the keys and foreign keys
+// it is part of
+func (s *ddlSchema) dropColumn(t *ddlTable, name string) {
+	t.schema.Columns = slices.DeleteFunc(t.schema.Columns, func(col Column) bool { return col.Name == name })
+	if slices.Contains(t.schema.PrimaryKey, name) {
+		t.schema.PrimaryKey = nil
+	}
+	delete(t.unique, name)
+	t.foreign = slices.DeleteFunc(t.foreign, func(fk ddlForeignKey) bool { return slices.Contains(fk.columns, name) })
+	for _, other := range s.byName {
+		other.foreign = slices.DeleteFunc(other.foreign, func(fk ddlForeignKey) bool {
+			return fk.parentTable == t.schema.TableName && slices.Contains(fk.parentColumns, name)
+		})
+	}
+}
+
+// replaceName replaces name by newName in names
+func replaceName(names []string, name, newName string) {
+	for i := range names {
+		if names[i] == name {
+			names[i] = newName
+		}
+	}
+}
+
+// createIndex reads CREATE [UNIQUE] INDEX name ON table (column), marking the
+// column of a unique index unique unless the index is partial or spans
+// several columns. SQLite tables keep the statement in their Indexes, like
+// the introspected schema.
+func (p *ddlParser) createIndex(unique bool) error {
+	p.acceptKeywords("concurrently")
+	p.acceptKeywords("if", "not", "exists")
+	if !p.isKeyword(0, "on") {
+		if _, _, err := p.qualifiedName(); err != nil {
+			return err
+		}
+	}
+	if !p.acceptKeywords("on") {
+		return p.unexpected("ON")
+	}
+	p.acceptKeywords("only")
+	t, err := p.table()
+	if err != nil || t == nil {
+		return err
+	}
+	if p.acceptKeywords("using") {
+		p.pos++
+	}
+	columns, err := p.identifierList()
+	if err != nil {
+		return err
+	}
+	partial := false
+	for !p.done() {
+		partial = partial || p.isKeyword(0, "where")
+		p.pos++
+	}
+	if p.schema.dialect == DialectSQLite {
+		t.schema.Indexes = append(t.schema.Indexes, p.text(0))
+	}
+	if unique && !partial && len(columns) == 1 {
+		t.unique[columns[0]] = true
+	}
+	return nil
+}
+
+// commentOnColumn reads COMMENT ON COLUMN table.column IS 'text'
+func (p *ddlParser) commentOnColumn() error {
+	var names []string
+	for {
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+		if !p.acceptPunct(".") {
+			break
+		}
+	}
+	if len(names) < 2 || !p.acceptKeywords("is") {
+		return p.unexpected("IS")
+	}
+	if len(names) > 2 && names[len(names)-3] != "public" && p.schema.isPostgres() {
+		return nil
+	}
+	t, ok := p.schema.byName[names[len(names)-2]]
+	if !ok {
+		return fmt.Errorf("table %s does not exist", names[len(names)-2])
+	}
+	comment := ""
+	if !p.done() && p.tokens[p.pos].kind == tokenString {
+		comment = p.tokens[p.pos].text
+	}
+	for i := range t.schema.Columns {
+		if t.schema.Columns[i].Name == names[len(names)-1] {
+			t.schema.Columns[i].Comment = comment
+			return nil
+		}
+	}
+	return fmt.Errorf("column %s of table %s does not exist", names[len(names)-1], names[len(names)-2])
+}
+
+// dropTable reads DROP TABLE [IF EXISTS] name, ...
+func (p *ddlParser) dropTable() error {
+	ifExists := p.acceptKeywords("if", "exists")
+	for {
+		name, ok, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if _, exists := p.schema.byName[name]; ok && exists {
+			delete(p.schema.byName, name)
+			p.schema.order = slices.DeleteFunc(p.schema.order, func(n string) bool { return n == name })
+		} else if ok && !ifExists {
+			return fmt.Errorf("table %s does not exist", name)
+		}
+		if !p.acceptPunct(",") {
+			return nil
+		}
+	}
+}
+
+// column returns the column of a declared type, reported like the dialect
+// introspects it
+func (s *ddlSchema) column(table, name, typeDef string) Column {
+	col := Column{Name: name, IsNullable: true}
+	switch s.dialect {
+	case DialectMySQL:
+		// The data type without its length and attributes, and the full
+		// column type
+		col.TypeDef = strings.ToLower(typeDef)
+		col.Type, _, _ = splitTypeDef(col.TypeDef)
+		col.MaxLength, col.Precision, col.Scale = parseTypeModifiers(col.TypeDef)
+		col.EnumValues = parseEnumType(col.TypeDef)
+	case DialectSQLite:
+		// The declared type, as written
+		col.Type, col.TypeDef = typeDef, typeDef
+		col.MaxLength, col.Precision, col.Scale = parseTypeModifiers(typeDef)
+	default:
+		col.Type, col.TypeDef, col.MaxLength, col.Precision, col.Scale = postgresDataType(typeDef)
+		if serial, ok := postgresSerialTypes[col.Type]; ok {
+			col.Type, col.TypeDef = serial, serial
+			col.Default = fmt.Sprintf("nextval('%s_%s_seq'::regclass)", table, name)
+		}
+	}
+	return col
+}
+
+// postgresSerialTypes maps the serial pseudo-types to the integer type of the
+// column they declare
+var postgresSerialTypes = map[string]string{
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+}
+
+// postgresTypeNames maps PostgreSQL type aliases to the names the information
+// schema reports
+var postgresTypeNames = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"int2":        "smallint",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"bool":        "boolean",
+	"float8":      "double precision",
+	"float4":      "real",
+	"float":       "double precision",
+	"decimal":     "numeric",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+	"varbit":      "bit varying",
+}
+
+var typeArgumentsPattern = regexp.MustCompile(`\s*\(([^)]*)\)`)
+
+// postgresDataType returns the data type PostgreSQL reports for a declared
+// type, and its type definition, which is the element type followed by []
+// for arrays, along with its length, precision and scale
+func postgresDataType(typeDef string) (dataType, def string, maxLength, precision, scale int) {
+	typ := strings.ToLower(strings.TrimSpace(typeDef))
+	isArray := false
+	for {
+		if trimmed, ok := strings.CutSuffix(typ, "]"); ok {
+			if open := strings.LastIndex(trimmed, "["); open >= 0 {
+				typ, isArray = strings.TrimSpace(trimmed[:open]), true
+				continue
+			}
+		}
+		if trimmed, ok := strings.CutSuffix(typ, " array"); ok {
+			typ, isArray = strings.TrimSpace(trimmed), true
+			continue
+		}
+		break
+	}
+
+	var args []string
+	if match := typeArgumentsPattern.FindStringSubmatch(typ); match != nil {
+		args = strings.Split(match[1], ",")
+	}
+	typ = strings.Join(strings.Fields(typeArgumentsPattern.ReplaceAllString(typ, "")), " ")
+	// Types qualified by their schema, like public.mood
+	if i := strings.LastIndex(typ, "."); i >= 0 && !strings.Contains(typ, " ") {
+		typ = typ[i+1:]
+	}
+	if typ == "float" && len(args) == 1 && typeArgument(args, 0) <= 24 {
+		typ = "real"
+	}
+	if name, ok := postgresTypeNames[typ]; ok {
+		typ = name
+	}
+
+	switch typ {
+	case "character varying", "character", "bit", "bit varying":
+		maxLength = typeArgument(args, 0)
+		if len(args) == 0 && typ == "character" {
+			maxLength = 1
+		}
+	case "numeric":
+		precision, scale = typeArgument(args, 0), typeArgument(args, 1)
+	}
+
+	if isArray {
+		// PostgreSQL reports the length of array elements nowhere
+		return "ARRAY", typ + "[]", 0, 0, 0
+	}
+	return typ, typ, maxLength, precision, scale
+}
+
+// typeArgument returns the i-th numeric argument of a type, zero when missing
+func typeArgument(args []string, i int) int {
+	if i >= len(args) {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(args[i]))
+	return n
+}
@@ -0,0 +1,301 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseDDL(t *testing.T, dialect, src string) map[string]TableSchema {
+	t.Helper()
+	schema := newDDLSchema(dialect)
+	if err := schema.parse("schema.sql", src); err != nil {
+		t.Fatalf("Failed to parse DDL: %v", err)
+	}
+	return schema.tables()
+}
+
+func TestParseDDLPostgres(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+-- Users and their posts
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+CREATE TABLE IF NOT EXISTS public.users (
+	id SERIAL PRIMARY KEY,
+	Email VARCHAR(255) NOT NULL UNIQUE,
+	"displayName" text,
+	balance numeric(10, 2) DEFAULT 0,
+	tags text[] NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE posts (
+	id uuid NOT NULL,
+	author_id integer REFERENCES users ON DELETE CASCADE,
+	status varchar(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published')),
+	mood mood,
+	body text,
+	editor_id integer,
+	CONSTRAINT posts_pkey PRIMARY KEY (id)
+);
+
+/* Constraints added separately, like pg_dump does */
+ALTER TABLE ONLY public.posts
+	ADD CONSTRAINT posts_editor_fk FOREIGN KEY (editor_id) REFERENCES public.users(id);
+CREATE UNIQUE INDEX posts_body_key ON posts USING btree (body);
+COMMENT ON COLUMN public.posts.body IS 'Markdown body';
+CREATE INDEX posts_status_idx ON posts (status);
+CREATE TABLE audit.events (id integer);
+`)
+
+	if len(tables) != 2 {
+		t.Fatalf("Expected users and posts, got %v", tables)
+	}
+
+	users := tables["users"]
+	wantUsers := []Column{
+		{Name: "id", Type: "integer", TypeDef: "integer", IsPrimaryKey: true, Default: "nextval('users_id_seq'::regclass)"},
+		{Name: "email", Type: "character varying", TypeDef: "character varying", MaxLength: 255, IsUnique: true},
+		{Name: "displayName", Type: "text", TypeDef: "text", IsNullable: true},
+		{Name: "balance", Type: "numeric", TypeDef: "numeric", Precision: 10, Scale: 2, Default: "0", IsNullable: true},
+		{Name: "tags", Type: "ARRAY", TypeDef: "text[]", Default: "'{}'"},
+		{Name: "created_at", Type: "timestamp with time zone", TypeDef: "timestamp with time zone", Default: "now()"},
+	}
+	if !reflect.DeepEqual(users.Columns, wantUsers) {
+		t.Errorf("Expected users columns\n%+v\ngot\n%+v", wantUsers, users.Columns)
+	}
+	if !reflect.DeepEqual(users.PrimaryKey, []string{"id"}) {
+		t.Errorf("Expected users primary key id, got %v", users.PrimaryKey)
+	}
+
+	posts := tables["posts"]
+	if !reflect.DeepEqual(posts.PrimaryKey, []string{"id"}) || !posts.Columns[0].IsPrimaryKey || posts.Columns[0].IsNullable {
+		t.Errorf("Expected posts primary key id, got %v", posts.PrimaryKey)
+	}
+	status := posts.Columns[2]
+	if status.Default != "'draft'" || !reflect.DeepEqual(status.EnumValues, []string{"draft", "published"}) {
+		t.Errorf("Expected status default and check values, got %+v", status)
+	}
+	mood := posts.Columns[3]
+	if mood.EnumType != "mood" || mood.TypeDef != "enum('happy','sad')" || !reflect.DeepEqual(mood.EnumValues, []string{"happy", "sad"}) {
+		t.Errorf("Expected mood enum column, got %+v", mood)
+	}
+	body := posts.Columns[4]
+	if !body.IsUnique || body.Comment != "Markdown body" {
+		t.Errorf("Expected unique commented body, got %+v", body)
+	}
+	wantRelations := []Relation{
+		{ChildTable: "posts", ChildColumn: "author_id", ParentTable: "users", ParentColumn: "id"},
+		{ChildTable: "posts", ChildColumn: "editor_id", ParentTable: "users", ParentColumn: "id"},
+	}
+	if !reflect.DeepEqual(posts.Relations, wantRelations) {
+		t.Errorf("Expected relations %+v, got %+v", wantRelations, posts.Relations)
+	}
+}
+
+func TestParseDDLDialects(t *testing.T) {
+	tests := []struct {
+		dialect       string
+		src           string
+		column        Column
+		autoIncrement bool
+		indexes       []string
+	}{
+		{
+			dialect:       DialectMySQL,
+			src:           "CREATE TABLE `files` (`id` int(10) unsigned NOT NULL AUTO_INCREMENT, `kind` enum('a','b') CHARACTER SET utf8mb4 DEFAULT 'a' COMMENT 'File kind', PRIMARY KEY (`id`)) ENGINE=InnoDB;",
+			column:        Column{Name: "kind", Type: "enum", TypeDef: "enum('a','b')", IsNullable: true, Default: "'a'", Comment: "File kind", EnumValues: []string{"a", "b"}},
+			autoIncrement: true,
+		},
+		{
+			dialect:       DialectSQLite,
+			src:           "CREATE TABLE files (id INTEGER PRIMARY KEY AUTOINCREMENT, kind VARCHAR(10) NOT NULL);\nCREATE INDEX files_kind\n\tON files (kind);",
+			column:        Column{Name: "kind", Type: "VARCHAR(10)", TypeDef: "VARCHAR(10)", MaxLength: 10},
+			autoIncrement: true,
+			indexes:       []string{"CREATE INDEX files_kind ON files (kind)"},
+		},
+		{
+			dialect: DialectPostgres,
+			src:     "CREATE TABLE Files (ID bigint GENERATED ALWAYS AS IDENTITY, kind character(3)[]);",
+			column:  Column{Name: "kind", Type: "ARRAY", TypeDef: "character[]", IsNullable: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			tables := parseDDL(t, tt.dialect, tt.src)
+			files, ok := tables["files"]
+			if !ok || len(files.Columns) != 2 {
+				t.Fatalf("Expected files table with 2 columns, got %+v", tables)
+			}
+			if !reflect.DeepEqual(files.Columns[1], tt.column) {
+				t.Errorf("Expected column\n%+v\ngot\n%+v", tt.column, files.Columns[1])
+			}
+			// Key columns are never nullable, SQLite ones included
+			if files.Columns[0].IsNullable {
+				t.Errorf("Unexpected nullability of the key column %+v", files.Columns[0])
+			}
+			if files.Columns[0].AutoIncrement != tt.autoIncrement {
+				t.Errorf("Expected auto increment %v on the key column, got %+v", tt.autoIncrement, files.Columns[0])
+			}
+			// Only SQLite keeps its indexes, to recreate them with the table
+			if !reflect.DeepEqual(files.Indexes, tt.indexes) {
+				t.Errorf("Expected indexes %q, got %q", tt.indexes, files.Indexes)
+			}
+		})
+	}
+}
+
+func TestParseDDLAlterTable(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE users (id integer PRIMARY KEY, name text, legacy text);
+ALTER TABLE users ADD COLUMN email text NOT NULL, ADD COLUMN IF NOT EXISTS name text, DROP COLUMN legacy, ALTER COLUMN name SET NOT NULL;
+CREATE TABLE drafts (id integer);
+DROP TABLE IF EXISTS drafts, missing;
+`)
+
+	var names []string
+	for _, col := range tables["users"].Columns {
+		names = append(names, col.Name)
+		if col.IsNullable {
+			t.Errorf("Expected %s not to be nullable", col.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"id", "name", "email"}) {
+		t.Errorf("Expected columns id, name, email, got %v", names)
+	}
+	if _, ok := tables["drafts"]; ok {
+		t.Error("Expected drafts to be dropped")
+	}
+}
+
+func TestParseDDLAlterTableRenames(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE accounts (id integer PRIMARY KEY, name text UNIQUE);
+CREATE TABLE posts (
+	id integer,
+	author_id integer REFERENCES accounts (id),
+	editor_id integer REFERENCES accounts,
+	status text CHECK (status IN ('draft', 'published')),
+	views integer,
+	PRIMARY KEY (id)
+);
+ALTER TABLE accounts RENAME TO users;
+ALTER TABLE users RENAME COLUMN name TO login;
+ALTER TABLE posts RENAME author_id TO user_id, RENAME COLUMN status TO state;
+ALTER TABLE posts ALTER COLUMN views TYPE bigint USING views::bigint, ALTER COLUMN views SET DEFAULT 0;
+ALTER TABLE posts ALTER COLUMN state SET DATA TYPE varchar(20), ALTER state SET DEFAULT 'draft';
+ALTER TABLE posts DROP COLUMN editor_id, DROP COLUMN IF EXISTS missing;
+`)
+
+	if _, ok := tables["accounts"]; ok {
+		t.Error("Expected accounts to be renamed users")
+	}
+	users := tables["users"]
+	if users.TableName != "users" || users.Columns[1].Name != "login" || !users.Columns[1].IsUnique {
+		t.Errorf("Expected users with a unique login column, got %+v", users)
+	}
+
+	posts := tables["posts"]
+	wantPosts := []Column{
+		{Name: "id", Type: "integer", TypeDef: "integer", IsPrimaryKey: true},
+		{Name: "user_id", Type: "integer", TypeDef: "integer", IsNullable: true},
+		{Name: "state", Type: "character varying", TypeDef: "character varying", MaxLength: 20, IsNullable: true, Default: "'draft'", EnumValues: []string{"draft", "published"}},
+		{Name: "views", Type: "bigint", TypeDef: "bigint", IsNullable: true, Default: "0"},
+	}
+	if !reflect.DeepEqual(posts.Columns, wantPosts) {
+		t.Errorf("Expected posts columns\n%+v\ngot\n%+v", wantPosts, posts.Columns)
+	}
+	// The dropped editor_id takes its foreign key along
+	wantRelations := []Relation{{ChildTable: "posts", ChildColumn: "user_id", ParentTable: "users", ParentColumn: "id"}}
+	if !reflect.DeepEqual(posts.Relations, wantRelations) {
+		t.Errorf("Expected relations %+v, got %+v", wantRelations, posts.Relations)
+	}
+}
+
+func TestParseDDLAlterTableMySQL(t *testing.T) {
+	tables := parseDDL(t, DialectMySQL, "CREATE TABLE `users` (`id` int NOT NULL, `name` varchar(50), `email` text, `team_id` int, PRIMARY KEY (`id`));\n"+
+		"ALTER TABLE `users` MODIFY `name` varchar(100) NOT NULL, CHANGE COLUMN `email` `mail` varchar(255) NULL, ALTER COLUMN `team_id` SET DEFAULT 1;\n"+
+		"ALTER TABLE `users` DROP COLUMN `id`, RENAME `members`;")
+
+	members, ok := tables["members"]
+	if !ok {
+		t.Fatalf("Expected users to be renamed members, got %v", tables)
+	}
+	want := []Column{
+		{Name: "name", Type: "varchar", TypeDef: "varchar(100)", MaxLength: 100},
+		{Name: "mail", Type: "varchar", TypeDef: "varchar(255)", MaxLength: 255, IsNullable: true},
+		{Name: "team_id", Type: "int", TypeDef: "int", IsNullable: true, Default: "1"},
+	}
+	if !reflect.DeepEqual(members.Columns, want) {
+		t.Errorf("Expected columns\n%+v\ngot\n%+v", want, members.Columns)
+	}
+	// Dropping a key column drops the key
+	if len(members.PrimaryKey) != 0 {
+		t.Errorf("Expected no primary key once id is dropped, got %v", members.PrimaryKey)
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"CREATE TABLE users (id integer);\nCREATE TABLE users (id integer);", "line 2: table users already exists"},
+		{"ALTER TABLE users ADD COLUMN email text;", "line 1: table users does not exist"},
+		{"CREATE TABLE users (\n\tid integer,\n\tname text", "line 3: expected \",\", got end of statement"},
+		{"CREATE TYPE mood AS ENUM (happy);", "line 1: expected an enum label, got \"happy\""},
+		{"CREATE TABLE users (name text DEFAULT 'x);", "unterminated ' quote"},
+		{"CREATE TABLE users (id integer);\nALTER TABLE users RENAME COLUMN name TO login;", "line 2: column name of table users does not exist"},
+		{"CREATE TABLE users (id integer, name text);\nALTER TABLE users RENAME name TO id;", "line 2: column id of table users already exists"},
+		{"CREATE TABLE users (id integer);\nCREATE TABLE posts (id integer);\nALTER TABLE posts RENAME TO users;", "line 3: table users already exists"},
+		{"CREATE TABLE users (id integer);\nALTER TABLE users ALTER COLUMN name TYPE text;", "line 2: column name of table users does not exist"},
+		{"CREATE TABLE users (id integer);\nALTER TABLE users DROP COLUMN name;", "line 2: column name of table users does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			err := newDDLSchema(DialectPostgres).parse("schema.sql", tt.src)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected ParseError containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadDDLSchema(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"001_users.sql": "CREATE TABLE users (id integer PRIMARY KEY);",
+		"002_posts.sql": "CREATE TABLE posts (id integer PRIMARY KEY, user_id integer);\nALTER TABLE posts ADD FOREIGN KEY (user_id) REFERENCES users;",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	paths, err := resolveSchemaFiles(dir, []string{"*.sql", "001_users.sql"})
+	if err != nil {
+		t.Fatalf("Failed to resolve schema files: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "001_users.sql" {
+		t.Fatalf("Expected files in name order without duplicates, got %v", paths)
+	}
+	tables, err := LoadDDLSchema(DialectPostgres, paths...)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	want := []Relation{{ChildTable: "posts", ChildColumn: "user_id", ParentTable: "users", ParentColumn: "id"}}
+	if !reflect.DeepEqual(tables["posts"].Relations, want) {
+		t.Errorf("Expected relations %+v, got %+v", want, tables["posts"].Relations)
+	}
+
+	if _, err := resolveSchemaFiles(dir, []string{"schema.sql"}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing schema file to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package codegen
+
+import "strings"
+
+// Database dialects, named after the driver names of gorest databases
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// goTypeMappers return the Go type of a non-null column, by dialect
+var goTypeMappers = map[string]func(col Column) string{
+	DialectPostgres: postgresGoType,
+	DialectMySQL:    mysqlGoType,
+	DialectSQLite:   sqliteGoType,
+}
+
+// DialectTypeMapper returns the default type mapping of a dialect. Unknown
+// dialects are mapped like PostgreSQL.
+func DialectTypeMapper(dialect string) TypeMapper {
+	mapper, ok := goTypeMappers[dialect]
+	if !ok {
+		mapper = postgresGoType
+	}
+	return TypeMapperFunc(func(col Column) (string, bool) {
+		return mapper(col), true
+	})
+}
+
+// nullableGoType makes nullable columns and timestamps pointers. Interfaces,
+// slices and maps hold nil already.
+func nullableGoType(goType string, nullable bool) string {
+	isTimestamp := goType == "time.Time"
+	if (nullable || isTimestamp) && !holdsNil(goType) {
+		return "*" + goType
+	}
+	return goType
+}
+
+// postgresGoType maps arrays by their type definition, like text[], the
+// information schema reporting them as ARRAY
+func postgresGoType(col Column) string {
+	pgType := col.Type
+	if pgType == "ARRAY" && strings.HasSuffix(col.TypeDef, "[]") {
+		pgType = col.TypeDef
+	}
+	return strings.TrimPrefix(pgToGoType(pgType, false), "*")
+}
+
+// mysqlTypes maps MySQL types, without their length and attributes, to Go
+// types. Signed integers are listed, their unsigned variant being derived.
+var mysqlTypes = map[string]string{
+	"tinyint":          "int8",
+	"smallint":         "int16",
+	"mediumint":        "int32",
+	"int":              "int",
+	"integer":          "int",
+	"bigint":           "int64",
+	"bool":             "bool",
+	"boolean":          "bool",
+	"decimal":          "float64",
+	"dec":              "float64",
+	"numeric":          "float64",
+	"fixed":            "float64",
+	"float":            "float32",
+	"double":           "float64",
+	"double precision": "float64",
+	"real":             "float64",
+	"char":             "string",
+	"varchar":          "string",
+	"tinytext":         "string",
+	"text":             "string",
+	"mediumtext":       "string",
+	"longtext":         "string",
+	"enum":             "string",
+	"set":              "string",
+	"time":             "string",
+	"date":             "time.Time",
+	"datetime":         "time.Time",
+	"timestamp":        "time.Time",
+	"year":             "int16",
+	"binary":           "[]byte",
+	"varbinary":        "[]byte",
+	"tinyblob":         "[]byte",
+	"blob":             "[]byte",
+	"mediumblob":       "[]byte",
+	"longblob":         "[]byte",
+	"json":             "map[string]interface{}",
+}
+
+// mysqlGoType maps the full column type, like int unsigned or tinyint(1),
+// which the information schema data type leaves out
+func mysqlGoType(col Column) string {
+	typeDef := col.TypeDef
+	if typeDef == "" {
+		typeDef = col.Type
+	}
+	base, args, unsigned := splitTypeDef(typeDef)
+
+	switch {
+	case base == "tinyint" && args == "1":
+		// The conventional MySQL boolean
+		return "bool"
+	case base == "bit":
+		if args == "" || args == "1" {
+			return "bool"
+		}
+		return "uint64"
+	}
+	goType, ok := mysqlTypes[base]
+	if !ok {
+		return "string"
+	}
+	if unsigned && strings.HasPrefix(goType, "int") {
+		goType = "u" + goType
+	}
+	return goType
+}
+
+// sqliteTypes maps the SQLite declared types whose affinity alone would lose
+// their meaning, like dates stored as text
+var sqliteTypes = map[string]string{
+	"bool":      "bool",
+	"boolean":   "bool",
+	"date":      "time.Time",
+	"datetime":  "time.Time",
+	"timestamp": "time.Time",
+	"json":      "map[string]interface{}",
+	"decimal":   "float64",
+	"numeric":   "float64",
+}
+
+// sqliteGoType maps a declared type through the SQLite type affinity rules:
+// any type containing INT is an integer, CHAR, CLOB or TEXT a string, BLOB
+// bytes, and the REAL and NUMERIC affinities a float
+func sqliteGoType(col Column) string {
+	base, _, _ := splitTypeDef(col.Type)
+	if goType, ok := sqliteTypes[base]; ok {
+		return goType
+	}
+
+	switch {
+	case base == "":
+		// Columns without a declared type store any value
+		return "interface{}"
+	case strings.Contains(base, "int"):
+		return "int64"
+	case strings.Contains(base, "char"), strings.Contains(base, "clob"), strings.Contains(base, "text"):
+		return "string"
+	case strings.Contains(base, "blob"):
+		return "[]byte"
+	}
+	return "float64"
+}
+
+// splitTypeDef splits a type definition like INT(11) UNSIGNED into its
+// lowercase base type, the arguments between parentheses and whether it is
+// unsigned
+func splitTypeDef(typeDef string) (base, args string, unsigned bool) {
+	typeDef = strings.ToLower(strings.TrimSpace(typeDef))
+	if open := strings.Index(typeDef, "("); open >= 0 {
+		if end := strings.Index(typeDef[open:], ")"); end >= 0 {
+			args = strings.TrimSpace(typeDef[open+1 : open+end])
+			typeDef = typeDef[:open] + " " + typeDef[open+end+1:]
+		}
+	}
+
+	var words []string
+	for _, word := range strings.Fields(typeDef) {
+		switch word {
+		case "unsigned":
+			unsigned = true
+		case "signed", "zerofill":
+		default:
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " "), args, unsigned
+}
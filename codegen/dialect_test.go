@@ -0,0 +1,125 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestColumnGoType(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		col      Column
+		expected string
+	}{
+		{DialectPostgres, Column{Type: "integer"}, "int"},
+		{DialectPostgres, Column{Type: "text", IsNullable: true}, "*string"},
+		{DialectPostgres, Column{Type: "timestamp with time zone"}, "*time.Time"},
+		{"", Column{Type: "jsonb"}, "map[string]interface{}"},
+		{DialectPostgres, Column{Type: "ARRAY", TypeDef: "text[]", IsNullable: true}, "[]string"},
+		{DialectPostgres, Column{Type: "ARRAY", TypeDef: "integer[]"}, "[]int"},
+		{DialectMySQL, Column{Type: "tinyint", TypeDef: "tinyint(1)"}, "bool"},
+		{DialectMySQL, Column{Type: "tinyint", TypeDef: "tinyint(4)"}, "int8"},
+		{DialectMySQL, Column{Type: "int", TypeDef: "int unsigned"}, "uint"},
+		{DialectMySQL, Column{Type: "bigint", TypeDef: "bigint(20) unsigned zerofill", IsNullable: true}, "*uint64"},
+		{DialectMySQL, Column{Type: "decimal", TypeDef: "decimal(10,2)"}, "float64"},
+		{DialectMySQL, Column{Type: "datetime", TypeDef: "datetime"}, "*time.Time"},
+		{DialectMySQL, Column{Type: "varbinary", TypeDef: "varbinary(16)", IsNullable: true}, "[]byte"},
+		{DialectMySQL, Column{Type: "bit", TypeDef: "bit(1)"}, "bool"},
+		{DialectMySQL, Column{Type: "json", IsNullable: true}, "map[string]interface{}"},
+		{DialectMySQL, Column{Type: "enum", TypeDef: "enum('draft','published')"}, "string"},
+		{DialectSQLite, Column{Type: "INTEGER"}, "int64"},
+		{DialectSQLite, Column{Type: "UNSIGNED BIG INT"}, "int64"},
+		{DialectSQLite, Column{Type: "VARCHAR(255)", IsNullable: true}, "*string"},
+		{DialectSQLite, Column{Type: "REAL"}, "float64"},
+		{DialectSQLite, Column{Type: "DECIMAL(10,2)"}, "float64"},
+		{DialectSQLite, Column{Type: "BLOB"}, "[]byte"},
+		{DialectSQLite, Column{Type: "BOOLEAN"}, "bool"},
+		{DialectSQLite, Column{Type: "DATETIME"}, "*time.Time"},
+		{DialectSQLite, Column{Type: ""}, "interface{}"},
+	}
+
+	for _, tt := range tests {
+		name := tt.col.Type
+		if tt.col.TypeDef != "" {
+			name = tt.col.TypeDef
+		}
+		t.Run(tt.dialect+"/"+name, func(t *testing.T) {
+			g := &Generator{Dialect: tt.dialect}
+			result, _, err := g.columnGoType(tt.col)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("columnGoType(%+v) = %s; want %s", tt.col, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitTypeDef(t *testing.T) {
+	tests := []struct {
+		typeDef  string
+		base     string
+		args     string
+		unsigned bool
+	}{
+		{"int", "int", "", false},
+		{"INT(11) UNSIGNED", "int", "11", true},
+		{"decimal(10, 2)", "decimal", "10, 2", false},
+		{"double precision", "double precision", "", false},
+		{"  ", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeDef, func(t *testing.T) {
+			base, args, unsigned := splitTypeDef(tt.typeDef)
+			if base != tt.base || args != tt.args || unsigned != tt.unsigned {
+				t.Errorf("splitTypeDef(%q) = %q, %q, %v; want %q, %q, %v", tt.typeDef, base, args, unsigned, tt.base, tt.args, tt.unsigned)
+			}
+		})
+	}
+}
+
+func TestGenerateStructsMySQL(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	g := NewGenerator(cfg, nil)
+	g.Dialect = DialectMySQL
+	tables := map[string]TableSchema{
+		"files": {TableName: "files", Columns: []Column{
+			{Name: "id", Type: "int", TypeDef: "int unsigned", IsPrimaryKey: true},
+			{Name: "is_public", Type: "tinyint", TypeDef: "tinyint(1)"},
+			{Name: "content", Type: "longblob", TypeDef: "longblob", IsNullable: true},
+		}},
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	model, err := os.ReadFile(filepath.Join(dir, "models", "file.go"))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	for _, want := range []string{
+		"Id       uint   `json:\"id,omitempty\" db:\"id\" pk:\"true\"`",
+		"IsPublic bool   `json:\"isPublic\" db:\"is_public\"`",
+		"Content  []byte `json:\"content,omitempty\" db:\"content\"`",
+	} {
+		if !strings.Contains(string(model), want) {
+			t.Errorf("Expected model to contain %q, got:\n%s", want, model)
+		}
+	}
+
+	fields, err := g.modelFields("File")
+	if err != nil {
+		t.Fatalf("Failed to read model fields: %v", err)
+	}
+	if fields[2].Type != "[]byte" || fields[2].IsPointer {
+		t.Errorf("Expected content to be extracted as []byte, got %+v", fields[2])
+	}
+}
@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []byte
+		after    []byte
+		expected string
+	}{
+		{
+			name:     "identical content",
+			before:   []byte("a\nb\n"),
+			after:    []byte("a\nb\n"),
+			expected: "",
+		},
+		{
+			name:     "new file",
+			before:   nil,
+			after:    []byte("a\nb\n"),
+			expected: "--- /dev/null\n+++ b/file.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "removed file",
+			before:   []byte("a\nb\n"),
+			after:    nil,
+			expected: "--- a/file.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "changed line",
+			before:   []byte("a\nb\nc\n"),
+			after:    []byte("a\nB\nc\n"),
+			expected: "--- a/file.go\n+++ b/file.go\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "missing trailing newline",
+			before:   []byte("a\n"),
+			after:    []byte("a"),
+			expected: "--- a/file.go\n+++ b/file.go\n@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("file.go", tt.before, tt.after)
+			if got != tt.expected {
+				t.Errorf("Expected diff:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffSplitsDistantChanges(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf("line %d\n", i)
+		before = append(before, line)
+		after = append(after, line)
+	}
+	after[1] = "first\n"
+	after[18] = "second\n"
+
+	got := UnifiedDiff("file.go", []byte(strings.Join(before, "")), []byte(strings.Join(after, "")))
+
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Errorf("Expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") {
+		t.Errorf("Expected first hunk header '@@ -1,5 +1,5 @@', got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -16,5 +16,5 @@") {
+		t.Errorf("Expected second hunk header '@@ -16,5 +16,5 @@', got:\n%s", got)
+	}
+}
@@ -40,82 +40,117 @@ type ResourceDTOs struct {
 	DTOs       map[string]DTOSchema
 }
 
-func generateDTOForStruct(dtosDir string, structName string) {
+func (g *Generator) generateDTOForStruct(dtosDir string, structName string) error {
 	dtoFile := filepath.Join(dtosDir, strings.ToLower(structName)+".go")
 
-	cfg, _ := LoadConfig()
-	projectRoot, _ := findProjectRoot()
-	modelsDir := cfg.Codegen.Output.Models
-	if !filepath.IsAbs(modelsDir) {
-		modelsDir = filepath.Join(projectRoot, modelsDir)
+	fields, err := g.modelFields(structName)
+	if err != nil {
+		return err
 	}
-	modelPath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
-	fields := extractStructFields(modelPath, structName)
 
-	code := generateDTOsFromModel(structName, fields)
-	if err := os.WriteFile(dtoFile, []byte(code), 0644); err != nil {
-		log.Fatalf("failed to write DTOs for %s: %v", structName, err)
+	code, err := g.generateDTOsFromModel(structName, fields)
+	if err != nil {
+		return err
+	}
+	written, err := g.writeGenerated(dtoFile, []byte(code))
+	if err != nil {
+		return err
 	}
-	log.Printf("📝 Generated DTOs for model: %s → %s", structName, dtoFile)
+	if written && !g.Output.DryRun {
+		log.Printf("📝 Generated DTOs for model: %s → %s", structName, dtoFile)
+	}
+	return nil
 }
 
-func generateDTOsFromModel(structName string, fields []StructField) string {
-	needsTimeImport := false
-	for _, f := range fields {
-		if f.Type == "time.Time" {
-			needsTimeImport = true
-			break
+func (g *Generator) generateDTOsFromModel(structName string, fields []StructField) (string, error) {
+	relations, err := g.modelRelations(fields)
+	if err != nil {
+		return "", err
+	}
+	table, err := g.modelTable(structName)
+	if err != nil {
+		return "", err
+	}
+	fields = qualifyModelTypes(fields)
+
+	data := DTOTemplateData{
+		StructName:   structName,
+		Fields:       readDTOFields(fields),
+		CreateFields: createDTOFields(fields),
+		UpdateFields: updateDTOFields(fields),
+		Relations:    relationTemplateData(g.tableOptions().Path(table), relations),
+	}
+	// Only resources with a primary key have a Patch handler
+	if len(primaryKeyFields(fields)) > 0 {
+		data.PatchFields = patchDTOFields(fields)
+	}
+	// Field types from other packages need the imports of the model
+	known, err := g.modelImports(structName)
+	if err != nil {
+		return "", err
+	}
+	for name, importPath := range knownPackages {
+		if _, ok := known[name]; !ok {
+			known[name] = importPath
 		}
 	}
-
-	timeImport := ""
-	if needsTimeImport {
-		timeImport = `import "time"`
+	known["models"], _ = g.packageImports()
+	var types []string
+	for _, group := range [][]TemplateField{data.Fields, data.CreateFields, data.UpdateFields, data.PatchFields} {
+		for _, f := range group {
+			types = append(types, f.Type)
+		}
+	}
+	for _, importPath := range typeImports(types, known) {
+		if importPath == "time" {
+			data.NeedsTime = true
+			continue
+		}
+		data.Imports = append(data.Imports, importPath)
 	}
 
-	dtoFields := generateDTOFields(fields)
-	createFields := generateCreateDTOFields(fields)
-	updateFields := generateUpdateDTOFields(fields)
-
-	return fmt.Sprintf(`package dtos
-
-%s
+	return g.renderTemplate(DTOTemplate, data)
+}
 
-type %sDTO struct {
-%s}
+func newTemplateField(field StructField) TemplateField {
+	typeStr := field.Type
+	if field.IsPointer {
+		typeStr = "*" + typeStr
+	}
 
-type %sCreateDTO struct {
-%s}
+	jsonTag := field.JSONTag
+	if jsonTag == "" {
+		jsonTag = toJSONCamelCase(field.Name)
+	}
 
-type %sUpdateDTO struct {
-%s}
-`, timeImport, structName, dtoFields, structName, createFields, structName, updateFields)
+	return TemplateField{
+		Name:     field.Name,
+		Type:     typeStr,
+		JSONTag:  jsonTag,
+		DBTag:    field.DBTag,
+		Nullable: field.IsPointer,
+		Validate: field.ValidateTag,
+		NullType: field.NullType,
+	}
 }
 
-func generateDTOFields(fields []StructField) string {
-	var result strings.Builder
+// readDTOFields returns the fields exposed by the read DTO
+func readDTOFields(fields []StructField) []TemplateField {
+	var result []TemplateField
 	for _, field := range fields {
 		if field.DTOTag == "-" || field.DTOTag == "write" {
 			continue
 		}
-
-		typeStr := field.Type
-		if field.IsPointer {
-			typeStr = "*" + typeStr
-		}
-
-		jsonTag := field.JSONTag
-		if jsonTag == "" {
-			jsonTag = toJSONCamelCase(field.Name)
-		}
-
-		result.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\"`\n", field.Name, typeStr, jsonTag))
+		result = append(result, newTemplateField(field))
 	}
-	return result.String()
+	return result
 }
 
-func generateCreateDTOFields(fields []StructField) string {
-	var result strings.Builder
+// createDTOFields returns the fields accepted by the create DTO, leaving out
+// the id and timestamps managed by the database. Other primary key columns are
+// accepted so that natural and composite keys can be supplied by the client.
+func createDTOFields(fields []StructField) []TemplateField {
+	var result []TemplateField
 	for _, field := range fields {
 		dbTag := strings.ToLower(field.DBTag)
 		if dbTag == FieldID || dbTag == FieldCreatedAt || dbTag == FieldUpdatedAt {
@@ -125,80 +160,141 @@ func generateCreateDTOFields(fields []StructField) string {
 		if field.DTOTag == "-" || field.DTOTag == "read" {
 			continue
 		}
-
-		typeStr := field.Type
-		if field.IsPointer {
-			typeStr = "*" + typeStr
-		}
-
-		jsonTag := field.JSONTag
-		if jsonTag == "" {
-			jsonTag = toJSONCamelCase(field.Name)
-		}
-
-		result.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\"`\n", field.Name, typeStr, jsonTag))
+		result = append(result, newWriteTemplateField(field))
 	}
-	return result.String()
+	return result
 }
 
-func generateUpdateDTOFields(fields []StructField) string {
-	var result strings.Builder
+// updateDTOFields returns the fields accepted by the update DTO. The primary
+// key is taken from the route and cannot be changed.
+func updateDTOFields(fields []StructField) []TemplateField {
+	var result []TemplateField
 	for _, field := range fields {
 		dbTag := strings.ToLower(field.DBTag)
-		if dbTag == FieldID || dbTag == FieldCreatedAt || dbTag == FieldUpdatedAt {
+		if dbTag == FieldID || dbTag == FieldCreatedAt || dbTag == FieldUpdatedAt || field.IsPrimaryKey {
 			continue
 		}
 
 		if field.DTOTag == "-" || field.DTOTag == "read" {
 			continue
 		}
+		result = append(result, newWriteTemplateField(field))
+	}
+	return result
+}
 
-		typeStr := field.Type
-		if field.IsPointer {
-			typeStr = "*" + typeStr
+// patchDTOFields returns the fields accepted by the patch DTO: the update DTO
+// fields stored in a column, as pointers so that absent fields can be told
+// apart from zero values. No field is required.
+func patchDTOFields(fields []StructField) []TemplateField {
+	var result []TemplateField
+	for _, field := range updateDTOFields(fields) {
+		if field.DBTag == "" {
+			continue
+		}
+		if !field.Nullable {
+			field.Type = "*" + field.Type
 		}
+		if rules := withoutRule(field.Validate, "required"); rules != "" && !hasRule(rules, "omitempty") {
+			field.Validate = "omitempty," + rules
+		} else {
+			field.Validate = rules
+		}
+		result = append(result, field)
+	}
+	return result
+}
 
-		jsonTag := field.JSONTag
-		if jsonTag == "" {
-			jsonTag = toJSONCamelCase(field.Name)
+// newWriteTemplateField returns a field of the write DTOs. UserId is filled in
+// from the authenticated user by the generated handlers, so it is never required.
+func newWriteTemplateField(field StructField) TemplateField {
+	templateField := newTemplateField(field)
+	if isAuthUserIDField(field) && hasRule(field.ValidateTag, "required") {
+		rules := withoutRule(field.ValidateTag, "required")
+		if rules != "" && field.IsPointer {
+			rules = "omitempty," + rules
 		}
+		templateField.Validate = rules
+	}
+	return templateField
+}
+
+// isAuthUserIDField reports whether the field is the UserId field filled in
+// from the authenticated user, whose ID is a string. Fields of other types,
+// like an integer user_id, are left to the request body.
+func isAuthUserIDField(field StructField) bool {
+	return field.Name == "UserId" && field.Type == "string"
+}
 
-		result.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\"`\n", field.Name, typeStr, jsonTag))
+// primaryKeyFields returns the fields making up the primary key of a model:
+// the fields tagged pk:"true", or the id field of models without key tags
+func primaryKeyFields(fields []StructField) []StructField {
+	var key []StructField
+	for _, field := range fields {
+		if field.IsPrimaryKey {
+			key = append(key, field)
+		}
+	}
+	if len(key) > 0 {
+		return key
+	}
+
+	for _, field := range fields {
+		if strings.ToLower(field.DBTag) == FieldID {
+			return []StructField{field}
+		}
 	}
-	return result.String()
+	return nil
 }
 
-func LoadResourceDTOs() map[string]ResourceDTOs {
-	cfg, _ := LoadConfig()
-	projectRoot, err := findProjectRoot()
+// isDefaultKey reports whether key is the single "id" column the crud package
+// works with
+func isDefaultKey(key []StructField) bool {
+	return len(key) == 1 && strings.ToLower(key[0].DBTag) == FieldID
+}
+
+func LoadResourceDTOs() (map[string]ResourceDTOs, error) {
+	g, err := newDefaultGenerator()
 	if err != nil {
-		log.Fatalf("failed to find project root: %v", err)
+		return nil, err
 	}
+	return g.LoadResourceDTOs()
+}
 
-	dtosDir := cfg.Codegen.Output.DTOs
-	if !filepath.IsAbs(dtosDir) {
-		dtosDir = filepath.Join(projectRoot, dtosDir)
-	}
-	if _, err := os.Stat(dtosDir); os.IsNotExist(err) {
-		log.Fatal("❌ DTOs directory not found. Run 'make resourcegen' first.")
+// LoadResourceDTOs parses the DTO structs from the DTOs directory, including
+// DTOs generated earlier in the same run
+func (g *Generator) LoadResourceDTOs() (map[string]ResourceDTOs, error) {
+	dtosDir, err := g.dtosDir()
+	if err != nil {
+		return nil, err
 	}
 
-	files, err := os.ReadDir(dtosDir)
+	files, err := g.Output.ReadDir(dtosDir)
+	if os.IsNotExist(err) {
+		return nil, &ParseError{Path: dtosDir, Err: fmt.Errorf("DTOs directory not found, run the resources command first")}
+	}
 	if err != nil {
-		log.Fatalf("❌ Failed to read dtos directory: %v", err)
+		return nil, &ParseError{Path: dtosDir, Err: err}
 	}
 
 	resources := make(map[string]ResourceDTOs)
 
 	for _, file := range files {
-		if !strings.HasSuffix(file.Name(), ".go") {
+		if !strings.HasSuffix(file, ".go") {
 			continue
 		}
 
-		filePath := filepath.Join(dtosDir, file.Name())
-		resourceName := strings.TrimSuffix(file.Name(), ".go")
+		filePath := filepath.Join(dtosDir, file)
+		resourceName := strings.TrimSuffix(file, ".go")
 
-		dtos := extractDTOsFromResourceFile(filePath)
+		src, err := g.Output.ReadFile(filePath)
+		if err != nil {
+			return nil, &ParseError{Path: filePath, Err: err}
+		}
+		dtos, err := extractDTOsFromSource(filePath, src)
+		if err != nil {
+			return nil, err
+		}
 		if len(dtos) > 0 {
 			resources[resourceName] = ResourceDTOs{
 				Name:       resourceName,
@@ -208,12 +304,12 @@ func LoadResourceDTOs() map[string]ResourceDTOs {
 		}
 	}
 
-	return resources
+	return resources, nil
 }
 
 func (r *ResourceDTOs) GetMainDTO() *DTOSchema {
 	for name, dto := range r.DTOs {
-		if !strings.Contains(name, "Create") && !strings.Contains(name, "Update") {
+		if !strings.Contains(name, "Create") && !strings.Contains(name, "Update") && !strings.Contains(name, "Patch") {
 			return &dto
 		}
 	}
@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// enumTypeNames returns the Go type names of the enum types used by the
+// tables, keyed by enum type. Names taken by a model struct get an Enum suffix.
+func enumTypeNames(tables map[string]TableSchema, opts TableOptions) map[string]string {
+	structs := make(map[string]bool, len(tables))
+	for name := range tables {
+		structs[opts.StructName(name)] = true
+	}
+
+	names := make(map[string]string)
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if col.EnumType == "" || len(col.EnumValues) == 0 {
+				continue
+			}
+			name := goIdentifier(col.EnumType)
+			if structs[name] {
+				name += "Enum"
+			}
+			names[col.EnumType] = name
+		}
+	}
+	return names
+}
+
+// goIdentifier turns a database name, like order_status or in review, into an
+// exported Go identifier, like OrderStatus or InReview
+func goIdentifier(name string) string {
+	caser := cases.Title(language.English)
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, part := range parts {
+		parts[i] = caser.String(part)
+	}
+	return strings.Join(parts, "")
+}
+
+// enumTemplateData returns the constants of an enum type, named after the
+// type and the label, like MoodHappy. Labels yielding the same name are
+// numbered.
+func enumTemplateData(typeName, enumType string, labels []string) EnumTemplateData {
+	data := EnumTemplateData{TypeName: typeName, EnumType: enumType}
+	seen := make(map[string]bool)
+	for i, label := range labels {
+		name := typeName + goIdentifier(label)
+		if name == typeName {
+			name += "Empty"
+		}
+		if seen[name] {
+			name = fmt.Sprintf("%s%d", name, i+1)
+		}
+		seen[name] = true
+		data.Values = append(data.Values, EnumTemplateValue{Name: name, Value: label})
+	}
+	return data
+}
+
+// generateEnums writes a file per enum type to the models directory. enums
+// holds the labels of the enum types used by the generated models.
+func (g *Generator) generateEnums(modelsDir string, enums map[string][]string) error {
+	enumTypes := make([]string, 0, len(enums))
+	for enumType := range enums {
+		enumTypes = append(enumTypes, enumType)
+	}
+	sort.Strings(enumTypes)
+
+	for _, enumType := range enumTypes {
+		typeName := g.enumTypes[enumType]
+		code, err := g.renderTemplate(EnumTemplate, enumTemplateData(typeName, enumType, enums[enumType]))
+		if err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(modelsDir, strings.ToLower(typeName)+".go")
+		written, err := g.writeGenerated(filePath, []byte(code))
+		if err != nil {
+			return err
+		}
+		if written && !g.Output.DryRun {
+			fmt.Printf("✅ Generated enum for type: %s → %s\n", enumType, filePath)
+		}
+	}
+	return nil
+}
+
+// qualifyModelTypes qualifies the types declared by the models package, like
+// enum types, for use in the dtos package
+func qualifyModelTypes(fields []StructField) []StructField {
+	qualified := make([]StructField, len(fields))
+	for i, field := range fields {
+		base := strings.TrimLeft(field.Type, "*[]")
+		if token.IsIdentifier(base) && types.Universe.Lookup(base) == nil {
+			field.Type = field.Type[:len(field.Type)-len(base)] + "models." + base
+		}
+		qualified[i] = field
+	}
+	return qualified
+}
@@ -0,0 +1,112 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestEnumTemplateData(t *testing.T) {
+	tests := []struct {
+		labels   []string
+		expected []string
+	}{
+		{[]string{"happy", "sad"}, []string{"MoodHappy", "MoodSad"}},
+		{[]string{"so-so", "not happy", "IN_REVIEW"}, []string{"MoodSoSo", "MoodNotHappy", "MoodInReview"}},
+		{[]string{"", "?"}, []string{"MoodEmpty", "MoodEmpty2"}},
+		{[]string{"a b", "a-b"}, []string{"MoodAB", "MoodAB2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.labels, ","), func(t *testing.T) {
+			data := enumTemplateData("Mood", "mood", tt.labels)
+			var names []string
+			for i, value := range data.Values {
+				names = append(names, value.Name)
+				if value.Value != tt.labels[i] {
+					t.Errorf("Expected value %q, got %q", tt.labels[i], value.Value)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected constants %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestGenerateStructsEnums(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	g := NewGenerator(cfg, nil)
+	g.Options.Types = TypeMap{"visibility": "string"}
+	tables := map[string]TableSchema{
+		"posts": {TableName: "posts", Columns: []Column{
+			{Name: "id", Type: "integer"},
+			{Name: "mood", Type: "mood", EnumType: "mood", EnumValues: []string{"happy", "sad"}},
+			{Name: "kind", Type: "post", EnumType: "post", EnumValues: []string{"draft", "published"}, IsNullable: true},
+			{Name: "visibility", Type: "visibility", EnumType: "visibility", EnumValues: []string{"public", "private"}},
+		}},
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	model, err := os.ReadFile(filepath.Join(dir, "models", "post.go"))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	for _, want := range []string{
+		"Mood       Mood      `json:\"mood\" db:\"mood\" validate:\"required,oneof=happy sad\"`",
+		"Kind       *PostEnum `json:\"kind,omitempty\" db:\"kind\" validate:\"omitempty,oneof=draft published\"`",
+		"Visibility string    `json:\"visibility\" db:\"visibility\" validate:\"required,oneof=public private\"`",
+	} {
+		if !strings.Contains(string(model), want) {
+			t.Errorf("Expected model to contain %q, got:\n%s", want, model)
+		}
+	}
+
+	enum, err := os.ReadFile(filepath.Join(dir, "models", "mood.go"))
+	if err != nil {
+		t.Fatalf("Failed to read enum: %v", err)
+	}
+	for _, want := range []string{
+		"type Mood string",
+		"MoodHappy Mood = \"happy\"",
+		"var MoodValues = []Mood{MoodHappy, MoodSad}",
+		"func (e Mood) Valid() bool",
+	} {
+		if !strings.Contains(string(enum), want) {
+			t.Errorf("Expected enum to contain %q, got:\n%s", want, enum)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "postenum.go")); err != nil {
+		t.Errorf("Expected enum named after a model to be suffixed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "visibility.go")); err == nil {
+		t.Error("Expected mapped enum not to be generated")
+	}
+
+	fields, err := g.modelFields("Post")
+	if err != nil {
+		t.Fatalf("Failed to read model fields: %v", err)
+	}
+	dto, err := g.generateDTOsFromModel("Post", fields)
+	if err != nil {
+		t.Fatalf("Failed to generate DTOs: %v", err)
+	}
+	modelsImport, _ := g.packageImports()
+	for _, want := range []string{
+		"import \"" + modelsImport + "\"",
+		"Mood models.Mood `json:\"mood\"`",
+		"Kind *models.PostEnum `json:\"kind\"`",
+	} {
+		if !strings.Contains(dto, want) {
+			t.Errorf("Expected DTOs to contain %q, got:\n%s", want, dto)
+		}
+	}
+}
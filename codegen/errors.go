@@ -0,0 +1,142 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaError is returned when the database schema cannot be introspected
+type SchemaError struct {
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("failed to load schema: %v", e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is returned when the project configuration or an output path
+// derived from it cannot be resolved
+type ConfigError struct {
+	Op  string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError is returned when a generated file or output directory cannot be written
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is returned when a Go or SQL source file or a schema snapshot
+// used as generator input cannot be read or parsed
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s: %v", e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// OutOfDateError is returned in diff mode when the generated files on disk do not
+// match what the generators would produce
+type OutOfDateError struct {
+	Paths []string
+}
+
+func (e *OutOfDateError) Error() string {
+	return fmt.Sprintf("%d generated file(s) out of date: %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// ConflictError is returned when generated files were modified since they were
+// generated. They are left untouched unless the generator is forced.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d generated file(s) modified by hand, use --force to overwrite: %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// FormatError is returned when a generated Go file is not valid Go source and
+// cannot be formatted, usually because of a custom template
+type FormatError struct {
+	Path string
+	Err  error
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("failed to format generated %s: %v", e.Path, e.Err)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// TemplateError is returned when a generation template cannot be loaded, parsed
+// or executed
+type TemplateError struct {
+	Name string
+	Err  error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %s: %v", e.Name, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyError is returned when the generated code does not type-check. Each
+// problem is mapped back to the table and column it was generated from when
+// known.
+type VerifyError struct {
+	Problems []VerifyProblem
+}
+
+func (e *VerifyError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return fmt.Sprintf("generated code does not compile, %d problem(s):\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// DriftError is returned when the schema and the models on disk differ, so
+// that an API not regenerated after a schema change is caught before it ships
+type DriftError struct {
+	Problems []DriftProblem
+}
+
+func (e *DriftError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return fmt.Sprintf("models drifted from the schema, %d problem(s):\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,61 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSchemaWithoutDatabase(t *testing.T) {
+	_, err := LoadSchema(nil)
+
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected SchemaError, got %v", err)
+	}
+}
+
+func TestParseStructsReturnsParseError(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "broken.go")
+	if err := os.WriteFile(testFile, []byte("package models\n\ntype User struct {"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := parseStructs(testFile)
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got %v", err)
+	}
+	if parseErr.Path != testFile {
+		t.Errorf("Expected path %s, got %s", testFile, parseErr.Path)
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		msg  string
+	}{
+		{"schema", &SchemaError{Err: cause}, "failed to load schema: boom"},
+		{"config", &ConfigError{Op: "load config", Err: cause}, "failed to load config: boom"},
+		{"write", &WriteError{Path: "models/user.go", Err: cause}, "failed to write models/user.go: boom"},
+		{"parse", &ParseError{Path: "models/user.go", Err: cause}, "failed to parse models/user.go: boom"},
+		{"format", &FormatError{Path: "models/user.go", Err: cause}, "failed to format generated models/user.go: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, cause) {
+				t.Error("Expected error to wrap its cause")
+			}
+			if tt.err.Error() != tt.msg {
+				t.Errorf("Error() = %q; want %q", tt.err.Error(), tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package codegen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// formatSource formats a generated Go file like gofmt and goimports: unused
+// imports are removed and standard library imports are grouped apart, so that
+// generated code passes lint gates as is
+func formatSource(path string, src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, &FormatError{Path: path, Err: err}
+	}
+	for _, tidy := range []func(string, []byte) ([]byte, error){pruneImports, groupImports} {
+		tidied, err := tidy(path, formatted)
+		if err != nil {
+			return nil, &FormatError{Path: path, Err: err}
+		}
+		if bytes.Equal(tidied, formatted) {
+			continue
+		}
+		if formatted, err = format.Source(tidied); err != nil {
+			return nil, &FormatError{Path: path, Err: err}
+		}
+	}
+	return formatted, nil
+}
+
+// pruneImports removes the lines of the unused imports of gofmt'ed src,
+// dropping import declarations left empty. A package is considered used when
+// its name qualifies any identifier, so imports are never wrongly removed.
+func pruneImports(path string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	// Lines to remove, 1-based
+	remove := make(map[int]bool)
+	removeNode := func(n ast.Node) {
+		for line := fset.Position(n.Pos()).Line; line <= fset.Position(n.End()).Line; line++ {
+			remove[line] = true
+		}
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var unused []ast.Spec
+		for _, spec := range gen.Specs {
+			if !isUsedImport(spec.(*ast.ImportSpec), used) {
+				unused = append(unused, spec)
+			}
+		}
+		if len(unused) == len(gen.Specs) {
+			removeNode(gen)
+			continue
+		}
+		for _, spec := range unused {
+			removeNode(spec)
+		}
+	}
+	if len(remove) == 0 {
+		return src, nil
+	}
+
+	var b bytes.Buffer
+	for i, line := range bytes.SplitAfter(src, []byte("\n")) {
+		if !remove[i+1] {
+			b.Write(line)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func isUsedImport(spec *ast.ImportSpec, used map[string]bool) bool {
+	importPath, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return true
+	}
+	name := importName(importPath)
+	if spec.Name != nil {
+		name = spec.Name.Name
+	}
+	// Blank and dot imports are kept for their side effects, and paths whose
+	// package name cannot be guessed are kept as well
+	if name == "_" || name == "." || !token.IsIdentifier(name) {
+		return true
+	}
+	return used[name]
+}
+
+// groupImports moves standard library imports before the others, separated
+// by a blank line, in the import blocks of gofmt'ed src
+func groupImports(path string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.SplitAfter(src, []byte("\n"))
+
+	// Runs of imports on successive lines, gofmt writing one per line
+	var runs [][]*ast.ImportSpec
+	for i, spec := range file.Imports {
+		if fset.Position(spec.Pos()).Line != fset.Position(spec.End()).Line {
+			return src, nil
+		}
+		if i > 0 && fset.Position(spec.Pos()).Line == fset.Position(file.Imports[i-1].Pos()).Line+1 {
+			runs[len(runs)-1] = append(runs[len(runs)-1], spec)
+			continue
+		}
+		runs = append(runs, []*ast.ImportSpec{spec})
+	}
+
+	// Replacement of the first line of each run to regroup, 0-based
+	regrouped := make(map[int][]byte)
+	skip := make(map[int]bool)
+	for _, run := range runs {
+		var std, other [][]byte
+		for _, spec := range run {
+			line := lines[fset.Position(spec.Pos()).Line-1]
+			if isStdImport(spec) {
+				std = append(std, line)
+			} else {
+				other = append(other, line)
+			}
+		}
+		if len(std) == 0 || len(other) == 0 {
+			continue
+		}
+
+		first := fset.Position(run[0].Pos()).Line - 1
+		regrouped[first] = append(append(bytes.Join(std, nil), '\n'), bytes.Join(other, nil)...)
+		for i := 1; i < len(run); i++ {
+			skip[first+i] = true
+		}
+	}
+	if len(regrouped) == 0 {
+		return src, nil
+	}
+
+	var b bytes.Buffer
+	for i, line := range lines {
+		if replacement, ok := regrouped[i]; ok {
+			b.Write(replacement)
+		} else if !skip[i] {
+			b.Write(line)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// isStdImport reports whether spec imports a standard library package, whose
+// path has no dot in its first element
+func isStdImport(spec *ast.ImportSpec) bool {
+	importPath, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return false
+	}
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "gofmt",
+			src:      "package models\n\ntype User struct {\n\tId int `json:\"id\"`\n\tEmail   string `json:\"email\"`\n}\nfunc (User) TableName() string {\n\treturn \"users\" \n}\n",
+			expected: "package models\n\ntype User struct {\n\tId    int    `json:\"id\"`\n\tEmail string `json:\"email\"`\n}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+		},
+		{
+			name:     "unused imports",
+			src:      "package dtos\n\nimport (\n\t\"time\"\n\t\"database/sql\"\n)\n\ntype UserDTO struct {\n\tBio sql.NullString\n}\n",
+			expected: "package dtos\n\nimport (\n\t\"database/sql\"\n)\n\ntype UserDTO struct {\n\tBio sql.NullString\n}\n",
+		},
+		{
+			name:     "unused import declaration",
+			src:      "package dtos\n\nimport \"time\"\n\ntype UserDTO struct {\n\tId int\n}\n",
+			expected: "package dtos\n\ntype UserDTO struct {\n\tId int\n}\n",
+		},
+		{
+			name:     "blank and aliased imports",
+			src:      "package resources\n\nimport (\n\t_ \"embed\"\n\tauth \"github.com/nicolasbonnici/gorest-auth\"\n\t\"github.com/mattn/go-sqlite3\"\n)\n\nvar _ = auth.Context\n",
+			expected: "package resources\n\nimport (\n\t_ \"embed\"\n\n\t\"github.com/mattn/go-sqlite3\"\n\tauth \"github.com/nicolasbonnici/gorest-auth\"\n)\n\nvar _ = auth.Context\n",
+		},
+		{
+			name:     "standard library grouped first",
+			src:      "package models\n\nimport (\n\t\"database/sql\"\n\t\"github.com/google/uuid\"\n\t\"time\"\n)\n\nvar (\n\t_ sql.NullString\n\t_ uuid.UUID\n\t_ time.Time\n)\n",
+			expected: "package models\n\nimport (\n\t\"database/sql\"\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)\n\nvar (\n\t_ sql.NullString\n\t_ uuid.UUID\n\t_ time.Time\n)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatSource("file.go", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Failed to format: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+			again, err := formatSource("file.go", got)
+			if err != nil || string(again) != string(got) {
+				t.Errorf("Expected formatting to be idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatSourceInvalid(t *testing.T) {
+	_, err := formatSource("models/user.go", []byte("package models\n\ntype User struct {\n"))
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("Expected a FormatError, got %v", err)
+	}
+	if formatErr.Path != "models/user.go" {
+		t.Errorf("Expected path models/user.go, got %s", formatErr.Path)
+	}
+}
@@ -0,0 +1,184 @@
+package codegen
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+// Generator runs the code generators for a project and routes every file they
+// produce through its Output, so a run can be written to disk or inspected as
+// a dry run.
+type Generator struct {
+	Config  *config.Config
+	Options *Options
+	Output  *Output
+	// Force overwrites generated files modified since they were generated
+	Force bool
+	// DropTables lets migrations drop the tables of the schema without a
+	// model. They are kept by default, as databases hold tables no model
+	// maps, like the bookkeeping tables of migration tools.
+	DropTables bool
+	// Tables restricts the tables whose files are written to those matching
+	// one of these glob patterns. Files shared by every table, like the
+	// routes and the OpenAPI document, still cover all included tables.
+	Tables []string
+	// Dialect is the database dialect column types are mapped from, like
+	// postgres, mysql or sqlite. Defaults to PostgreSQL.
+	Dialect string
+	// TypeMappers map column types before the types configured in Options
+	// and the dialect defaults
+	TypeMappers []TypeMapper
+
+	// targetDialect is the dialect of the configured database when the
+	// schema is read from migrations applied in SQLite, which cannot replay
+	// migrations written for another database
+	targetDialect string
+
+	templates map[string]*template.Template
+	manifest  *Manifest
+	// schemaHashes holds the schema hash of the models generated in this run,
+	// keyed by path
+	schemaHashes map[string]string
+	// conflicts holds the paths of the files left untouched because they were
+	// modified since they were generated
+	conflicts map[string]bool
+	// enumTypes holds the Go type names of the enum types of the models being
+	// generated, keyed by enum type
+	enumTypes map[string]string
+	// sqlNullFields is set when the generated resources convert sql.Null*
+	// model fields, which needs the nullable.go helpers
+	sqlNullFields bool
+}
+
+func NewGenerator(cfg *config.Config, out *Output) *Generator {
+	if out == nil {
+		out = NewOutput(false)
+	}
+	return &Generator{
+		Config:  cfg,
+		Options: &Options{},
+		Output:  out,
+	}
+}
+
+// newDefaultGenerator loads the project configuration and returns a generator
+// writing straight to disk
+func newDefaultGenerator() (*Generator, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, &ConfigError{Op: "load config", Err: err}
+	}
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return nil, &ConfigError{Op: "find project root", Err: err}
+	}
+	opts, err := LoadOptions(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	g := NewGenerator(cfg, nil)
+	g.Options = opts
+	return g, nil
+}
+
+// outputDir resolves a configured output directory against the project root,
+// leaving absolute paths untouched
+func (g *Generator) outputDir(dir string) (string, error) {
+	if filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return "", &ConfigError{Op: "find project root", Err: err}
+	}
+	return filepath.Join(projectRoot, dir), nil
+}
+
+func (g *Generator) modelsDir() (string, error) {
+	return g.outputDir(g.Config.Codegen.Output.Models)
+}
+
+func (g *Generator) dtosDir() (string, error) {
+	return g.outputDir(g.Config.Codegen.Output.DTOs)
+}
+
+func (g *Generator) resourcesDir() (string, error) {
+	return g.outputDir(g.Config.Codegen.Output.Resources)
+}
+
+func (g *Generator) openAPIDir() (string, error) {
+	return g.outputDir(g.Config.Codegen.Output.OpenAPI)
+}
+
+// modelFields extracts the fields of a model struct from the models directory,
+// including models generated earlier in the same run. Fields of nullable
+// types, like sql.NullString, are seen as pointers to their value.
+func (g *Generator) modelFields(structName string) ([]StructField, error) {
+	modelsDir, err := g.modelsDir()
+	if err != nil {
+		return nil, err
+	}
+	modelPath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
+	src, err := g.Output.ReadFile(modelPath)
+	if err != nil {
+		return nil, &ParseError{Path: modelPath, Err: err}
+	}
+	fields, err := extractStructFieldsSource(modelPath, src, structName)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		fields[i] = g.nullableField(field)
+	}
+	return fields, nil
+}
+
+// modelTable returns the table of a model struct, read from the TableName
+// method of its model file. Models without one map to their pluralized
+// snake case name.
+func (g *Generator) modelTable(structName string) (string, error) {
+	modelsDir, err := g.modelsDir()
+	if err != nil {
+		return "", err
+	}
+	modelPath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
+	src, err := g.Output.ReadFile(modelPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaultTableName(structName), nil
+	}
+	if err != nil {
+		return "", &ParseError{Path: modelPath, Err: err}
+	}
+	tables, err := extractTableNamesSource(modelPath, src)
+	if err != nil {
+		return "", err
+	}
+	if table, ok := tables[structName]; ok {
+		return table, nil
+	}
+	return defaultTableName(structName), nil
+}
+
+// modelImports returns the imports of the model file of a struct keyed by
+// name, empty when the model does not exist
+func (g *Generator) modelImports(structName string) (map[string]string, error) {
+	modelsDir, err := g.modelsDir()
+	if err != nil {
+		return nil, err
+	}
+	modelPath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
+	src, err := g.Output.ReadFile(modelPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, &ParseError{Path: modelPath, Err: err}
+	}
+	return extractImportsSource(modelPath, src)
+}
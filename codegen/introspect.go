@@ -0,0 +1,328 @@
+package codegen
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nicolasbonnici/gorest/database"
+)
+
+// The upstream introspector only reports column names, types and nullability.
+// The queries below fill in the rest of the schema for each supported driver.
+
+var primaryKeyQueries = map[string]string{
+	"postgres": `
+	SELECT kcu.table_name, kcu.column_name
+	FROM information_schema.table_constraints tc
+	JOIN information_schema.key_column_usage kcu
+		ON tc.constraint_name = kcu.constraint_name
+		AND tc.table_schema = kcu.table_schema
+		AND tc.table_name = kcu.table_name
+	WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = 'public'
+	ORDER BY kcu.table_name, kcu.ordinal_position;
+	`,
+	"mysql": `
+	SELECT kcu.table_name, kcu.column_name
+	FROM information_schema.table_constraints tc
+	JOIN information_schema.key_column_usage kcu
+		ON tc.constraint_name = kcu.constraint_name
+		AND tc.table_schema = kcu.table_schema
+		AND tc.table_name = kcu.table_name
+	WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = DATABASE()
+	ORDER BY kcu.table_name, kcu.ordinal_position;
+	`,
+	"sqlite": `
+	SELECT m.name, p.name
+	FROM sqlite_master m
+	JOIN pragma_table_info(m.name) p
+	WHERE m.type = 'table' AND p.pk > 0
+	ORDER BY m.name, p.pk;
+	`,
+}
+
+// indexQueries return the table and the CREATE INDEX statement of the indexes
+// created by statement, leaving out those of keys and UNIQUE constraints.
+// Only SQLite needs them, to recreate the tables migrations alter.
+var indexQueries = map[string]string{
+	"sqlite": `
+	SELECT tbl_name, sql
+	FROM sqlite_master
+	WHERE type = 'index' AND sql IS NOT NULL AND tbl_name NOT LIKE 'sqlite_%'
+	ORDER BY tbl_name, name;
+	`,
+}
+
+// loadIndexes returns the statements creating the indexes of every table.
+// Drivers without a known query yield an empty map.
+func loadIndexes(ctx context.Context, db database.Database) (map[string][]string, error) {
+	indexes := make(map[string][]string)
+
+	q, ok := indexQueries[db.DriverName()]
+	if !ok {
+		return indexes, nil
+	}
+
+	rows, err := db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var table, stmt string
+		if err := rows.Scan(&table, &stmt); err != nil {
+			return nil, err
+		}
+		indexes[table] = append(indexes[table], stmt)
+	}
+	return indexes, rows.Err()
+}
+
+// loadPrimaryKeys returns the primary key columns of every table, in key order.
+// Drivers without a known query yield an empty map.
+func loadPrimaryKeys(ctx context.Context, db database.Database) (map[string][]string, error) {
+	keys := make(map[string][]string)
+
+	q, ok := primaryKeyQueries[db.DriverName()]
+	if !ok {
+		return keys, nil
+	}
+
+	rows, err := db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		keys[table] = append(keys[table], column)
+	}
+	return keys, rows.Err()
+}
+
+// columnMetadataQueries return, for every column: table, column, default
+// expression, character max length, numeric precision and scale, comment,
+// whether a single column unique index exists, the type definition (an enum
+// type is reported as enum('a','b') and a PostgreSQL array as text[], the
+// CHECK constraints on the column (the CREATE TABLE statement in SQLite) and
+// whether MySQL increments it
+var columnMetadataQueries = map[string]string{
+	"postgres": `
+	SELECT c.table_name::text, c.column_name::text,
+		COALESCE(c.column_default, '')::text,
+		COALESCE(c.character_maximum_length, 0)::int,
+		COALESCE(c.numeric_precision, 0)::int,
+		COALESCE(c.numeric_scale, 0)::int,
+		COALESCE(col_description(a.attrelid, a.attnum), ''),
+		CASE WHEN EXISTS (
+			SELECT 1 FROM pg_index i
+			WHERE i.indrelid = a.attrelid AND i.indisunique AND NOT i.indisprimary
+				AND i.indnatts = 1 AND i.indkey[0] = a.attnum AND i.indpred IS NULL
+		) THEN 1 ELSE 0 END,
+		COALESCE((
+			SELECT 'enum(' || string_agg(quote_literal(e.enumlabel), ',' ORDER BY e.enumsortorder) || ')'
+			FROM pg_enum e WHERE e.enumtypid = a.atttypid
+		), CASE WHEN c.data_type = 'ARRAY' THEN format_type(a.atttypid, NULL) ELSE c.data_type::text END),
+		COALESCE((
+			SELECT string_agg(pg_get_constraintdef(con.oid), ' ')
+			FROM pg_constraint con
+			WHERE con.conrelid = a.attrelid AND con.contype = 'c' AND con.conkey = ARRAY[a.attnum]
+		), ''),
+		0
+	FROM information_schema.columns c
+	JOIN pg_attribute a
+		ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+		AND a.attname = c.column_name
+	WHERE c.table_schema = 'public';
+	`,
+	"mysql": `
+	SELECT c.table_name, c.column_name,
+		CASE
+			WHEN c.column_default IS NULL THEN ''
+			WHEN c.data_type IN ('char', 'varchar', 'tinytext', 'text', 'mediumtext', 'longtext', 'enum', 'set')
+				AND c.extra NOT LIKE '%DEFAULT_GENERATED%' THEN QUOTE(c.column_default)
+			ELSE c.column_default
+		END,
+		COALESCE(c.character_maximum_length, 0),
+		COALESCE(c.numeric_precision, 0),
+		COALESCE(c.numeric_scale, 0),
+		c.column_comment,
+		CASE WHEN c.column_key = 'UNI' THEN 1 ELSE 0 END,
+		c.column_type,
+		COALESCE((
+			SELECT GROUP_CONCAT(cc.check_clause SEPARATOR ' ')
+			FROM information_schema.table_constraints tc
+			JOIN information_schema.check_constraints cc
+				ON cc.constraint_schema = tc.constraint_schema
+				AND cc.constraint_name = tc.constraint_name
+			WHERE tc.table_schema = c.table_schema AND tc.table_name = c.table_name
+				AND tc.constraint_type = 'CHECK'
+		), ''),
+		CASE WHEN c.extra LIKE '%auto_increment%' THEN 1 ELSE 0 END
+	FROM information_schema.columns c
+	WHERE c.table_schema = DATABASE();
+	`,
+	"sqlite": `
+	SELECT m.name, p.name, COALESCE(p.dflt_value, ''), 0, 0, 0, '',
+		CASE WHEN EXISTS (
+			SELECT 1 FROM pragma_index_list(m.name) il
+			WHERE il."unique" = 1 AND il.origin != 'pk' AND il.partial = 0
+				AND (SELECT COUNT(*) FROM pragma_index_info(il.name)) = 1
+				AND (SELECT ii.name FROM pragma_index_info(il.name) ii) = p.name
+		) THEN 1 ELSE 0 END,
+		p.type, COALESCE(m.sql, ''), 0
+	FROM sqlite_master m
+	JOIN pragma_table_info(m.name) p
+	WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%';
+	`,
+}
+
+// columnMetadata is the column information missing from the upstream introspector
+type columnMetadata struct {
+	Default    string
+	MaxLength  int
+	Precision  int
+	Scale      int
+	IsUnique   bool
+	Comment    string
+	TypeDef    string
+	EnumValues []string
+	// IsEnumType is set when EnumValues come from the enum type of the column
+	// rather than from a CHECK constraint
+	IsEnumType    bool
+	AutoIncrement bool
+}
+
+// loadColumnMetadata returns the metadata of every column, by table and column
+// name. Drivers without a known query yield an empty map.
+func loadColumnMetadata(ctx context.Context, db database.Database) (map[string]map[string]columnMetadata, error) {
+	metadata := make(map[string]map[string]columnMetadata)
+
+	q, ok := columnMetadataQueries[db.DriverName()]
+	if !ok {
+		return metadata, nil
+	}
+
+	rows, err := db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var table, column, typeDef, checks string
+		var unique, autoIncrement int
+		var m columnMetadata
+		if err := rows.Scan(&table, &column, &m.Default, &m.MaxLength, &m.Precision, &m.Scale,
+			&m.Comment, &unique, &typeDef, &checks, &autoIncrement); err != nil {
+			return nil, err
+		}
+		m.IsUnique = unique == 1
+		m.AutoIncrement = autoIncrement == 1 || parseAutoIncrement(column, checks)
+		m.TypeDef = typeDef
+
+		// SQLite only reports the declared type, like VARCHAR(255) or DECIMAL(10,2)
+		if m.MaxLength == 0 && m.Precision == 0 {
+			m.MaxLength, m.Precision, m.Scale = parseTypeModifiers(typeDef)
+		}
+		m.EnumValues = parseEnumType(typeDef)
+		m.IsEnumType = m.EnumValues != nil
+		if m.EnumValues == nil {
+			m.EnumValues = parseCheckEnum(column, checks)
+		}
+
+		if metadata[table] == nil {
+			metadata[table] = make(map[string]columnMetadata)
+		}
+		metadata[table][column] = m
+	}
+	return metadata, rows.Err()
+}
+
+var (
+	typeModifiersPattern = regexp.MustCompile(`(?i)^\s*(\w[\w ]*?)\s*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+	enumTypePattern      = regexp.MustCompile(`(?is)^\s*enum\s*\((.*)\)\s*$`)
+	sqlStringPattern     = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// parseTypeModifiers reads the length of character types, like VARCHAR(255),
+// and the precision and scale of numeric types, like DECIMAL(10,2)
+func parseTypeModifiers(typeDef string) (maxLength, precision, scale int) {
+	match := typeModifiersPattern.FindStringSubmatch(typeDef)
+	if match == nil {
+		return 0, 0, 0
+	}
+
+	first, _ := strconv.Atoi(match[2])
+	second, _ := strconv.Atoi(match[3])
+	switch base := strings.ToLower(match[1]); {
+	case strings.Contains(base, "char"):
+		return first, 0, 0
+	case base == "decimal" || base == "numeric":
+		return 0, first, second
+	}
+	return 0, 0, 0
+}
+
+// parseEnumType returns the values of an enum('a','b') type definition
+func parseEnumType(typeDef string) []string {
+	match := enumTypePattern.FindStringSubmatch(typeDef)
+	if match == nil {
+		return nil
+	}
+	return sqlStrings(match[1])
+}
+
+// parseCheckEnum returns the values a CHECK constraint restricts a column to,
+// from col IN ('a', 'b') or, as reported by PostgreSQL,
+// col = ANY (ARRAY['a'::text, 'b'::text])
+func parseCheckEnum(column, checks string) []string {
+	if checks == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(`(?i)(?:^|[^\w])` + regexp.QuoteMeta(column) +
+		"[\"`\\])]*(?:::[\\w ]+\\)*)?\\s*(?:in\\s*\\(|=\\s*any\\s*\\(+\\s*array\\s*\\[)([^\\])]*)")
+	if err != nil {
+		return nil
+	}
+	match := pattern.FindStringSubmatch(checks)
+	if match == nil {
+		return nil
+	}
+	return sqlStrings(match[1])
+}
+
+// parseAutoIncrement reports whether a SQLite CREATE TABLE statement declares
+// the column INTEGER PRIMARY KEY AUTOINCREMENT
+func parseAutoIncrement(column, createTable string) bool {
+	if createTable == "" {
+		return false
+	}
+	pattern, err := regexp.Compile("(?is)(?:^|[(,])\\s*[\"`\\[]?" + regexp.QuoteMeta(column) +
+		"[\"`\\]]?\\s+integer\\b[^,]*\\bautoincrement\\b")
+	if err != nil {
+		return false
+	}
+	return pattern.MatchString(createTable)
+}
+
+// sqlStrings returns the single quoted string literals of s, unescaped
+func sqlStrings(s string) []string {
+	var values []string
+	for _, match := range sqlStringPattern.FindAllStringSubmatch(s, -1) {
+		values = append(values, strings.ReplaceAll(match[1], "''", "'"))
+	}
+	return values
+}
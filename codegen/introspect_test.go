@@ -0,0 +1,111 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTypeModifiers(t *testing.T) {
+	tests := []struct {
+		typeDef                     string
+		maxLength, precision, scale int
+	}{
+		{typeDef: "VARCHAR(255)", maxLength: 255},
+		{typeDef: "character varying(40)", maxLength: 40},
+		{typeDef: "DECIMAL(10,2)", precision: 10, scale: 2},
+		{typeDef: "numeric(8)", precision: 8},
+		{typeDef: "INTEGER"},
+		{typeDef: "int(11)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeDef, func(t *testing.T) {
+			maxLength, precision, scale := parseTypeModifiers(tt.typeDef)
+			if maxLength != tt.maxLength || precision != tt.precision || scale != tt.scale {
+				t.Errorf("Expected (%d, %d, %d), got (%d, %d, %d)",
+					tt.maxLength, tt.precision, tt.scale, maxLength, precision, scale)
+			}
+		})
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		column   string
+		typeDef  string
+		checks   string
+		expected []string
+	}{
+		{
+			name:     "enum type",
+			column:   "status",
+			typeDef:  "enum('draft','it''s live')",
+			expected: []string{"draft", "it's live"},
+		},
+		{
+			name:     "postgres check",
+			column:   "status",
+			typeDef:  "character varying",
+			checks:   "CHECK (((status)::text = ANY ((ARRAY['draft'::character varying, 'published'::character varying])::text[])))",
+			expected: []string{"draft", "published"},
+		},
+		{
+			name:     "mysql check",
+			column:   "status",
+			typeDef:  "varchar(20)",
+			checks:   "(`status` in (_utf8mb4'draft',_utf8mb4'published'))",
+			expected: []string{"draft", "published"},
+		},
+		{
+			name:     "sqlite table definition",
+			column:   "status",
+			typeDef:  "TEXT",
+			checks:   "CREATE TABLE posts (id INTEGER, user_status TEXT CHECK (user_status IN ('x')), status TEXT CHECK (status IN ('draft', 'published')))",
+			expected: []string{"draft", "published"},
+		},
+		{
+			name:    "unrelated check",
+			column:  "age",
+			typeDef: "integer",
+			checks:  "CHECK ((age >= 0))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := parseEnumType(tt.typeDef)
+			if values == nil {
+				values = parseCheckEnum(tt.column, tt.checks)
+			}
+			if strings.Join(values, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestParseAutoIncrement(t *testing.T) {
+	createTable := `CREATE TABLE users (
+	"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	team_id INTEGER,
+	name TEXT CHECK (name IN ('a', 'b'))
+)`
+	tests := []struct {
+		column   string
+		sql      string
+		expected bool
+	}{
+		{column: "id", sql: createTable, expected: true},
+		{column: "team_id", sql: createTable},
+		{column: "name", sql: createTable},
+		{column: "id", sql: "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"},
+		{column: "id", sql: "CHECK ((id > 0))"},
+	}
+
+	for _, tt := range tests {
+		if got := parseAutoIncrement(tt.column, tt.sql); got != tt.expected {
+			t.Errorf("Expected %v for %s in %q, got %v", tt.expected, tt.column, tt.sql, got)
+		}
+	}
+}
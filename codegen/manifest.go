@@ -0,0 +1,206 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestFile is the default name of the manifest, kept in the parent of the
+// models directory
+const ManifestFile = ".codegen-manifest.json"
+
+const manifestVersion = 1
+
+// Manifest records the files produced by previous generation runs, so that the
+// outputs of dropped tables can be removed
+type Manifest struct {
+	Version int `json:"version"`
+	// Files is keyed by paths relative to the manifest directory
+	Files map[string]ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes a generated file
+type ManifestEntry struct {
+	// Hash is the hash of the generated content
+	Hash string `json:"hash"`
+	// Schema is the hash of the table schema a model was generated from
+	Schema string `json:"schema,omitempty"`
+}
+
+// contentHash returns the hex encoded SHA-256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// schemaHash returns the hash of a table schema
+func schemaHash(table TableSchema) (string, error) {
+	data, err := json.Marshal(table)
+	if err != nil {
+		return "", err
+	}
+	return contentHash(data), nil
+}
+
+// manifestPath returns the path of the manifest: the configured one, or
+// ManifestFile next to the models directory
+func (g *Generator) manifestPath() (string, error) {
+	if g.Options != nil && g.Options.Manifest != "" {
+		return g.outputDir(g.Options.Manifest)
+	}
+	modelsDir, err := g.modelsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(modelsDir), ManifestFile), nil
+}
+
+// loadManifest reads the manifest once per generator. A missing manifest yields
+// an empty one.
+func (g *Generator) loadManifest() (*Manifest, error) {
+	if g.manifest != nil {
+		return g.manifest, nil
+	}
+
+	path, err := g.manifestPath()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{Version: manifestVersion, Files: make(map[string]ManifestEntry)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, &ParseError{Path: path, Err: err}
+	default:
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		if manifest.Files == nil {
+			manifest.Files = make(map[string]ManifestEntry)
+		}
+	}
+
+	g.manifest = manifest
+	return manifest, nil
+}
+
+// syncManifest removes the files recorded in the manifest under dirs which were
+// not generated during a run over every table, records the files generated
+// under dirs and, unless in dry-run mode, saves the manifest. Recorded files modified since
+// they were generated are kept. A ConflictError lists the files under dirs left
+// untouched because they were modified.
+func (g *Generator) syncManifest(dirs ...string) error {
+	manifest, err := g.loadManifest()
+	if err != nil {
+		return err
+	}
+	path, err := g.manifestPath()
+	if err != nil {
+		return err
+	}
+	root := filepath.Dir(path)
+
+	inDirs := func(file string) bool {
+		for _, dir := range dirs {
+			if filepath.Dir(file) == filepath.Clean(dir) {
+				return true
+			}
+		}
+		return false
+	}
+
+	generated := make(map[string]bool)
+	var conflicts []string
+	for path := range g.conflicts {
+		if inDirs(path) {
+			generated[path] = true
+			conflicts = append(conflicts, path)
+		}
+	}
+	sort.Strings(conflicts)
+	for _, change := range g.Output.Changes() {
+		if change.Kind != ChangeRemoved {
+			generated[change.Path] = true
+		}
+	}
+
+	// Files of the tables left out of a selection are kept
+	var recorded []string
+	if len(g.Tables) == 0 {
+		for rel := range manifest.Files {
+			recorded = append(recorded, rel)
+		}
+		sort.Strings(recorded)
+	}
+	for _, rel := range recorded {
+		file := rel
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(root, filepath.FromSlash(rel))
+		}
+		if !inDirs(file) || generated[file] {
+			continue
+		}
+		entry := manifest.Files[rel]
+		delete(manifest.Files, rel)
+
+		current, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return &ParseError{Path: file, Err: err}
+		}
+		if contentHash(current) != entry.Hash {
+			log.Printf("⚠️  Keeping %s: modified since it was generated", file)
+			continue
+		}
+		if err := g.Output.RemoveFile(file); err != nil {
+			return err
+		}
+		if !g.Output.DryRun {
+			log.Printf("🗑️  Removed %s", file)
+		}
+	}
+
+	for _, change := range g.Output.Changes() {
+		if change.Kind == ChangeRemoved || !inDirs(change.Path) {
+			continue
+		}
+		manifest.Files[relativePath(root, change.Path)] = ManifestEntry{
+			Hash:   contentHash(change.Content),
+			Schema: g.schemaHashes[change.Path],
+		}
+	}
+
+	if !g.Output.DryRun {
+		if err := writeManifest(path, manifest); err != nil {
+			return err
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Paths: conflicts}
+	}
+	return nil
+}
+
+func writeManifest(path string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return &WriteError{Path: path, Err: err}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &WriteError{Path: filepath.Dir(path), Err: err}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return &WriteError{Path: path, Err: err}
+	}
+	return nil
+}
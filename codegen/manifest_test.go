@@ -0,0 +1,137 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func newManifestTestGenerator(t *testing.T, dir string, dryRun bool) *Generator {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	return NewGenerator(cfg, NewOutput(dryRun))
+}
+
+func manifestTestTables() map[string]TableSchema {
+	return map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{{Name: "id", Type: "integer"}, {Name: "email", Type: "text"}}},
+		"posts": {TableName: "posts", Columns: []Column{{Name: "id", Type: "integer"}, {Name: "title", Type: "text"}}},
+	}
+}
+
+func readTestManifest(t *testing.T, dir string) Manifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	return manifest
+}
+
+func TestGenerateStructsManifest(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	manifest := readTestManifest(t, dir)
+	for _, name := range []string{"models/user.go", "models/post.go"} {
+		entry, ok := manifest.Files[name]
+		if !ok {
+			t.Fatalf("Expected manifest entry for %s, got %v", name, manifest.Files)
+		}
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if entry.Hash != contentHash(src) {
+			t.Errorf("Expected %s hash to match its content", name)
+		}
+		if entry.Schema == "" {
+			t.Errorf("Expected %s to record its schema hash", name)
+		}
+	}
+
+	// Dropping a table removes its model, unchanged models are left alone
+	delete(tables, "posts")
+	g := newManifestTestGenerator(t, dir, false)
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to regenerate models: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "post.go")); !os.IsNotExist(err) {
+		t.Error("Expected the model of a dropped table to be removed")
+	}
+	if got := g.Output.Paths(dir, ChangeRemoved); !reflect.DeepEqual(got, []string{"models/post.go"}) {
+		t.Errorf("Expected removed [models/post.go], got %v", got)
+	}
+	if got := g.Output.Paths(dir, ChangeUnchanged); !reflect.DeepEqual(got, []string{"models/user.go"}) {
+		t.Errorf("Expected unchanged [models/user.go], got %v", got)
+	}
+	if _, ok := readTestManifest(t, dir).Files["models/post.go"]; ok {
+		t.Error("Expected the removed model to leave the manifest")
+	}
+}
+
+func TestSyncManifestKeepsModifiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	postPath := filepath.Join(dir, "models", "post.go")
+	if err := os.WriteFile(postPath, []byte("package models\n\n// edited by hand\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit model: %v", err)
+	}
+
+	delete(tables, "posts")
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to regenerate models: %v", err)
+	}
+	if _, err := os.Stat(postPath); err != nil {
+		t.Errorf("Expected a model modified by hand to be kept: %v", err)
+	}
+}
+
+func TestSyncManifestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+	before := readTestManifest(t, dir)
+
+	delete(tables, "posts")
+	g := newManifestTestGenerator(t, dir, true)
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to regenerate models: %v", err)
+	}
+
+	if got := g.Output.Paths(dir, ChangeRemoved); !reflect.DeepEqual(got, []string{"models/post.go"}) {
+		t.Errorf("Expected removed [models/post.go], got %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "post.go")); err != nil {
+		t.Errorf("Expected dry run not to remove files: %v", err)
+	}
+	if !reflect.DeepEqual(readTestManifest(t, dir), before) {
+		t.Error("Expected dry run not to update the manifest")
+	}
+	names, err := g.Output.ReadDir(filepath.Join(dir, "models"))
+	if err != nil {
+		t.Fatalf("Failed to read models directory: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"user.go"}) {
+		t.Errorf("Expected removed files to be hidden from later steps, got %v", names)
+	}
+}
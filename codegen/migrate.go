@@ -0,0 +1,985 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MigrationsDir is where codegen migrate writes migrations when no migrations
+// directory is configured, relative to the project root
+const MigrationsDir = "migrations"
+
+// migrationVersionDigits is the width of the version of the first migration
+// of a directory, like 000001_create_users.up.sql
+const migrationVersionDigits = 6
+
+// migrationColumn is the definition of a column in a migration
+type migrationColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+	// Unique and Check are the UNIQUE and CHECK (column IN (...)) constraints
+	// of a column of the schema, kept when a SQLite table is rebuilt
+	Unique bool
+	Check  string
+	// AutoIncrement is set on the integer primary key of a table, rendered as
+	// a serial type, an AUTO_INCREMENT attribute or, for SQLite columns
+	// declared so, AUTOINCREMENT
+	AutoIncrement bool
+}
+
+// migrationTable is the definition of a table in a migration, built from a
+// model or from the schema
+type migrationTable struct {
+	Name        string
+	Columns     []migrationColumn
+	PrimaryKey  []string
+	ForeignKeys []Relation
+	// Indexes holds the CREATE INDEX statements of a SQLite table of the
+	// schema
+	Indexes []string
+}
+
+func (t *migrationTable) column(name string) (migrationColumn, bool) {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return migrationColumn{}, false
+}
+
+// hasColumns reports whether the table has every column named, empty names
+// standing for expressions
+func (t *migrationTable) hasColumns(names []string) bool {
+	for _, name := range names {
+		if _, ok := t.column(name); name != "" && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parents returns the tables the table references, other than itself
+func (t *migrationTable) parents() []string {
+	var parents []string
+	for _, fk := range t.ForeignKeys {
+		if fk.ParentTable != t.Name && !slices.Contains(parents, fk.ParentTable) {
+			parents = append(parents, fk.ParentTable)
+		}
+	}
+	return parents
+}
+
+// migrationStep is a change of the schema, with the statements applying and
+// reverting it
+type migrationStep struct {
+	Up   []string
+	Down []string
+}
+
+// modelTable is a model struct read from the models directory, with the
+// fields mapped to a column
+type modelTable struct {
+	Struct string
+	Table  string
+	Path   string
+	Fields []StructField
+}
+
+// GenerateMigration compares the models with tables, the current schema, and
+// writes the up and down migrations taking the schema to the models, named
+// after name. Nothing is written when they match. A schema read from
+// migrations applied in SQLite only takes SQLite migrations, which it can
+// replay, so the configured database must be a SQLite one.
+func (g *Generator) GenerateMigration(tables map[string]TableSchema, name string) error {
+	if g.targetDialect != "" && g.targetDialect != DialectSQLite {
+		return &ConfigError{Op: "generate migration", Err: fmt.Errorf(
+			"the schema is read from migrations applied in SQLite, which cannot replay %s migrations: read it from the database, schema files or a snapshot instead",
+			g.targetDialect)}
+	}
+	models, err := g.loadModelTables()
+	if err != nil {
+		return err
+	}
+	steps, err := g.migrationSteps(models, tables)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Println("✅ Schema matches the models, no migration needed")
+		return nil
+	}
+
+	dir, err := g.migrationsDir()
+	if err != nil {
+		return err
+	}
+	base, err := g.nextMigrationName(dir, name)
+	if err != nil {
+		return err
+	}
+
+	up := make([]string, len(steps))
+	down := make([]string, len(steps))
+	for i, step := range steps {
+		up[i] = strings.Join(step.Up, "\n")
+		// Changes are reverted in reverse order
+		down[len(steps)-1-i] = strings.Join(step.Down, "\n")
+	}
+	files := []struct {
+		suffix     string
+		statements []string
+	}{
+		{".up.sql", up},
+		{".down.sql", down},
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, base+file.suffix)
+		if err := g.Output.WriteFile(path, []byte(strings.Join(file.statements, "\n\n")+"\n")); err != nil {
+			return err
+		}
+		if !g.Output.DryRun {
+			fmt.Printf("✅ Generated migration → %s\n", path)
+		}
+	}
+	return nil
+}
+
+// migrationsDir returns the directory migrations are written to: the first
+// configured migrations directory, or MigrationsDir
+func (g *Generator) migrationsDir() (string, error) {
+	dir := MigrationsDir
+	if g.Options != nil && len(g.Options.Migrations) > 0 {
+		dir = g.Options.Migrations[0]
+	}
+	return g.outputDir(dir)
+}
+
+var migrationNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// nextMigrationName returns the file name, without its suffix, of the next
+// migration of dir: the version following the highest one, padded like it,
+// and the snake case name
+func (g *Generator) nextMigrationName(dir, name string) (string, error) {
+	entries, err := g.Output.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", &ParseError{Path: dir, Err: err}
+	}
+	var last uint64
+	digits := migrationVersionDigits
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry, ".sql") {
+			continue
+		}
+		if version, ok := migrationVersion(entry); ok && version >= last {
+			last = version
+			digits = len(entry) - len(strings.TrimLeft(entry, "0123456789"))
+		}
+	}
+
+	name = strings.Trim(migrationNameInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "schema"
+	}
+	return fmt.Sprintf("%0*d_%s", digits, last+1, name), nil
+}
+
+// loadModelTables reads the model structs of the models directory, keyed by
+// table. Structs without a db field, like relation helpers, are skipped.
+func (g *Generator) loadModelTables() (map[string]modelTable, error) {
+	modelsDir, err := g.modelsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := g.Output.ReadDir(modelsDir)
+	if err != nil {
+		return nil, &ParseError{Path: modelsDir, Err: err}
+	}
+
+	models := make(map[string]modelTable)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		path := filepath.Join(modelsDir, file)
+		src, err := g.Output.ReadFile(path)
+		if err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		structs, err := parseStructsSource(path, src)
+		if err != nil {
+			return nil, err
+		}
+		tables, err := extractTableNamesSource(path, src)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range structs {
+			table := tables[s]
+			if !g.selected(table) {
+				continue
+			}
+			fields, err := extractStructFieldsSource(path, src, s)
+			if err != nil {
+				return nil, err
+			}
+			var columns []StructField
+			for _, field := range fields {
+				if field.DBTag != "" && field.DBTag != "-" {
+					columns = append(columns, field)
+				}
+			}
+			if len(columns) == 0 {
+				continue
+			}
+			if other, ok := models[table]; ok {
+				return nil, &ParseError{Path: path, Err: fmt.Errorf("models %s and %s both map to table %s", other.Struct, s, table)}
+			}
+			models[table] = modelTable{Struct: s, Table: table, Path: path, Fields: columns}
+		}
+	}
+	return models, nil
+}
+
+// migrationSteps returns the changes taking the schema of tables to the
+// models: created tables, parents first, altered tables, then, with
+// DropTables, dropped tables, children first. Tables excluded or left out of
+// the selection are ignored.
+func (g *Generator) migrationSteps(models map[string]modelTable, tables map[string]TableSchema) ([]migrationStep, error) {
+	current := g.modelSchemaTables(tables)
+
+	var created, dropped []*migrationTable
+	var steps []migrationStep
+	for _, name := range sortedKeys(models) {
+		model := models[name]
+		target, err := g.modelMigrationTable(model)
+		if err != nil {
+			return nil, err
+		}
+		table, ok := current[name]
+		if !ok {
+			created = append(created, target)
+			continue
+		}
+
+		from := g.schemaMigrationTable(table)
+		to, err := g.alteredMigrationTable(model, table, from, target)
+		if err != nil {
+			return nil, err
+		}
+		up := g.alterTableSQL(from, to)
+		if len(up) > 0 {
+			steps = append(steps, migrationStep{Up: up, Down: g.alterTableSQL(to, from)})
+		}
+	}
+	var kept []string
+	for _, name := range sortedKeys(current) {
+		if _, ok := models[name]; ok {
+			continue
+		}
+		if !g.DropTables {
+			kept = append(kept, name)
+			continue
+		}
+		dropped = append(dropped, g.schemaMigrationTable(current[name]))
+	}
+	if len(kept) > 0 {
+		log.Printf("⚠️  Keeping %d table(s) without a model, use --drop-tables to drop them: %s", len(kept), strings.Join(kept, ", "))
+	}
+
+	var createSteps []migrationStep
+	for _, table := range sortByParents(created) {
+		createSteps = append(createSteps, migrationStep{Up: g.createTableSQL(table), Down: []string{g.dropTableSQL(table)}})
+	}
+	parentsFirst := sortByParents(dropped)
+	for i := len(parentsFirst) - 1; i >= 0; i-- {
+		table := parentsFirst[i]
+		steps = append(steps, migrationStep{Up: []string{g.dropTableSQL(table)}, Down: g.createTableSQL(table)})
+	}
+	return append(createSteps, steps...), nil
+}
+
+// modelSchemaTables returns the tables of the schema compared with the models,
+// the included and selected ones, and maps enum columns to the enum types
+// generated for the models
+func (g *Generator) modelSchemaTables(tables map[string]TableSchema) map[string]TableSchema {
+	opts := g.tableOptions()
+	filtered := opts.filter(tables)
+	current := make(map[string]TableSchema)
+	for name, table := range filtered {
+		if g.selected(name) {
+			current[name] = table
+		}
+	}
+	g.enumTypes = enumTypeNames(filtered, opts)
+	return current
+}
+
+// modelMigrationTable returns the definition of the table of a model, its
+// columns being nullable when their field is a pointer, a nullable type, a
+// slice or a map
+func (g *Generator) modelMigrationTable(model modelTable) (*migrationTable, error) {
+	table := &migrationTable{Name: model.Table}
+	for _, field := range model.Fields {
+		if field.IsPrimaryKey {
+			table.PrimaryKey = append(table.PrimaryKey, field.DBTag)
+		}
+	}
+
+	for _, field := range model.Fields {
+		base, nullable := splitNullableGoType(fieldGoTypeString(field))
+		sqlType, err := g.migrationSQLType(base, field.ValidateTag)
+		if err != nil {
+			return nil, &ParseError{Path: model.Path, Err: fmt.Errorf("field %s.%s: %w", model.Struct, field.Name, err)}
+		}
+		isKey := slices.Contains(table.PrimaryKey, field.DBTag)
+		table.Columns = append(table.Columns, migrationColumn{
+			Name: field.DBTag,
+			Type: sqlType,
+			// Nil slices and maps are stored as nulls
+			NotNull: isKey || !(nullable || holdsNil(base)),
+			// SQLite numbers INTEGER PRIMARY KEY columns without AUTOINCREMENT
+			AutoIncrement: isKey && len(table.PrimaryKey) == 1 && isIntegerGoType(base) && g.migrationDialect() != DialectSQLite,
+		})
+
+		if parent, column, ok := strings.Cut(field.ForeignKey, "."); ok {
+			table.ForeignKeys = append(table.ForeignKeys, Relation{
+				ChildTable:   model.Table,
+				ChildColumn:  field.DBTag,
+				ParentTable:  parent,
+				ParentColumn: column,
+			})
+		}
+	}
+	return table, nil
+}
+
+// alteredMigrationTable returns the definition of an existing table once
+// altered to match its model. Columns whose field has the type the model
+// generator would give them are kept as they are, with their default, and
+// the type or nullability of the others is taken from the model.
+func (g *Generator) alteredMigrationTable(model modelTable, table TableSchema, from, target *migrationTable) (*migrationTable, error) {
+	columns := make(map[string]Column, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[col.Name] = col
+	}
+
+	to := &migrationTable{Name: from.Name, PrimaryKey: from.PrimaryKey, ForeignKeys: from.ForeignKeys, Indexes: from.Indexes}
+	for i, field := range model.Fields {
+		col, ok := columns[field.DBTag]
+		if !ok {
+			to.Columns = append(to.Columns, target.Columns[i])
+			for _, fk := range target.ForeignKeys {
+				if fk.ChildColumn == field.DBTag {
+					to.ForeignKeys = append(to.ForeignKeys, fk)
+				}
+			}
+			continue
+		}
+
+		current, _ := from.column(col.Name)
+		expected, _, err := g.fieldGoType(table.TableName, col)
+		if err != nil {
+			return nil, err
+		}
+		fieldType := fieldGoTypeString(field)
+		if expected != fieldType {
+			expectedBase, _ := splitNullableGoType(expected)
+			base, nullable := splitNullableGoType(fieldType)
+			if base != expectedBase {
+				current.Type = target.Columns[i].Type
+			}
+			// Nil slices and maps cannot tell a nullable column apart
+			if !holdsNil(base) {
+				current.NotNull = slices.Contains(from.PrimaryKey, col.Name) || !nullable
+			}
+		}
+		to.Columns = append(to.Columns, current)
+	}
+
+	// Foreign keys of dropped columns go with them
+	to.ForeignKeys = slices.DeleteFunc(slices.Clone(to.ForeignKeys), func(fk Relation) bool {
+		_, ok := to.column(fk.ChildColumn)
+		return !ok
+	})
+	return to, nil
+}
+
+// schemaMigrationTable returns the definition of a table of the schema
+func (g *Generator) schemaMigrationTable(table TableSchema) *migrationTable {
+	t := &migrationTable{Name: table.TableName, PrimaryKey: table.PrimaryKey, ForeignKeys: table.Relations, Indexes: table.Indexes}
+	for _, col := range table.Columns {
+		c := migrationColumn{
+			Name:    col.Name,
+			Type:    g.schemaSQLType(col),
+			NotNull: !col.IsNullable,
+			Default: col.Default,
+			// Unique indexes are recreated along with the table
+			Unique:        col.IsUnique && !hasUniqueIndex(table.Indexes, col.Name),
+			Check:         g.checkSQL(col),
+			AutoIncrement: col.AutoIncrement,
+		}
+		// Sequences of serial columns are created along with them
+		if g.migrationDialect() == DialectPostgres && strings.HasPrefix(col.Default, "nextval(") {
+			if _, ok := postgresIntegerSerials[c.Type]; ok {
+				c.Default, c.AutoIncrement = "", true
+			}
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	return t
+}
+
+// checkSQL returns the CHECK constraint restricting a column of the schema to
+// its EnumValues, empty for columns of an enum type
+func (g *Generator) checkSQL(col Column) string {
+	if len(col.EnumValues) == 0 || col.EnumType != "" || parseEnumType(col.TypeDef) != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s IN (%s)", g.quoteIdentifier(col.Name), quoteSQLStrings(col.EnumValues))
+}
+
+// migrationDialect returns the dialect migrations are written in
+func (g *Generator) migrationDialect() string {
+	if _, ok := goTypeMappers[g.Dialect]; ok {
+		return g.Dialect
+	}
+	return DialectPostgres
+}
+
+// postgresIntegerSerials maps PostgreSQL integer types to the serial type
+// auto-incrementing them, the reverse of postgresSerialTypes
+var postgresIntegerSerials = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
+
+// schemaSQLType returns the SQL type of a column of the schema, as it would be
+// declared
+func (g *Generator) schemaSQLType(col Column) string {
+	switch g.migrationDialect() {
+	case DialectMySQL:
+		if col.TypeDef != "" {
+			return col.TypeDef
+		}
+		return col.Type
+	case DialectSQLite:
+		return col.Type
+	}
+
+	switch {
+	case col.EnumType != "":
+		return col.EnumType
+	case col.Type == "ARRAY" && strings.HasSuffix(col.TypeDef, "[]"):
+		return col.TypeDef
+	case col.MaxLength > 0 && (col.Type == "character varying" || col.Type == "character"):
+		return fmt.Sprintf("%s(%d)", col.Type, col.MaxLength)
+	case col.Precision > 0 && col.Type == "numeric":
+		return fmt.Sprintf("numeric(%d,%d)", col.Precision, col.Scale)
+	}
+	return col.Type
+}
+
+// migrationSQLTypes map the Go types of model fields to SQL types, by dialect
+var migrationSQLTypes = map[string]map[string]string{
+	DialectPostgres: {
+		"int":                    "integer",
+		"int8":                   "smallint",
+		"int16":                  "smallint",
+		"int32":                  "integer",
+		"int64":                  "bigint",
+		"uint":                   "bigint",
+		"uint8":                  "smallint",
+		"uint16":                 "integer",
+		"uint32":                 "bigint",
+		"uint64":                 "numeric(20)",
+		"float32":                "real",
+		"float64":                "double precision",
+		"bool":                   "boolean",
+		"string":                 "text",
+		"time.Time":              "timestamp with time zone",
+		"[]byte":                 "bytea",
+		"map[string]interface{}": "jsonb",
+		"map[string]any":         "jsonb",
+		"json.RawMessage":        "jsonb",
+		"uuid.UUID":              "uuid",
+	},
+	DialectMySQL: {
+		"int":                    "int",
+		"int8":                   "tinyint",
+		"int16":                  "smallint",
+		"int32":                  "int",
+		"int64":                  "bigint",
+		"uint":                   "int unsigned",
+		"uint8":                  "tinyint unsigned",
+		"uint16":                 "smallint unsigned",
+		"uint32":                 "int unsigned",
+		"uint64":                 "bigint unsigned",
+		"float32":                "float",
+		"float64":                "double",
+		"bool":                   "tinyint(1)",
+		"string":                 "text",
+		"time.Time":              "datetime",
+		"[]byte":                 "blob",
+		"map[string]interface{}": "json",
+		"map[string]any":         "json",
+		"json.RawMessage":        "json",
+		"uuid.UUID":              "char(36)",
+	},
+	DialectSQLite: {
+		"int":                    "INTEGER",
+		"int8":                   "INTEGER",
+		"int16":                  "INTEGER",
+		"int32":                  "INTEGER",
+		"int64":                  "INTEGER",
+		"uint":                   "INTEGER",
+		"uint8":                  "INTEGER",
+		"uint16":                 "INTEGER",
+		"uint32":                 "INTEGER",
+		"uint64":                 "INTEGER",
+		"float32":                "REAL",
+		"float64":                "REAL",
+		"bool":                   "BOOLEAN",
+		"string":                 "TEXT",
+		"time.Time":              "DATETIME",
+		"[]byte":                 "BLOB",
+		"map[string]interface{}": "JSON",
+		"map[string]any":         "JSON",
+		"json.RawMessage":        "JSON",
+		"uuid.UUID":              "TEXT",
+	},
+}
+
+var maxRulePattern = regexp.MustCompile(`(?:^|,)max=(\d+)(?:,|$)`)
+
+// migrationSQLType returns the SQL type of a model field of type goType, not
+// nullable: the database type the configured types map to it, the enum type
+// it was generated for, or the dialect default. Strings with a max rule are
+// bounded.
+func (g *Generator) migrationSQLType(goType, validateTag string) (string, error) {
+	if g.Options != nil {
+		for _, dbType := range sortedKeys(g.Options.Types) {
+			if mapped, _, err := parseGoType(g.Options.Types[dbType]); err == nil && mapped == goType {
+				return dbType, nil
+			}
+		}
+	}
+	for _, enumType := range sortedKeys(g.enumTypes) {
+		if g.enumTypes[enumType] == goType {
+			return enumType, nil
+		}
+	}
+
+	dialect := g.migrationDialect()
+	if goType == "string" {
+		if m := maxRulePattern.FindStringSubmatch(validateTag); m != nil {
+			if dialect == DialectSQLite {
+				return "VARCHAR(" + m[1] + ")", nil
+			}
+			return "varchar(" + m[1] + ")", nil
+		}
+	}
+	if sqlType, ok := migrationSQLTypes[dialect][goType]; ok {
+		return sqlType, nil
+	}
+	// Only PostgreSQL has array columns
+	if elem, ok := strings.CutPrefix(goType, "[]"); ok && dialect == DialectPostgres {
+		if elemType, ok := migrationSQLTypes[dialect][elem]; ok {
+			return elemType + "[]", nil
+		}
+	}
+	return "", fmt.Errorf("no %s type for Go type %s, map one in codegen.types", dialect, goType)
+}
+
+// fieldGoTypeString returns the Go type of a model field as declared
+func fieldGoTypeString(field StructField) string {
+	if field.IsPointer {
+		return "*" + field.Type
+	}
+	return field.Type
+}
+
+// splitNullableGoType returns the type of the value of a model field type and
+// whether it holds nulls, as a pointer or a nullable type
+func splitNullableGoType(goType string) (string, bool) {
+	if elem, ok := strings.CutPrefix(goType, "*"); ok {
+		return elem, true
+	}
+	if elem, ok := unwrapNullable(goType); ok {
+		return elem, true
+	}
+	return goType, false
+}
+
+func isIntegerGoType(goType string) bool {
+	return strings.HasPrefix(goType, "int") || strings.HasPrefix(goType, "uint")
+}
+
+// quoteIdentifier quotes a table or column name for the dialect
+func (g *Generator) quoteIdentifier(name string) string {
+	if g.migrationDialect() == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (g *Generator) quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = g.quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// columnSQL renders a column definition
+func (g *Generator) columnSQL(col migrationColumn) string {
+	dialect := g.migrationDialect()
+	colType := col.Type
+	if col.AutoIncrement && dialect == DialectPostgres {
+		if serial, ok := postgresIntegerSerials[colType]; ok {
+			colType = serial
+		}
+	}
+
+	def := g.quoteIdentifier(col.Name) + " " + colType
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if col.Unique {
+		def += " UNIQUE"
+	}
+	if col.Check != "" {
+		def += " CHECK (" + col.Check + ")"
+	}
+	if col.AutoIncrement {
+		switch dialect {
+		case DialectMySQL:
+			def += " AUTO_INCREMENT"
+		case DialectSQLite:
+			def += " PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+	return def
+}
+
+func (g *Generator) foreignKeySQL(fk Relation) string {
+	return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+		g.quoteIdentifier(fk.ChildColumn), g.quoteIdentifier(fk.ParentTable), g.quoteIdentifier(fk.ParentColumn))
+}
+
+// createTableSQL returns the statement creating a table under its name
+func (g *Generator) createTableSQL(table *migrationTable) []string {
+	return []string{g.createTableAsSQL(table, table.Name)}
+}
+
+func (g *Generator) createTableAsSQL(table *migrationTable, name string) string {
+	var defs []string
+	for _, col := range table.Columns {
+		defs = append(defs, g.columnSQL(col))
+	}
+	// SQLite AUTOINCREMENT columns declare the key themselves
+	autoIncrement := slices.ContainsFunc(table.Columns, func(col migrationColumn) bool { return col.AutoIncrement })
+	if len(table.PrimaryKey) > 0 && !(autoIncrement && g.migrationDialect() == DialectSQLite) {
+		defs = append(defs, "PRIMARY KEY ("+g.quoteIdentifiers(table.PrimaryKey)+")")
+	}
+	for _, fk := range table.ForeignKeys {
+		defs = append(defs, g.foreignKeySQL(fk))
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n\t%s\n);", g.quoteIdentifier(name), strings.Join(defs, ",\n\t"))
+}
+
+func (g *Generator) dropTableSQL(table *migrationTable) string {
+	return fmt.Sprintf("DROP TABLE %s;", g.quoteIdentifier(table.Name))
+}
+
+// alterTableSQL returns the statements taking a table from one definition to
+// another, adding, dropping and altering its columns. SQLite tables are
+// rebuilt when a column cannot be added or dropped in place.
+func (g *Generator) alterTableSQL(from, to *migrationTable) []string {
+	var added, dropped []migrationColumn
+	altered := false
+	for _, col := range to.Columns {
+		current, ok := from.column(col.Name)
+		switch {
+		case !ok:
+			added = append(added, col)
+		case current != col:
+			altered = true
+		}
+	}
+	for _, col := range from.Columns {
+		if _, ok := to.column(col.Name); !ok {
+			dropped = append(dropped, col)
+		}
+	}
+	if len(added) == 0 && len(dropped) == 0 && !altered {
+		return nil
+	}
+
+	table := g.quoteIdentifier(to.Name)
+	switch g.migrationDialect() {
+	case DialectSQLite:
+		// Only nullable or defaulted columns without a UNIQUE constraint can be
+		// added in place
+		rebuild := altered || len(dropped) > 0
+		for _, col := range added {
+			rebuild = rebuild || (col.NotNull && col.Default == "") || col.Unique
+		}
+		if rebuild {
+			return g.rebuildTableSQL(from, to)
+		}
+	}
+
+	var statements []string
+	for _, col := range added {
+		// MySQL fills NOT NULL columns added without a default with the
+		// implicit default of their type, PostgreSQL needs a value
+		placeholder := ""
+		if g.migrationDialect() == DialectPostgres {
+			var ok bool
+			if placeholder, ok = to.placeholder(col); !ok {
+				statements = append(statements, g.placeholderTODO(col))
+			}
+		}
+
+		def := g.columnSQL(col)
+		if placeholder != "" {
+			def += " DEFAULT " + placeholder
+		}
+		for _, fk := range to.ForeignKeys {
+			if fk.ChildColumn == col.Name && g.migrationDialect() != DialectMySQL {
+				def += fmt.Sprintf(" REFERENCES %s (%s)", g.quoteIdentifier(fk.ParentTable), g.quoteIdentifier(fk.ParentColumn))
+			}
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, def))
+		if placeholder != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, g.quoteIdentifier(col.Name)))
+		}
+		for _, fk := range to.ForeignKeys {
+			if fk.ChildColumn == col.Name && g.migrationDialect() == DialectMySQL {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s;", table, g.foreignKeySQL(fk)))
+			}
+		}
+	}
+	// Indexes of the columns added back are recreated with them
+	for _, index := range to.Indexes {
+		if columns, _ := parseIndex(index); to.hasColumns(columns) && !from.hasColumns(columns) {
+			statements = append(statements, index+";")
+		}
+	}
+	for _, col := range dropped {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, g.quoteIdentifier(col.Name)))
+	}
+
+	for _, col := range to.Columns {
+		current, ok := from.column(col.Name)
+		if !ok || current == col {
+			continue
+		}
+		if g.migrationDialect() == DialectMySQL {
+			// The UNIQUE and CHECK constraints of the column are left in place
+			modified := col
+			modified.Unique, modified.Check = false, ""
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", table, g.columnSQL(modified)))
+			continue
+		}
+
+		alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", table, g.quoteIdentifier(col.Name))
+		if current.Type != col.Type {
+			statements = append(statements, fmt.Sprintf("%s TYPE %s USING %s::%s;", alter, col.Type, g.quoteIdentifier(col.Name), col.Type))
+		}
+		if current.NotNull != col.NotNull {
+			if col.NotNull {
+				statements = append(statements, alter+" SET NOT NULL;")
+			} else {
+				statements = append(statements, alter+" DROP NOT NULL;")
+			}
+		}
+		if current.Default != col.Default {
+			if col.Default != "" {
+				statements = append(statements, alter+" SET DEFAULT "+col.Default+";")
+			} else {
+				statements = append(statements, alter+" DROP DEFAULT;")
+			}
+		}
+	}
+	return statements
+}
+
+// rebuildTableSQL returns the statements recreating a SQLite table with a new
+// definition, copying the rows of the columns kept, giving the NOT NULL
+// columns added without a default a placeholder value, and recreating the
+// indexes whose columns are kept
+func (g *Generator) rebuildTableSQL(from, to *migrationTable) []string {
+	rebuilt := to.Name + "_new"
+	statements := []string{
+		fmt.Sprintf("-- %s is rebuilt: CHECK constraints other than column IN (...) lists and triggers are not carried over", g.quoteIdentifier(to.Name)),
+		g.createTableAsSQL(to, rebuilt),
+	}
+
+	var columns, values []string
+	for _, col := range to.Columns {
+		if _, ok := from.column(col.Name); ok {
+			columns = append(columns, g.quoteIdentifier(col.Name))
+			values = append(values, g.quoteIdentifier(col.Name))
+			continue
+		}
+		placeholder, ok := to.placeholder(col)
+		if !ok {
+			statements = append(statements, g.placeholderTODO(col))
+		}
+		if placeholder != "" {
+			columns = append(columns, g.quoteIdentifier(col.Name))
+			values = append(values, placeholder)
+		}
+	}
+	statements = append(statements,
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", g.quoteIdentifier(rebuilt), strings.Join(columns, ", "), strings.Join(values, ", "), g.quoteIdentifier(to.Name)),
+		fmt.Sprintf("DROP TABLE %s;", g.quoteIdentifier(to.Name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", g.quoteIdentifier(rebuilt), g.quoteIdentifier(to.Name)),
+	)
+	for _, index := range to.Indexes {
+		if columns, _ := parseIndex(index); to.hasColumns(columns) {
+			statements = append(statements, index+";")
+		}
+	}
+	return statements
+}
+
+// placeholder returns the value the existing rows get in a column added to
+// the table: none for columns that are nullable, defaulted or numbered by the
+// database, the zero value of the type of the other ones, and false when
+// there is no safe one, like for foreign keys or enum types
+func (t *migrationTable) placeholder(col migrationColumn) (string, bool) {
+	if !col.NotNull || col.Default != "" || col.AutoIncrement {
+		return "", true
+	}
+	if slices.ContainsFunc(t.ForeignKeys, func(fk Relation) bool { return fk.ChildColumn == col.Name }) {
+		return "", false
+	}
+
+	sqlType := strings.ToLower(col.Type)
+	if strings.HasSuffix(sqlType, "[]") {
+		return "'{}'", true
+	}
+	// The base type, like varchar of varchar(120) or double of double precision
+	base, _, _ := strings.Cut(sqlType, "(")
+	if fields := strings.Fields(base); len(fields) > 0 {
+		base = fields[0]
+	}
+	switch base {
+	case "bool", "boolean":
+		return "false", true
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "int2", "int4", "int8",
+		"numeric", "decimal", "real", "double", "float", "float4", "float8":
+		return "0", true
+	case "char", "character", "varchar", "nchar", "nvarchar", "text", "clob", "citext":
+		return "''", true
+	case "timestamp", "timestamptz", "datetime":
+		return "CURRENT_TIMESTAMP", true
+	case "date":
+		return "CURRENT_DATE", true
+	case "json", "jsonb":
+		return "'{}'", true
+	case "bytea":
+		return "''", true
+	case "blob":
+		return "X''", true
+	}
+	return "", false
+}
+
+// placeholderTODO returns the comment flagging a NOT NULL column added without
+// a value for the existing rows
+func (g *Generator) placeholderTODO(col migrationColumn) string {
+	return fmt.Sprintf("-- TODO: %s is NOT NULL without a default, give the existing rows a value or this fails on a table with rows", g.quoteIdentifier(col.Name))
+}
+
+// parseIndex reads a CREATE INDEX statement, returning its columns, empty
+// names standing for expressions, and whether it is unique on every row
+func parseIndex(stmt string) (columns []string, unique bool) {
+	tokens, err := lexDDL(stmt)
+	if err != nil {
+		return nil, false
+	}
+	p := &ddlParser{schema: newDDLSchema(DialectSQLite), src: stmt, tokens: tokens}
+	unique = p.acceptKeywords("create", "unique")
+	for !p.done() && !p.acceptKeywords("on") {
+		p.pos++
+	}
+	if _, _, err := p.qualifiedName(); err != nil {
+		return nil, false
+	}
+	if columns, err = p.identifierList(); err != nil {
+		return nil, false
+	}
+	for ; !p.done(); p.pos++ {
+		unique = unique && !p.isKeyword(0, "where")
+	}
+	return columns, unique
+}
+
+// hasUniqueIndex reports whether one of the CREATE INDEX statements makes the
+// column unique on its own
+func hasUniqueIndex(indexes []string, column string) bool {
+	for _, index := range indexes {
+		if columns, unique := parseIndex(index); unique && slices.Equal(columns, []string{column}) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByParents orders tables so that referenced tables come before the
+// tables referencing them, in the given order otherwise. Tables of a cycle
+// keep the given order.
+func sortByParents(tables []*migrationTable) []*migrationTable {
+	byName := make(map[string]*migrationTable, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	var sorted []*migrationTable
+	visited := make(map[string]bool, len(tables))
+	var visit func(table *migrationTable)
+	visit = func(table *migrationTable) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+		for _, parent := range table.parents() {
+			if p, ok := byName[parent]; ok {
+				visit(p)
+			}
+		}
+		sorted = append(sorted, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return sorted
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,465 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+// newMigrateGenerator returns a generator whose models directory holds the
+// given model files
+func newMigrateGenerator(t *testing.T, dialect string, models map[string]string) *Generator {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	if err := os.MkdirAll(cfg.Codegen.Output.Models, 0755); err != nil {
+		t.Fatalf("Failed to create models directory: %v", err)
+	}
+	for name, src := range models {
+		if err := os.WriteFile(filepath.Join(cfg.Codegen.Output.Models, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	g := NewGenerator(cfg, nil)
+	g.Dialect = dialect
+	g.Options.Migrations = []string{filepath.Join(dir, "migrations")}
+	return g
+}
+
+func readMigration(t *testing.T, g *Generator, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(g.Options.Migrations[0], name))
+	if err != nil {
+		t.Fatalf("Failed to read migration: %v", err)
+	}
+	return string(data)
+}
+
+const migrateUserModel = "package models\n\n" +
+	"type User struct {\n" +
+	"\tId    int     `json:\"id,omitempty\" db:\"id\" pk:\"true\"`\n" +
+	"\tEmail *string `json:\"email\" db:\"email\" validate:\"omitempty,max=255\"`\n" +
+	"\tAge   int64   `json:\"age\" db:\"age\"`\n" +
+	"\tBio   *string `json:\"bio,omitempty\" db:\"bio\"`\n" +
+	"\n" +
+	"\tPosts []Post `json:\"posts,omitempty\" rel:\"user_id\"`\n" +
+	"}\n\n" +
+	"func (User) TableName() string {\n\treturn \"users\"\n}\n"
+
+const migratePostModel = "package models\n\n" +
+	"import \"time\"\n\n" +
+	"type Post struct {\n" +
+	"\tId        int        `json:\"id,omitempty\" db:\"id\" pk:\"true\"`\n" +
+	"\tUserId    int        `json:\"userId\" db:\"user_id\" fk:\"users.id\"`\n" +
+	"\tTitle     string     `json:\"title\" db:\"title\" validate:\"required,max=120\"`\n" +
+	"\tTags      []string   `json:\"tags,omitempty\" db:\"tags\"`\n" +
+	"\tCreatedAt *time.Time `json:\"createdAt,omitempty\" db:\"created_at\"`\n" +
+	"}\n"
+
+func TestGenerateMigrationPostgres(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE users (
+	id serial PRIMARY KEY,
+	email varchar(255) NOT NULL,
+	age integer NOT NULL DEFAULT 0,
+	legacy text
+);
+CREATE TABLE tags (id serial PRIMARY KEY, user_id integer REFERENCES users);
+`)
+	g := newMigrateGenerator(t, DialectPostgres, map[string]string{
+		"user.go": migrateUserModel,
+		"post.go": migratePostModel,
+	})
+	g.DropTables = true
+
+	if err := g.GenerateMigration(tables, "Add posts!"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+
+	wantUp := `CREATE TABLE "posts" (
+	"id" serial NOT NULL,
+	"user_id" integer NOT NULL,
+	"title" varchar(120) NOT NULL,
+	"tags" text[],
+	"created_at" timestamp with time zone,
+	PRIMARY KEY ("id"),
+	FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+ALTER TABLE "users" ADD COLUMN "bio" text;
+ALTER TABLE "users" DROP COLUMN "legacy";
+ALTER TABLE "users" ALTER COLUMN "email" DROP NOT NULL;
+ALTER TABLE "users" ALTER COLUMN "age" TYPE bigint USING "age"::bigint;
+
+DROP TABLE "tags";
+`
+	if up := readMigration(t, g, "000001_add_posts.up.sql"); up != wantUp {
+		t.Errorf("Expected up migration\n%s\ngot\n%s", wantUp, up)
+	}
+
+	wantDown := `CREATE TABLE "tags" (
+	"id" serial NOT NULL,
+	"user_id" integer,
+	PRIMARY KEY ("id"),
+	FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+ALTER TABLE "users" ADD COLUMN "legacy" text;
+ALTER TABLE "users" DROP COLUMN "bio";
+ALTER TABLE "users" ALTER COLUMN "email" SET NOT NULL;
+ALTER TABLE "users" ALTER COLUMN "age" TYPE integer USING "age"::integer;
+
+DROP TABLE "posts";
+`
+	if down := readMigration(t, g, "000001_add_posts.down.sql"); down != wantDown {
+		t.Errorf("Expected down migration\n%s\ngot\n%s", wantDown, down)
+	}
+}
+
+// TestGenerateMigrationKeepsTables checks that tables without a model, like
+// the bookkeeping tables of migration tools, are only dropped with DropTables.
+func TestGenerateMigrationKeepsTables(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, `
+CREATE TABLE users (id serial PRIMARY KEY, email varchar(255) NOT NULL, created_at timestamptz NOT NULL DEFAULT now());
+CREATE TABLE schema_migrations (version bigint PRIMARY KEY, dirty boolean NOT NULL);
+`)
+	g := newMigrateGenerator(t, DialectPostgres, nil)
+	if err := g.GenerateStructs(map[string]TableSchema{"users": tables["users"]}); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	if err := g.GenerateMigration(tables, "keep"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	if _, err := os.Stat(g.Options.Migrations[0]); !os.IsNotExist(err) {
+		t.Errorf("Expected no migration without DropTables, got %v", err)
+	}
+
+	g.DropTables = true
+	if err := g.GenerateMigration(tables, "drop"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	if up := readMigration(t, g, "000001_drop.up.sql"); up != "DROP TABLE \"schema_migrations\";\n" {
+		t.Errorf("Expected schema_migrations to be dropped with DropTables, got\n%s", up)
+	}
+}
+
+func TestGenerateMigrationMySQL(t *testing.T) {
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", PrimaryKey: []string{"id"}, Columns: []Column{
+			{Name: "id", Type: "int", TypeDef: "int unsigned", IsPrimaryKey: true},
+			{Name: "email", Type: "varchar", TypeDef: "varchar(255)", MaxLength: 255, IsNullable: true},
+		}},
+	}
+	model := "package models\n\ntype User struct {\n" +
+		"\tId    uint   `db:\"id\" pk:\"true\"`\n" +
+		"\tEmail string `db:\"email\" validate:\"required,max=255\"`\n" +
+		"\tTeamId *int  `db:\"team_id\" fk:\"teams.id\"`\n" +
+		"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n"
+	g := newMigrateGenerator(t, DialectMySQL, map[string]string{"user.go": model})
+
+	if err := g.GenerateMigration(tables, "teams"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	wantUp := "ALTER TABLE `users` ADD COLUMN `team_id` int;\n" +
+		"ALTER TABLE `users` ADD FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`);\n" +
+		"ALTER TABLE `users` MODIFY COLUMN `email` varchar(255) NOT NULL;\n"
+	if up := readMigration(t, g, "000001_teams.up.sql"); up != wantUp {
+		t.Errorf("Expected up migration\n%s\ngot\n%s", wantUp, up)
+	}
+}
+
+// TestGenerateMigrationSQLite applies the generated migrations to check that
+// the up migration takes the schema to the models and the down migration
+// back to where it was
+func TestGenerateMigrationSQLite(t *testing.T) {
+	g := newMigrateGenerator(t, DialectSQLite, map[string]string{
+		"user.go": "package models\n\ntype User struct {\n" +
+			"\tId    int64   `db:\"id\" pk:\"true\"`\n" +
+			"\tEmail string  `db:\"email\" validate:\"required,max=255\"`\n" +
+			"\tName  *string `db:\"name\"`\n" +
+			"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+		"post.go": "package models\n\ntype Post struct {\n" +
+			"\tId     int64  `db:\"id\" pk:\"true\"`\n" +
+			"\tUserId int64  `db:\"user_id\" fk:\"users.id\"`\n" +
+			"}\n",
+	})
+	dir := g.Options.Migrations[0]
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create migrations directory: %v", err)
+	}
+	initial := "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, legacy TEXT DEFAULT 'x');\nINSERT INTO users (email) VALUES ('a@example.com');"
+	if err := os.WriteFile(filepath.Join(dir, "000001_users.up.sql"), []byte(initial), 0644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+
+	before, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if err := g.GenerateMigration(before, "posts"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	up := readMigration(t, g, "000002_posts.up.sql")
+	if !strings.Contains(up, `CREATE TABLE "users_new"`) || !strings.Contains(up, `INSERT INTO "users_new" ("id", "email") SELECT "id", "email" FROM "users";`) {
+		t.Errorf("Expected users to be rebuilt, got:\n%s", up)
+	}
+
+	// Once applied, the schema matches the models
+	after, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to apply the up migration: %v", err)
+	}
+	steps, err := g.migrationSteps(mustLoadModelTables(t, g), after)
+	if err != nil {
+		t.Fatalf("Failed to compare the schema: %v", err)
+	}
+	if len(steps) != 0 {
+		t.Errorf("Expected no change once migrated, got %+v", steps)
+	}
+
+	// Applying the down migration after it gives the schema back
+	down := readMigration(t, g, "000002_posts.down.sql")
+	if err := os.WriteFile(filepath.Join(dir, "000003_revert.up.sql"), []byte(down), 0644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+	reverted, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to apply the down migration: %v", err)
+	}
+	if !reflect.DeepEqual(reverted, before) {
+		t.Errorf("Expected the down migration to restore\n%+v\ngot\n%+v", before, reverted)
+	}
+}
+
+// TestGenerateMigrationFromMigrations checks that migrations generated from
+// a schema read from the migrations directory replay in SQLite, and that
+// other databases are refused
+func TestGenerateMigrationFromMigrations(t *testing.T) {
+	g := newMigrateGenerator(t, DialectSQLite, map[string]string{
+		"user.go": "package models\n\ntype User struct {\n" +
+			"\tId    int64   `db:\"id\" pk:\"true\"`\n" +
+			"\tEmail string  `db:\"email\"`\n" +
+			"\tBio   *string `db:\"bio\"`\n" +
+			"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+		"comment.go": "package models\n\nimport \"time\"\n\ntype Comment struct {\n" +
+			"\tId        int64     `db:\"id\" pk:\"true\"`\n" +
+			"\tUserId    int64     `db:\"user_id\" fk:\"users.id\"`\n" +
+			"\tBody      string    `db:\"body\"`\n" +
+			"\tCreatedAt *time.Time `db:\"created_at\"`\n" +
+			"}\n",
+	})
+	dir := g.Options.Migrations[0]
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create migrations directory: %v", err)
+	}
+	initial := "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, legacy TEXT);\nINSERT INTO users (email) VALUES ('a@example.com');"
+	if err := os.WriteFile(filepath.Join(dir, "000001_users.up.sql"), []byte(initial), 0644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+
+	tables, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if err := g.GenerateMigration(tables, "comments"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+
+	// The migration is replayed with the others and the models match
+	after, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to replay the generated migration: %v", err)
+	}
+	if err := g.CheckSchema(after); err != nil {
+		t.Errorf("Expected no drift once migrated, got %v", err)
+	}
+
+	// A PostgreSQL project cannot replay PostgreSQL migrations in SQLite
+	g.Dialect = DialectPostgres
+	tables, err = g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	err = g.GenerateMigration(tables, "again")
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || !strings.Contains(err.Error(), "cannot replay postgres migrations") {
+		t.Errorf("Expected a ConfigError for postgres migrations, got %v", err)
+	}
+}
+
+func TestGenerateMigrationNotNullColumns(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, "CREATE TABLE users (id serial PRIMARY KEY, email text NOT NULL);")
+	g := newMigrateGenerator(t, DialectPostgres, map[string]string{
+		"user.go": "package models\n\nimport \"time\"\n\ntype User struct {\n" +
+			"\tId        int       `db:\"id\" pk:\"true\"`\n" +
+			"\tEmail     string    `db:\"email\"`\n" +
+			"\tAge       int64     `db:\"age\"`\n" +
+			"\tJoinedAt  time.Time `db:\"joined_at\"`\n" +
+			"\tTeamId    int       `db:\"team_id\" fk:\"teams.id\"`\n" +
+			"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+	})
+
+	if err := g.GenerateMigration(tables, "profile"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	// Existing rows get a placeholder, foreign keys have none
+	wantUp := `ALTER TABLE "users" ADD COLUMN "age" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ALTER COLUMN "age" DROP DEFAULT;
+ALTER TABLE "users" ADD COLUMN "joined_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE "users" ALTER COLUMN "joined_at" DROP DEFAULT;
+-- TODO: "team_id" is NOT NULL without a default, give the existing rows a value or this fails on a table with rows
+ALTER TABLE "users" ADD COLUMN "team_id" integer NOT NULL REFERENCES "teams" ("id");
+`
+	if up := readMigration(t, g, "000001_profile.up.sql"); up != wantUp {
+		t.Errorf("Expected up migration\n%s\ngot\n%s", wantUp, up)
+	}
+}
+
+// TestGenerateMigrationSQLiteRebuild checks that a SQLite table rebuilt to add
+// a NOT NULL column keeps its rows, its AUTOINCREMENT key, its UNIQUE and
+// CHECK constraints and its indexes
+func TestGenerateMigrationSQLiteRebuild(t *testing.T) {
+	g := newMigrateGenerator(t, DialectSQLite, map[string]string{
+		"user.go": "package models\n\ntype User struct {\n" +
+			"\tId     int64   `db:\"id\" pk:\"true\"`\n" +
+			"\tEmail  string  `db:\"email\"`\n" +
+			"\tStatus string  `db:\"status\"`\n" +
+			"\tName   *string `db:\"name\"`\n" +
+			"\tAge    int64   `db:\"age\"`\n" +
+			"}\n\nfunc (User) TableName() string {\n\treturn \"users\"\n}\n",
+	})
+	dir := g.Options.Migrations[0]
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create migrations directory: %v", err)
+	}
+	initial := `CREATE TABLE users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT NOT NULL UNIQUE,
+	status TEXT NOT NULL CHECK (status IN ('active', 'banned')),
+	name TEXT,
+	legacy TEXT
+);
+CREATE INDEX idx_users_name ON users (name);
+CREATE INDEX idx_users_legacy ON users (legacy, name);
+CREATE UNIQUE INDEX idx_users_email ON users (lower(email));
+INSERT INTO users (email, status) VALUES ('a@example.com', 'active');`
+	if err := os.WriteFile(filepath.Join(dir, "000001_users.up.sql"), []byte(initial), 0644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+
+	before, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if err := g.GenerateMigration(before, "age"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	up := readMigration(t, g, "000002_age.up.sql")
+	if !strings.Contains(up, `INSERT INTO "users_new" ("id", "email", "status", "name", "age") SELECT "id", "email", "status", "name", 0 FROM "users";`) {
+		t.Errorf("Expected existing rows to get a placeholder age, got:\n%s", up)
+	}
+
+	after, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to apply the up migration: %v", err)
+	}
+	users := after["users"]
+	if id := users.Columns[0]; !id.AutoIncrement {
+		t.Errorf("Expected id to keep AUTOINCREMENT, got %+v", id)
+	}
+	if email := users.Columns[1]; !email.IsUnique {
+		t.Errorf("Expected email to stay unique, got %+v", email)
+	}
+	if status := users.Columns[2]; !reflect.DeepEqual(status.EnumValues, []string{"active", "banned"}) {
+		t.Errorf("Expected status to keep its CHECK constraint, got %+v", status)
+	}
+	wantIndexes := []string{
+		"CREATE UNIQUE INDEX idx_users_email ON users (lower(email))",
+		"CREATE INDEX idx_users_name ON users (name)",
+	}
+	if !reflect.DeepEqual(users.Indexes, wantIndexes) {
+		t.Errorf("Expected indexes\n%q\ngot\n%q", wantIndexes, users.Indexes)
+	}
+	steps, err := g.migrationSteps(mustLoadModelTables(t, g), after)
+	if err != nil {
+		t.Fatalf("Failed to compare the schema: %v", err)
+	}
+	if len(steps) != 0 {
+		t.Errorf("Expected no change once migrated, got %+v", steps)
+	}
+
+	down := readMigration(t, g, "000002_age.down.sql")
+	if err := os.WriteFile(filepath.Join(dir, "000003_revert.up.sql"), []byte(down), 0644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+	reverted, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to apply the down migration: %v", err)
+	}
+	if !reflect.DeepEqual(reverted, before) {
+		t.Errorf("Expected the down migration to restore\n%+v\ngot\n%+v", before, reverted)
+	}
+}
+
+func mustLoadModelTables(t *testing.T, g *Generator) map[string]modelTable {
+	t.Helper()
+	models, err := g.loadModelTables()
+	if err != nil {
+		t.Fatalf("Failed to load models: %v", err)
+	}
+	return models
+}
+
+func TestGenerateMigrationUpToDate(t *testing.T) {
+	tables := parseDDL(t, DialectPostgres, "CREATE TABLE users (id serial PRIMARY KEY, email varchar(255) NOT NULL, created_at timestamptz NOT NULL DEFAULT now());")
+	g := newMigrateGenerator(t, DialectPostgres, nil)
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	if err := g.GenerateMigration(tables, "noop"); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	if _, err := os.Stat(g.Options.Migrations[0]); !os.IsNotExist(err) {
+		t.Errorf("Expected no migration for models generated from the schema, got %v", err)
+	}
+}
+
+func TestNextMigrationName(t *testing.T) {
+	tests := []struct {
+		files    []string
+		name     string
+		expected string
+	}{
+		{nil, "Create users", "000001_create_users"},
+		{[]string{"0009_a.up.sql", "0010_b.up.sql", "0010_b.down.sql", "README.md"}, "add-posts", "0011_add_posts"},
+		{[]string{"20240101120000_init.up.sql"}, "", "20240101120001_schema"},
+		{[]string{"1_init.up.sql", "2_more.up.sql"}, "x", "3_x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", file, err)
+				}
+			}
+			g := NewGenerator(&config.Config{}, nil)
+			name, err := g.nextMigrationName(dir, tt.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if name != tt.expected {
+				t.Errorf("nextMigrationName(%v, %q) = %s; want %s", tt.files, tt.name, name, tt.expected)
+			}
+		})
+	}
+}
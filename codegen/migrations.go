@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/nicolasbonnici/gorest/database"
+	// Migrations are applied to an in-memory SQLite database
+	_ "github.com/nicolasbonnici/gorest/database/sqlite"
+)
+
+// MigrationPattern matches the up migrations of a migrations directory, named
+// like 0001_create_users.up.sql
+const MigrationPattern = "*.up.sql"
+
+// migrationDatabases numbers the in-memory databases, each load getting its own
+var migrationDatabases atomic.Int64
+
+// LoadMigrationSchema applies the up migrations of dirs, in order, to an empty
+// in-memory SQLite database and returns its schema. Columns are reported in
+// the SQLite dialect.
+func LoadMigrationSchema(dirs ...string) (map[string]TableSchema, error) {
+	paths, err := resolveMigrations(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A shared cache keeps the database the same on every pooled connection
+	dsn := fmt.Sprintf("file:codegen-migrations-%d-%d?mode=memory&cache=shared", os.Getpid(), migrationDatabases.Add(1))
+	db, err := database.Open(DialectSQLite, dsn)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx := context.Background()
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		if _, err := db.Exec(ctx, string(src)); err != nil {
+			return nil, &ParseError{Path: path, Err: fmt.Errorf("apply migration: %w", err)}
+		}
+	}
+	return LoadSchema(db)
+}
+
+// resolveMigrations returns the up migrations of dirs, directory by directory
+// and in version order within a directory
+func resolveMigrations(dirs []string) ([]string, error) {
+	var paths []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, MigrationPattern))
+		if err != nil {
+			return nil, &ConfigError{Op: "resolve migrations", Err: err}
+		}
+		if len(matches) == 0 {
+			return nil, &ConfigError{Op: "resolve migrations", Err: fmt.Errorf("no %s file in %s: %w", MigrationPattern, dir, os.ErrNotExist)}
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			return migrationLess(filepath.Base(matches[i]), filepath.Base(matches[j]))
+		})
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// migrationLess orders migrations by their leading version number, so that
+// 2_posts.up.sql comes before 10_tags.up.sql, and by name otherwise
+func migrationLess(a, b string) bool {
+	va, okA := migrationVersion(a)
+	vb, okB := migrationVersion(b)
+	if okA && okB && va != vb {
+		return va < vb
+	}
+	return a < b
+}
+
+// migrationVersion returns the number a migration file name starts with
+func migrationVersion(name string) (uint64, bool) {
+	end := 0
+	for end < len(name) && name[end] >= '0' && name[end] <= '9' {
+		end++
+	}
+	version, err := strconv.ParseUint(name[:end], 10, 64)
+	return version, err == nil
+}
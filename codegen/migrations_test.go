@@ -0,0 +1,135 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMigrationSchema(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"1_users.up.sql":     "CREATE TABLE users (id INTEGER PRIMARY KEY, email VARCHAR(255) NOT NULL UNIQUE);",
+		"1_users.down.sql":   "DROP TABLE users;",
+		"2_posts.up.sql":     "CREATE TABLE posts (\n\tid INTEGER PRIMARY KEY,\n\tuser_id INTEGER NOT NULL REFERENCES users(id)\n);\nCREATE TABLE drafts (id INTEGER);",
+		"10_status.up.sql":   "ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published'));\nDROP TABLE drafts;",
+		"README.md":          "Applied by the deploy pipeline",
+		"10_status.down.sql": "ALTER TABLE posts DROP COLUMN status;",
+	})
+
+	tables, err := LoadMigrationSchema(dir)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	var names []string
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"posts", "users"}) {
+		t.Fatalf("Expected posts and users, got %v", names)
+	}
+
+	email := tables["users"].Columns[1]
+	if email.Type != "VARCHAR(255)" || email.MaxLength != 255 || !email.IsUnique || email.IsNullable {
+		t.Errorf("Expected a unique VARCHAR(255) email, got %+v", email)
+	}
+	status := tables["posts"].Columns[2]
+	if status.Default != "'draft'" || !reflect.DeepEqual(status.EnumValues, []string{"draft", "published"}) {
+		t.Errorf("Expected the status added by the last migration, got %+v", status)
+	}
+	want := []Relation{{ChildTable: "posts", ChildColumn: "user_id", ParentTable: "users", ParentColumn: "id"}}
+	if !reflect.DeepEqual(tables["posts"].Relations, want) {
+		t.Errorf("Expected relations %+v, got %+v", want, tables["posts"].Relations)
+	}
+
+	// Every load gets an empty database
+	other := writeMigrations(t, map[string]string{"1_tags.up.sql": "CREATE TABLE tags (id INTEGER PRIMARY KEY);"})
+	tables, err = LoadMigrationSchema(other)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Errorf("Expected only the tags table, got %+v", tables)
+	}
+}
+
+func TestLoadMigrationSchemaErrors(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"1_users.up.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY);",
+		"2_posts.up.sql": "ALTER TABLE missing ADD COLUMN title TEXT;",
+	})
+	_, err := LoadMigrationSchema(dir)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || filepath.Base(parseErr.Path) != "2_posts.up.sql" {
+		t.Errorf("Expected ParseError for 2_posts.up.sql, got %v", err)
+	}
+
+	_, err = LoadMigrationSchema(t.TempDir())
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected ConfigError for a directory without migrations, got %v", err)
+	}
+}
+
+func TestMigrationLess(t *testing.T) {
+	names := []string{"10_tags.up.sql", "b.up.sql", "2_posts.up.sql", "001_users.up.sql", "a.up.sql", "2_comments.up.sql"}
+	sort.Slice(names, func(i, j int) bool {
+		return migrationLess(names[i], names[j])
+	})
+	want := []string{"001_users.up.sql", "2_comments.up.sql", "2_posts.up.sql", "10_tags.up.sql", "a.up.sql", "b.up.sql"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestGenerateFromMigrations(t *testing.T) {
+	migrations := writeMigrations(t, map[string]string{
+		"1_users.up.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY, active BOOLEAN NOT NULL, created_at DATETIME);",
+	})
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	g := NewGenerator(cfg, nil)
+	g.Dialect = DialectPostgres
+	g.Options.Migrations = []string{migrations}
+
+	tables, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if g.Dialect != DialectSQLite {
+		t.Errorf("Expected migrations to be read in the SQLite dialect, got %s", g.Dialect)
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	model, err := os.ReadFile(filepath.Join(dir, "models", "user.go"))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	code := strings.Join(strings.Fields(string(model)), " ")
+	// INTEGER PRIMARY KEY is reported nullable by SQLite, the key is not
+	for _, want := range []string{"Id int64 `json:\"id,omitempty\" db:\"id\" pk:\"true\"`", "Active bool", "CreatedAt *time.Time"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected model to contain %q, got:\n%s", want, model)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package codegen
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"maps"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/nicolasbonnici/gorest/database"
 	"golang.org/x/text/cases"
@@ -17,12 +20,58 @@ type TableSchema struct {
 	TableName string
 	Columns   []Column
 	Relations []Relation
+	// PrimaryKey lists the primary key columns in key order
+	PrimaryKey []string
+	// Indexes holds the statements creating the indexes of the table, like
+	// CREATE INDEX idx_users_email ON users (email), other than those of its
+	// keys and UNIQUE constraints. Only read for SQLite, whose tables are
+	// recreated along with them when migrations alter a column.
+	Indexes []string
 }
 
 type Column struct {
-	Name       string
-	Type       string
-	IsNullable bool
+	Name         string
+	Type         string
+	IsNullable   bool
+	IsPrimaryKey bool
+	// Default is the SQL expression of the column default, like 'draft' or
+	// now(), empty when the column has none
+	Default string
+	// MaxLength is the maximum length of character columns, Precision and
+	// Scale those of numeric columns, zero when unknown
+	MaxLength int
+	Precision int
+	Scale     int
+	// TypeDef is the full type definition when the dialect reports more than
+	// Type, like int unsigned or tinyint(1) in MySQL
+	TypeDef string
+	// IsUnique is set when a single column unique constraint or index exists
+	IsUnique bool
+	Comment  string
+	// EnumValues lists the values allowed by an enum type or by a CHECK
+	// constraint such as status IN ('draft', 'published')
+	EnumValues []string
+	// EnumType is the name of the PostgreSQL enum type of the column, like
+	// mood, whose labels are EnumValues
+	EnumType string
+	// AutoIncrement is set on integer keys declared AUTOINCREMENT in SQLite
+	// or AUTO_INCREMENT in MySQL. PostgreSQL serial columns have a nextval()
+	// Default instead.
+	AutoIncrement bool
+}
+
+// KeyColumns returns the primary key columns of the table, falling back to an
+// "id" column when no primary key was introspected
+func (t TableSchema) KeyColumns() []string {
+	if len(t.PrimaryKey) > 0 {
+		return t.PrimaryKey
+	}
+	for _, col := range t.Columns {
+		if col.Name == FieldID {
+			return []string{FieldID}
+		}
+	}
+	return nil
 }
 
 type Relation struct {
@@ -32,20 +81,58 @@ type Relation struct {
 	ParentColumn string
 }
 
-func LoadSchema(db database.Database) map[string]TableSchema {
-	schemaSlice, err := db.Introspector().LoadSchema(context.Background())
+func LoadSchema(db database.Database) (map[string]TableSchema, error) {
+	if db == nil {
+		return nil, &SchemaError{Err: errors.New("no database connection")}
+	}
+
+	ctx := context.Background()
+	schemaSlice, err := db.Introspector().LoadSchema(ctx)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
+	}
+
+	primaryKeys, err := loadPrimaryKeys(ctx, db)
 	if err != nil {
-		log.Fatalf("Failed to load schema: %v", err)
+		return nil, &SchemaError{Err: err}
+	}
+	metadata, err := loadColumnMetadata(ctx, db)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
+	}
+	indexes, err := loadIndexes(ctx, db)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
 	}
 
 	tables := make(map[string]TableSchema)
 	for _, t := range schemaSlice {
+		primaryKey := primaryKeys[t.TableName]
+
 		columns := make([]Column, len(t.Columns))
 		for i, c := range t.Columns {
+			m := metadata[t.TableName][c.Name]
+			isKey := slices.Contains(primaryKey, c.Name)
 			columns[i] = Column{
-				Name:       c.Name,
-				Type:       c.Type,
-				IsNullable: c.IsNullable,
+				Name: c.Name,
+				Type: c.Type,
+				// SQLite reports primary keys as nullable unless declared
+				// NOT NULL, INTEGER PRIMARY KEY included
+				IsNullable:    c.IsNullable && !isKey,
+				IsPrimaryKey:  isKey,
+				Default:       m.Default,
+				MaxLength:     m.MaxLength,
+				Precision:     m.Precision,
+				Scale:         m.Scale,
+				TypeDef:       m.TypeDef,
+				IsUnique:      m.IsUnique,
+				Comment:       m.Comment,
+				EnumValues:    m.EnumValues,
+				AutoIncrement: m.AutoIncrement,
+			}
+			// MySQL enums are declared inline on each column
+			if m.IsEnumType && db.DriverName() == DialectPostgres {
+				columns[i].EnumType = c.Type
 			}
 		}
 
@@ -60,32 +147,50 @@ func LoadSchema(db database.Database) map[string]TableSchema {
 		}
 
 		tables[t.TableName] = TableSchema{
-			TableName: t.TableName,
-			Columns:   columns,
-			Relations: relations,
+			TableName:  t.TableName,
+			Columns:    columns,
+			Relations:  relations,
+			PrimaryKey: primaryKey,
+			Indexes:    indexes[t.TableName],
 		}
 	}
 
-	return tables
+	return tables, nil
 }
 
-func GenerateStructs(tables map[string]TableSchema) {
-	cfg, err := LoadConfig()
+func GenerateStructs(tables map[string]TableSchema) error {
+	g, err := newDefaultGenerator()
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		return err
 	}
+	return g.GenerateStructs(tables)
+}
 
-	modelsDir, err := GetModelsPath(cfg)
+func (g *Generator) GenerateStructs(tables map[string]TableSchema) error {
+	modelsDir, err := g.modelsDir()
 	if err != nil {
-		log.Fatalf("failed to get models path: %v", err)
-	}
-	if err := os.MkdirAll(modelsDir, 0755); err != nil {
-		log.Fatalf("failed to create models directory: %v", err)
+		return err
 	}
 
-	for _, table := range tables {
-		singularTable := singularize(table.TableName)
-		structName := toPascalCase(singularTable)
+	// Relations are only generated towards included tables
+	opts := g.tableOptions()
+	included := opts.filter(tables)
+	g.enumTypes = enumTypeNames(included, opts)
+	// Labels of the enum types used by the models, keyed by enum type
+	enums := make(map[string][]string)
+	usesOptional := false
+	// Tables are generated in name order so that runs are reproducible
+	names := make([]string, 0, len(included))
+	for name := range included {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		table := included[name]
+		if !g.selected(table.TableName) {
+			continue
+		}
+		structName := opts.StructName(table.TableName)
 		switch structName {
 		case "model":
 			continue
@@ -93,76 +198,143 @@ func GenerateStructs(tables map[string]TableSchema) {
 
 		filePath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
 
-		needsTime := false
-		for _, col := range table.Columns {
-			if strings.Contains(col.Type, "timestamp") {
-				needsTime = true
-				break
-			}
-		}
-
-		var b strings.Builder
-		b.WriteString("package models\n\n")
-		if needsTime {
-			b.WriteString("import \"time\"\n\n")
+		data := ModelTemplateData{
+			StructName: structName,
+			TableName:  table.TableName,
 		}
-		b.WriteString("type " + structName + " struct {\n")
-
+		keyColumns := table.KeyColumns()
+		foreignKeys := table.ForeignKeys(included)
 		for _, col := range table.Columns {
-			fieldName := toPascalCase(col.Name)
-			fieldType := pgToGoType(col.Type, col.IsNullable)
+			isKey := slices.Contains(keyColumns, col.Name)
+			override := g.columnOverride(table.TableName, col.Name)
 
 			omitempty := ""
-			if col.Name == "id" || col.Name == "created_at" || col.Name == "updated_at" || col.IsNullable {
+			if isKey || col.Name == "id" || col.Name == "created_at" || col.Name == "updated_at" || col.IsNullable {
 				omitempty = ",omitempty"
 			}
+			jsonName := toCamelCase(col.Name)
+			if override.JSON != "" {
+				jsonName = override.JSON
+			}
+			// A field left out of JSON cannot be sent or received
+			if jsonName == "-" {
+				omitempty = ""
+				if override.DTO == "" {
+					override.DTO = "-"
+				}
+			}
+
+			goType, importPath, err := g.fieldGoType(table.TableName, col)
+			if err != nil {
+				return err
+			}
+			// Nullable types are validated like the pointers of the DTOs
+			validationType := goType
+			if elem, ok := unwrapNullable(goType); ok {
+				validationType = "*" + elem
+				usesOptional = usesOptional || strings.HasPrefix(goType, "Optional[")
+			}
+			// Enum types are strings, validated against their labels
+			if name, ok := g.enumTypes[col.EnumType]; ok && strings.TrimPrefix(validationType, "*") == name {
+				enums[col.EnumType] = col.EnumValues
+				validationType = strings.Replace(validationType, name, "string", 1)
+			}
+			known := maps.Clone(knownPackages)
+			if importPath != "" {
+				known[importName(importPath)] = importPath
+			}
+			for _, typeImport := range typeImports([]string{goType}, known) {
+				if typeImport == "time" {
+					data.NeedsTime = true
+				} else if !slices.Contains(data.Imports, typeImport) {
+					data.Imports = append(data.Imports, typeImport)
+				}
+			}
+
+			extraTags := ""
+			if rules := columnValidation(col, validationType); rules != "" {
+				extraTags = fmt.Sprintf(` validate:"%s"`, rules)
+			}
+			if override.DTO != "" {
+				extraTags += fmt.Sprintf(` dto:"%s"`, override.DTO)
+			}
+			if isKey {
+				extraTags += ` pk:"true"`
+			}
+			for _, fk := range foreignKeys {
+				if fk.Column == col.Name {
+					extraTags += fmt.Sprintf(` fk:"%s.%s"`, fk.ParentTable, fk.ParentColumn)
+				}
+			}
 
-			jsonTag := fmt.Sprintf("`json:\"%s%s\" db:\"%s\"`", toCamelCase(col.Name), omitempty, col.Name)
-			b.WriteString(fmt.Sprintf("\t%s %s %s\n", fieldName, fieldType, jsonTag))
+			data.Fields = append(data.Fields, ModelTemplateField{
+				Name:         toPascalCase(col.Name),
+				Type:         goType,
+				Column:       col.Name,
+				IsPrimaryKey: isKey,
+				Tag:          fmt.Sprintf("`json:\"%s%s\" db:\"%s\"%s`", jsonName, omitempty, col.Name, extraTags),
+			})
 		}
-		b.WriteString("}\n")
-		b.WriteString("\n")
-		b.WriteString("func (" + structName + ") TableName() string {\n")
-		b.WriteString("	return \"" + table.TableName + "\" \n")
-		b.WriteString("}\n")
-
-		if err := os.WriteFile(filePath, []byte(b.String()), 0644); err != nil {
-			log.Fatalf("Failed to write file %s: %v", filePath, err)
+
+		// Relation fields have no db tag, so the crud package leaves them out
+		// of its queries, and are kept out of the DTOs which embed parent DTOs
+		// instead
+		for _, fk := range foreignKeys {
+			data.Relations = append(data.Relations, ModelTemplateField{
+				Name:   toPascalCase(fk.Name),
+				Type:   "*" + opts.StructName(fk.ParentTable),
+				Column: fk.Column,
+				Tag:    fmt.Sprintf("`json:\"%s,omitempty\" rel:\"%s\" dto:\"-\"`", toCamelCase(fk.Name), fk.Column),
+			})
 		}
-		fmt.Printf("✅ Generated struct for table: %s → %s\n", table.TableName, filePath)
-	}
-}
 
-func GenerateOpenAPI(tables map[string]TableSchema) {
-	cfg, err := LoadConfig()
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		code, err := g.renderTemplate(ModelTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		written, err := g.writeGenerated(filePath, []byte(code))
+		if err != nil {
+			return err
+		}
+		if written && !g.Output.DryRun {
+			fmt.Printf("✅ Generated struct for table: %s → %s\n", table.TableName, filePath)
+		}
+
+		hash, err := schemaHash(table)
+		if err != nil {
+			return &SchemaError{Err: err}
+		}
+		if g.schemaHashes == nil {
+			g.schemaHashes = make(map[string]string)
+		}
+		g.schemaHashes[filepath.Clean(filePath)] = hash
 	}
 
-	apiDir, err := GetOpenAPIPath(cfg)
-	if err != nil {
-		log.Fatalf("failed to get OpenAPI path: %v", err)
+	if err := g.generateEnums(modelsDir, enums); err != nil {
+		return err
 	}
-	if err := os.MkdirAll(apiDir, 0755); err != nil {
-		log.Fatalf("failed to create OpenAPI directory: %v", err)
+	if usesOptional {
+		if err := g.generateOptionalFile(modelsDir); err != nil {
+			return err
+		}
 	}
-	filePath := filepath.Join(apiDir, "openapi_gen.go")
-
-	var b strings.Builder
-	b.WriteString("package api\n\n")
-	b.WriteString("// Auto-generated OpenAPI schema stubs\n\n")
+	return g.syncManifest(modelsDir)
+}
 
-	for _, table := range tables {
-		singularTable := singularize(table.TableName)
-		resource := toPascalCase(singularTable)
-		b.WriteString(fmt.Sprintf("// %sResource defines OpenAPI schema and endpoints for %s\n", resource, table.TableName))
-		b.WriteString(fmt.Sprintf("type %sResource struct {}\n\n", resource))
+// fieldGoType returns the Go type of the model field of a column, mapped from
+// its type unless overridden by a go_type, and the import path it needs
+func (g *Generator) fieldGoType(table string, col Column) (goType, importPath string, err error) {
+	if override := g.columnOverride(table, col.Name); override.GoType != "" {
+		if goType, importPath, err = parseGoType(override.GoType); err != nil {
+			return "", "", &ConfigError{Op: "apply go_type of " + table + "." + col.Name, Err: err}
+		}
+		return goType, importPath, nil
 	}
-
-	if err := os.WriteFile(filePath, []byte(b.String()), 0644); err != nil {
-		log.Fatalf("failed to write OpenAPI file: %v", err)
+	if goType, importPath, err = g.columnGoType(col); err != nil {
+		return "", "", &ConfigError{Op: "map type of " + table + "." + col.Name, Err: err}
 	}
-	fmt.Printf("✅ Generated OpenAPI resource stubs → %s\n", filePath)
+	return goType, importPath, nil
 }
 
 func pgToGoType(pgType string, nullable bool) string {
@@ -183,6 +355,19 @@ func pgToGoType(pgType string, nullable bool) string {
 		"real":                        "float32",
 		"json":                        "map[string]interface{}",
 		"jsonb":                       "map[string]interface{}",
+		// Internal names, used by array types like _int4
+		"int2":        "int16",
+		"int4":        "int",
+		"int8":        "int64",
+		"float4":      "float32",
+		"float8":      "float64",
+		"bool":        "bool",
+		"bpchar":      "string",
+		"timestamptz": "time.Time",
+	}
+	// Arrays are reported as text[], or as _text by their internal name
+	if elem, ok := pgArrayElement(pgType); ok {
+		return "[]" + strings.TrimPrefix(pgToGoType(elem, false), "*")
 	}
 	goType, ok := base[pgType]
 	if !ok {
@@ -191,11 +376,22 @@ func pgToGoType(pgType string, nullable bool) string {
 		goType = "string"
 	}
 
-	isTimestamp := goType == "time.Time"
-	if (nullable || isTimestamp) && goType != "interface{}" {
-		goType = "*" + goType
+	return nullableGoType(goType, nullable)
+}
+
+// pgArrayElement returns the element type of a PostgreSQL array type. Arrays
+// of unknown element type are read as text.
+func pgArrayElement(pgType string) (string, bool) {
+	if pgType == "ARRAY" {
+		return "text", true
+	}
+	if elem, ok := strings.CutSuffix(pgType, "[]"); ok {
+		return elem, true
+	}
+	if elem, ok := strings.CutPrefix(pgType, "_"); ok && elem != "" {
+		return elem, true
 	}
-	return goType
+	return "", false
 }
 
 func toPascalCase(s string) string {
@@ -207,6 +403,29 @@ func toPascalCase(s string) string {
 	return strings.Join(parts, "")
 }
 
+// toSnakeCase returns the snake case form of a Go name, order_item for
+// OrderItem and user_id for UserID
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rune(s[i-1])
+			next := i+1 < len(s) && unicode.IsLower(rune(s[i+1]))
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// defaultTableName returns the table of a model without a TableName method,
+// its pluralized snake case name
+func defaultTableName(structName string) string {
+	return Pluralize(toSnakeCase(structName))
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	if len(parts) == 1 {
@@ -248,9 +467,25 @@ func SingularizeExported(word string) string {
 	return word
 }
 
-func ScaffoldAll(db database.Database) {
-	tables := LoadSchema(db)
-	GenerateStructs(tables)
-	GenerateAPI(NoAuthConfig())
-	GenerateOpenAPI(tables)
+func ScaffoldAll(db database.Database) error {
+	g, err := newDefaultGenerator()
+	if err != nil {
+		return err
+	}
+	return g.ScaffoldAll(db, NoAuthConfig())
+}
+
+// ScaffoldAll loads the schema and runs the model, resource and OpenAPI generators
+func (g *Generator) ScaffoldAll(db database.Database, authCfg *AuthConfig) error {
+	tables, err := g.LoadSchema(db)
+	if err != nil {
+		return err
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		return err
+	}
+	if err := g.GenerateAPI(authCfg); err != nil {
+		return err
+	}
+	return g.GenerateOpenAPI(tables)
 }
@@ -0,0 +1,190 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Nullable styles, typing a nullable text column as *string, sql.NullString
+// or Optional[string]
+const (
+	NullablePointer  = "pointer"
+	NullableSQL      = "sql"
+	NullableOptional = "optional"
+)
+
+var nullableStyles = []string{NullablePointer, NullableSQL, NullableOptional}
+
+// sqlNullTypes maps Go types to the database/sql type holding them when
+// nullable, and sqlNullFields the field of that type holding the value. Other
+// types, like int which sql.NullInt64 would widen or uint8, use the generic
+// sql.Null[T] so that the DTOs keep the type of the column.
+var (
+	sqlNullTypes = map[string]string{
+		"string":    "sql.NullString",
+		"int64":     "sql.NullInt64",
+		"int32":     "sql.NullInt32",
+		"int16":     "sql.NullInt16",
+		"byte":      "sql.NullByte",
+		"float64":   "sql.NullFloat64",
+		"bool":      "sql.NullBool",
+		"time.Time": "sql.NullTime",
+	}
+	sqlNullFields = map[string]struct{ valueType, field string }{
+		"sql.NullString":  {"string", "String"},
+		"sql.NullInt64":   {"int64", "Int64"},
+		"sql.NullInt32":   {"int32", "Int32"},
+		"sql.NullInt16":   {"int16", "Int16"},
+		"sql.NullByte":    {"byte", "Byte"},
+		"sql.NullFloat64": {"float64", "Float64"},
+		"sql.NullBool":    {"bool", "Bool"},
+		"sql.NullTime":    {"time.Time", "Time"},
+	}
+)
+
+func (g *Generator) nullableStyle() string {
+	if g.Options == nil || g.Options.NullableStyle == "" {
+		return NullablePointer
+	}
+	return g.Options.NullableStyle
+}
+
+// nullableGoType wraps the type of a nullable column in the configured style.
+// The pointer style also makes timestamps pointers, so that they can be left
+// to their database default; the other styles only wrap nullable columns.
+func (g *Generator) nullableGoType(goType string, nullable bool) string {
+	style := g.nullableStyle()
+	if style == NullablePointer {
+		return nullableGoType(goType, nullable)
+	}
+	if !nullable || holdsNil(goType) {
+		return goType
+	}
+	if style == NullableOptional {
+		return "Optional[" + goType + "]"
+	}
+	if nullType, ok := sqlNullTypes[goType]; ok {
+		return nullType
+	}
+	return "sql.Null[" + goType + "]"
+}
+
+// holdsNil reports whether the zero value of goType is nil already
+func holdsNil(goType string) bool {
+	return goType == "interface{}" || strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// unwrapNullable returns the type of the value held by a sql.Null* or
+// Optional type, like string for sql.NullString
+func unwrapNullable(goType string) (string, bool) {
+	if info, ok := sqlNullFields[goType]; ok {
+		return info.valueType, true
+	}
+	for _, generic := range []string{"sql.Null[", "Optional[", "models.Optional["} {
+		if elem, ok := strings.CutPrefix(goType, generic); ok && strings.HasSuffix(elem, "]") {
+			return strings.TrimSuffix(elem, "]"), true
+		}
+	}
+	return "", false
+}
+
+// nullableField makes a model field of a sql.Null* or Optional type look
+// like a pointer field to the DTOs, keeping its model type in NullType. With
+// the pointer style, sql.Null* fields set by go_type are kept as is.
+func (g *Generator) nullableField(field StructField) StructField {
+	if field.IsPointer || (g.nullableStyle() == NullablePointer && strings.HasPrefix(field.Type, "sql.")) {
+		return field
+	}
+	if elem, ok := unwrapNullable(field.Type); ok {
+		field.NullType = field.Type
+		field.Type = elem
+		field.IsPointer = true
+	}
+	return field
+}
+
+// nullablePointer returns the expression of a pointer to the value of the
+// model field expr of type nullType, nil when null
+func nullablePointer(expr, nullType string) string {
+	switch {
+	case nullType == "":
+		return expr
+	case strings.HasPrefix(nullType, "Optional["), strings.HasPrefix(nullType, "models.Optional["):
+		return expr + ".Ptr()"
+	case strings.HasPrefix(nullType, "sql.Null["):
+		return fmt.Sprintf("nullPtr(%s.V, %s.Valid)", expr, expr)
+	}
+	return fmt.Sprintf("nullPtr(%s.%s, %s.Valid)", expr, sqlNullFields[nullType].field, expr)
+}
+
+// nullableValue returns the expression of the model field value of type
+// nullType holding the value the pointer expr points to
+func nullableValue(expr, nullType string) string {
+	switch {
+	case nullType == "":
+		return expr
+	case strings.HasPrefix(nullType, "Optional["), strings.HasPrefix(nullType, "models.Optional["):
+		return "models.OptionalOf(" + expr + ")"
+	case strings.HasPrefix(nullType, "sql.Null["):
+		return "nullOf(" + expr + ")"
+	}
+	return fmt.Sprintf("%s{%s: nullValue(%s), Valid: %s != nil}", nullType, sqlNullFields[nullType].field, expr, expr)
+}
+
+// nullableValueOf returns the expression of the model field value holding the
+// value expr: expr itself for a plain field, its address for a pointer field
+// and a valid sql.Null* or Optional value for a field of type nullType
+func nullableValueOf(expr, nullType string, pointer bool) string {
+	if info, ok := sqlNullFields[nullType]; ok {
+		return fmt.Sprintf("%s{%s: %s, Valid: true}", nullType, info.field, expr)
+	}
+	if nullType == "" && !pointer {
+		return expr
+	}
+	return nullableValue("&"+expr, nullType)
+}
+
+// usesSQLNull reports whether a model field is stored in a database/sql type
+func usesSQLNull(fields []StructField) bool {
+	for _, field := range fields {
+		if strings.HasPrefix(field.NullType, "sql.") {
+			return true
+		}
+	}
+	return false
+}
+
+// generateOptionalFile generates the Optional type of the models package
+func (g *Generator) generateOptionalFile(modelsDir string) error {
+	filePath := filepath.Join(modelsDir, "optional.go")
+	code, err := g.renderTemplate(OptionalTemplate, nil)
+	if err != nil {
+		return err
+	}
+	written, err := g.writeGenerated(filePath, []byte(code))
+	if err != nil {
+		return err
+	}
+	if written && !g.Output.DryRun {
+		fmt.Printf("✅ Generated Optional type → %s\n", filePath)
+	}
+	return nil
+}
+
+// generateNullableFile generates the nullable.go file shared by the resources
+// converting sql.Null* model fields to and from DTO pointers
+func (g *Generator) generateNullableFile(nullablePath string) error {
+	code, err := g.renderTemplate(NullableTemplate, nil)
+	if err != nil {
+		return err
+	}
+	written, err := g.writeGenerated(nullablePath, []byte(code))
+	if err != nil {
+		return err
+	}
+	if written && !g.Output.DryRun {
+		fmt.Printf("✅ Generated nullable conversions → %s\n", nullablePath)
+	}
+	return nil
+}
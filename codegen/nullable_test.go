@@ -0,0 +1,129 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestNullableGoType(t *testing.T) {
+	tests := []struct {
+		style    string
+		goType   string
+		nullable bool
+		expected string
+	}{
+		{NullablePointer, "string", true, "*string"},
+		{NullablePointer, "time.Time", false, "*time.Time"},
+		{NullablePointer, "[]string", true, "[]string"},
+		{NullableSQL, "string", true, "sql.NullString"},
+		{NullableSQL, "int", true, "sql.Null[int]"},
+		{NullableSQL, "int64", true, "sql.NullInt64"},
+		{NullableSQL, "uint8", true, "sql.Null[uint8]"},
+		{NullableSQL, "time.Time", true, "sql.NullTime"},
+		{NullableSQL, "time.Time", false, "time.Time"},
+		{NullableSQL, "uuid.UUID", true, "sql.Null[uuid.UUID]"},
+		{NullableSQL, "map[string]interface{}", true, "map[string]interface{}"},
+		{NullableOptional, "string", true, "Optional[string]"},
+		{NullableOptional, "float32", false, "float32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style+"/"+tt.goType, func(t *testing.T) {
+			g := &Generator{Options: &Options{NullableStyle: tt.style}}
+			if result := g.nullableGoType(tt.goType, tt.nullable); result != tt.expected {
+				t.Errorf("nullableGoType(%s, %v) = %s; want %s", tt.goType, tt.nullable, result, tt.expected)
+			}
+			// DTOs hold the type of the column whatever the style
+			if elem, ok := unwrapNullable(tt.expected); ok && elem != tt.goType {
+				t.Errorf("unwrapNullable(%s) = %s; want %s", tt.expected, elem, tt.goType)
+			}
+		})
+	}
+}
+
+func TestGenerateNullableStyles(t *testing.T) {
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{
+			{Name: "id", Type: "integer", IsPrimaryKey: true},
+			{Name: "bio", Type: "text", IsNullable: true},
+		}},
+	}
+
+	tests := []struct {
+		style    string
+		model    string
+		optional bool
+		resource []string
+	}{
+		{
+			style:    NullablePointer,
+			model:    "Bio *string `json:\"bio,omitempty\" db:\"bio\"`",
+			resource: []string{"Bio: m.Bio,", "Bio: dto.Bio,"},
+		},
+		{
+			style: NullableSQL,
+			model: "Bio sql.NullString `json:\"bio,omitempty\" db:\"bio\"`",
+			resource: []string{
+				"Bio: nullPtr(m.Bio.String, m.Bio.Valid),",
+				"Bio: sql.NullString{String: nullValue(dto.Bio), Valid: dto.Bio != nil},",
+			},
+		},
+		{
+			style:    NullableOptional,
+			model:    "Bio Optional[string] `json:\"bio,omitempty\" db:\"bio\"`",
+			optional: true,
+			resource: []string{"Bio: m.Bio.Ptr(),", "Bio: models.OptionalOf(dto.Bio),"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &config.Config{}
+			cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+			g := NewGenerator(cfg, nil)
+			g.Options.NullableStyle = tt.style
+			if err := g.GenerateStructs(tables); err != nil {
+				t.Fatalf("Failed to generate models: %v", err)
+			}
+
+			model, err := os.ReadFile(filepath.Join(dir, "models", "user.go"))
+			if err != nil {
+				t.Fatalf("Failed to read model: %v", err)
+			}
+			if !strings.Contains(strings.Join(strings.Fields(string(model)), " "), tt.model) {
+				t.Errorf("Expected model to contain %q, got:\n%s", tt.model, model)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "models", "optional.go")); (err == nil) != tt.optional {
+				t.Errorf("Expected optional.go to be generated: %v, got %v", tt.optional, err)
+			}
+
+			fields, err := g.modelFields("User")
+			if err != nil {
+				t.Fatalf("Failed to read model fields: %v", err)
+			}
+			dto, err := g.generateDTOsFromModel("User", fields)
+			if err != nil {
+				t.Fatalf("Failed to generate DTOs: %v", err)
+			}
+			if !strings.Contains(dto, "Bio *string `json:\"bio,omitempty\"`") {
+				t.Errorf("Expected DTOs to keep a pointer field, got:\n%s", dto)
+			}
+
+			code, err := g.generateResourceFromModel("User", fields, NoAuthConfig())
+			if err != nil {
+				t.Fatalf("Failed to generate resource: %v", err)
+			}
+			code = strings.Join(strings.Fields(code), " ")
+			for _, want := range tt.resource {
+				if !strings.Contains(code, want) {
+					t.Errorf("Expected resource to contain %q", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,800 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OpenAPIVersion    = "3.1.0"
+	OpenAPISchemaFile = "schema.yaml"
+)
+
+var openAPIMediaTypes = []string{"application/json", "application/ld+json"}
+
+// OpenAPIDocument is the root object of a generated OpenAPI 3.1 document
+type OpenAPIDocument struct {
+	OpenAPI    string                      `yaml:"openapi"`
+	Info       OpenAPIInfo                 `yaml:"info"`
+	Paths      map[string]*OpenAPIPathItem `yaml:"paths"`
+	Components OpenAPIComponents           `yaml:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `yaml:"parameters,omitempty"`
+	Get        *OpenAPIOperation   `yaml:"get,omitempty"`
+	Post       *OpenAPIOperation   `yaml:"post,omitempty"`
+	Put        *OpenAPIOperation   `yaml:"put,omitempty"`
+	Patch      *OpenAPIOperation   `yaml:"patch,omitempty"`
+	Delete     *OpenAPIOperation   `yaml:"delete,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Summary     string                      `yaml:"summary,omitempty"`
+	Description string                      `yaml:"description,omitempty"`
+	Tags        []string                    `yaml:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `yaml:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Ref         string         `yaml:"$ref,omitempty"`
+	Name        string         `yaml:"name,omitempty"`
+	In          string         `yaml:"in,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	Required    bool           `yaml:"required,omitempty"`
+	Style       string         `yaml:"style,omitempty"`
+	Explode     *bool          `yaml:"explode,omitempty"`
+	Schema      *OpenAPISchema `yaml:"schema,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Description string                       `yaml:"description,omitempty"`
+	Required    bool                         `yaml:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `yaml:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `yaml:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPISchema is a JSON Schema object. Type is either a single type name or,
+// for nullable values, a list such as [string, "null"] as required by OpenAPI 3.1.
+type OpenAPISchema struct {
+	Ref                  string                    `yaml:"$ref,omitempty"`
+	Type                 any                       `yaml:"type,omitempty"`
+	Format               string                    `yaml:"format,omitempty"`
+	Description          string                    `yaml:"description,omitempty"`
+	Enum                 []any                     `yaml:"enum,omitempty"`
+	Default              any                       `yaml:"default,omitempty"`
+	Minimum              *int                      `yaml:"minimum,omitempty"`
+	Maximum              *int                      `yaml:"maximum,omitempty"`
+	MaxLength            *int                      `yaml:"maxLength,omitempty"`
+	Items                *OpenAPISchema            `yaml:"items,omitempty"`
+	UniqueItems          bool                      `yaml:"uniqueItems,omitempty"`
+	Properties           map[string]*OpenAPISchema `yaml:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `yaml:"additionalProperties,omitempty"`
+	Required             []string                  `yaml:"required,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas    map[string]*OpenAPISchema    `yaml:"schemas,omitempty"`
+	Parameters map[string]*OpenAPIParameter `yaml:"parameters,omitempty"`
+}
+
+func GenerateOpenAPI(tables map[string]TableSchema) error {
+	g, err := newDefaultGenerator()
+	if err != nil {
+		return err
+	}
+	return g.GenerateOpenAPI(tables)
+}
+
+func (g *Generator) GenerateOpenAPI(tables map[string]TableSchema) error {
+	apiDir, err := g.openAPIDir()
+	if err != nil {
+		return err
+	}
+	filePath := filepath.Join(apiDir, OpenAPISchemaFile)
+
+	resources, err := g.LoadResourceDTOs()
+	if err != nil {
+		return err
+	}
+	doc := BuildOpenAPIDocumentWithOptions(getModuleName(), tables, resources, g.tableOptions())
+
+	data, err := MarshalOpenAPIDocument(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	written, err := g.writeGenerated(filePath, data)
+	if err != nil {
+		return err
+	}
+	if written && !g.Output.DryRun {
+		fmt.Printf("✅ Generated OpenAPI document → %s\n", filePath)
+	}
+	return g.syncManifest(apiDir)
+}
+
+// MarshalOpenAPIDocument encodes the document as YAML with two-space indentation
+func MarshalOpenAPIDocument(doc *OpenAPIDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BuildOpenAPIDocument builds the OpenAPI document for every table that has
+// generated DTOs. Component schemas are derived from the DTO structs so the
+// spec describes exactly what the generated resources send and accept.
+func BuildOpenAPIDocument(title string, tables map[string]TableSchema, resources map[string]ResourceDTOs) *OpenAPIDocument {
+	return BuildOpenAPIDocumentWithOptions(title, tables, resources, TableOptions{})
+}
+
+// BuildOpenAPIDocumentWithOptions builds the OpenAPI document for the tables
+// included by opts, following their struct name, path and operation overrides
+func BuildOpenAPIDocumentWithOptions(title string, tables map[string]TableSchema, resources map[string]ResourceDTOs, opts TableOptions) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:   title,
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]*OpenAPIPathItem),
+		Components: OpenAPIComponents{
+			Schemas: map[string]*OpenAPISchema{
+				"Error": {
+					Type: "object",
+					Properties: map[string]*OpenAPISchema{
+						"error": {Type: "string"},
+					},
+					Required: []string{"error"},
+				},
+			},
+			Parameters: paginationParameters(),
+		},
+	}
+
+	tables = opts.filter(tables)
+	tableNames := make([]string, 0, len(tables))
+	for name := range tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		table := tables[tableName]
+		structName := opts.StructName(table.TableName)
+		resource, ok := resources[strings.ToLower(structName)]
+		if !ok {
+			continue
+		}
+
+		// Relations are only generated towards parents that have a resource
+		var foreignKeys []ForeignKey
+		for _, fk := range table.ForeignKeys(tables) {
+			if _, ok := resources[strings.ToLower(opts.StructName(fk.ParentTable))]; ok {
+				foreignKeys = append(foreignKeys, fk)
+			}
+		}
+		addResourceToOpenAPI(doc, structName, table, resource, foreignKeys, resources, opts)
+	}
+
+	return doc
+}
+
+func addResourceToOpenAPI(doc *OpenAPIDocument, structName string, table TableSchema, resource ResourceDTOs, foreignKeys []ForeignKey, resources map[string]ResourceDTOs, opts TableOptions) {
+	mainName := structName + "DTO"
+	createName := structName + "CreateDTO"
+	updateName := structName + "UpdateDTO"
+	patchName := structName + "PatchDTO"
+
+	mainDTO, ok := resource.DTOs[mainName]
+	if !ok {
+		return
+	}
+
+	doc.Components.Schemas[mainName] = dtoToOpenAPISchema(mainDTO, table, false)
+	doc.Components.Schemas[structName+"Collection"] = hydraCollectionSchema(mainName)
+	if dto, ok := resource.DTOs[createName]; ok {
+		doc.Components.Schemas[createName] = dtoToOpenAPISchema(dto, table, true)
+	}
+	if dto, ok := resource.DTOs[updateName]; ok {
+		doc.Components.Schemas[updateName] = dtoToOpenAPISchema(dto, table, false)
+	}
+	if dto, ok := resource.DTOs[patchName]; ok {
+		doc.Components.Schemas[patchName] = dtoToOpenAPISchema(dto, table, false)
+	}
+
+	pluralName := opts.Path(table.TableName)
+	operations := opts.operations(table.TableName)
+	tags := []string{pluralName}
+
+	listParams := []*OpenAPIParameter{
+		{Ref: "#/components/parameters/Page"},
+		{Ref: "#/components/parameters/Limit"},
+		{Ref: "#/components/parameters/Count"},
+	}
+	listParams = append(listParams, filterParameters(table, mainDTO)...)
+	if order := orderParameter(table, mainDTO); order != nil {
+		listParams = append(listParams, order)
+	}
+	if include := includeParameter(foreignKeys); include != nil {
+		listParams = append(listParams, include)
+	}
+
+	listResponses := map[string]*OpenAPIResponse{
+		"200": jsonResponse("Paginated "+pluralName, structName+"Collection"),
+		"400": errorResponse("Invalid filter or ordering"),
+		"500": errorResponse("Internal server error"),
+	}
+	collection := &OpenAPIPathItem{}
+	if operations.List {
+		collection.Get = &OpenAPIOperation{
+			OperationID: "list" + structName,
+			Summary:     "List " + structName,
+			Tags:        tags,
+			Parameters:  listParams,
+			Responses:   listResponses,
+		}
+	}
+	keyColumns := table.KeyColumns()
+	if _, ok := doc.Components.Schemas[createName]; ok && len(keyColumns) > 0 && operations.Create {
+		collection.Post = &OpenAPIOperation{
+			OperationID: "create" + structName,
+			Summary:     "Create " + structName,
+			Tags:        tags,
+			RequestBody: jsonRequestBody("New "+structName, createName),
+			Responses: map[string]*OpenAPIResponse{
+				"201": jsonResponse("Created "+structName, mainName),
+				"400": errorResponse("Invalid request body"),
+				"500": errorResponse("Internal server error"),
+			},
+		}
+		addValidationResponse(doc, collection.Post, resource.DTOs[createName])
+	}
+	if collection.Get != nil || collection.Post != nil {
+		doc.Paths["/"+pluralName] = collection
+	}
+
+	// Nested routes list the children of a parent, like /users/{id}/posts,
+	// as list operations
+	if operations.List {
+		parents := make(map[string]int, len(foreignKeys))
+		for _, fk := range foreignKeys {
+			parents[fk.ParentTable]++
+		}
+		for _, fk := range foreignKeys {
+			parentStruct := opts.StructName(fk.ParentTable)
+			parentPlural := opts.Path(fk.ParentTable)
+			parentDTO := resources[strings.ToLower(parentStruct)].DTOs[parentStruct+"DTO"]
+			segment := nestedRouteSegment(path.Base(pluralName), fk.Column, parents[fk.ParentTable] > 1)
+
+			doc.Paths["/"+parentPlural+"/{"+fk.ParentColumn+"}/"+segment] = &OpenAPIPathItem{
+				Parameters: keyParameters([]string{fk.ParentColumn}, parentDTO),
+				Get: &OpenAPIOperation{
+					OperationID: "list" + structName + "By" + toPascalCase(fk.Name),
+					Summary:     "List " + structName + " by " + toPascalCase(fk.Name),
+					Tags:        tags,
+					Parameters:  listParams,
+					Responses:   listResponses,
+				},
+			}
+		}
+	}
+
+	// Tables without a primary key only get a List operation
+	if len(keyColumns) == 0 {
+		return
+	}
+
+	keyPath := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		keyPath[i] = "{" + column + "}"
+	}
+
+	item := &OpenAPIPathItem{Parameters: keyParameters(keyColumns, mainDTO)}
+	if operations.Get {
+		item.Get = &OpenAPIOperation{
+			OperationID: "get" + structName,
+			Summary:     "Get " + structName,
+			Tags:        tags,
+			Responses: map[string]*OpenAPIResponse{
+				"200": jsonResponse(structName, mainName),
+				"400": errorResponse("Invalid ID"),
+				"404": errorResponse("Not found"),
+			},
+		}
+	}
+	if operations.Delete {
+		item.Delete = &OpenAPIOperation{
+			OperationID: "delete" + structName,
+			Summary:     "Delete " + structName,
+			Tags:        tags,
+			Responses: map[string]*OpenAPIResponse{
+				"204": {Description: "Deleted"},
+				"400": errorResponse("Invalid ID"),
+				"404": errorResponse("Not found"),
+				"500": errorResponse("Internal server error"),
+			},
+		}
+	}
+	if dto, ok := resource.DTOs[updateName]; ok && operations.Update && (len(dto.Fields) > 0 || isDefaultKeyColumns(keyColumns)) {
+		item.Put = &OpenAPIOperation{
+			OperationID: "update" + structName,
+			Summary:     "Update " + structName,
+			Tags:        tags,
+			RequestBody: jsonRequestBody("Updated "+structName, updateName),
+			Responses: map[string]*OpenAPIResponse{
+				"200": jsonResponse("Updated "+structName, mainName),
+				"400": errorResponse("Invalid request body or ID"),
+				"404": errorResponse("Not found"),
+				"500": errorResponse("Internal server error"),
+			},
+		}
+		addValidationResponse(doc, item.Put, dto)
+	}
+	if dto, ok := resource.DTOs[patchName]; ok && operations.Patch && len(dto.Fields) > 0 {
+		item.Patch = &OpenAPIOperation{
+			OperationID: "patch" + structName,
+			Summary:     "Patch " + structName,
+			Description: "Updates the fields present in the body, leaving the others unchanged",
+			Tags:        tags,
+			RequestBody: jsonRequestBody("Fields to update", patchName),
+			Responses: map[string]*OpenAPIResponse{
+				"200": jsonResponse("Updated "+structName, mainName),
+				"400": errorResponse("Invalid request body or ID"),
+				"404": errorResponse("Not found"),
+				"500": errorResponse("Internal server error"),
+			},
+		}
+		addValidationResponse(doc, item.Patch, dto)
+	}
+	if item.Get != nil || item.Put != nil || item.Patch != nil || item.Delete != nil {
+		doc.Paths["/"+pluralName+"/"+strings.Join(keyPath, "/")] = item
+	}
+}
+
+func dtoToOpenAPISchema(dto DTOSchema, table TableSchema, requireValues bool) *OpenAPISchema {
+	columns := make(map[string]Column, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[toPascalCase(col.Name)] = col
+	}
+
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenAPISchema),
+	}
+	for _, field := range dto.Fields {
+		name := openAPIFieldName(field)
+		property := fieldToOpenAPISchema(field)
+		nullable := field.IsPointer
+		if col, ok := columns[field.Name]; ok {
+			applyColumnMetadata(property, col, field)
+			// Slices and maps of nullable columns hold nil rather than being
+			// pointers, and may be left out of the body
+			if col.IsNullable && !field.IsPointer && holdsNil(field.Type) {
+				nullable = true
+				if typ, ok := property.Type.(string); ok && typ != "" {
+					property.Type = []string{typ, "null"}
+				}
+			}
+		}
+		if property.Ref == "" && hasRule(field.ValidateTag, "email") {
+			property.Format = "email"
+		}
+		schema.Properties[name] = property
+		if (requireValues && !nullable) || hasRule(field.ValidateTag, "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+func fieldToOpenAPISchema(field StructField) *OpenAPISchema {
+	// Parent DTOs embedded with ?include=
+	if strings.HasSuffix(field.Type, "DTO") {
+		return &OpenAPISchema{Ref: "#/components/schemas/" + field.Type}
+	}
+
+	typ, format := GoTypeToOpenAPIType(field.Type)
+	schema := &OpenAPISchema{Type: typ, Format: format}
+	switch typ {
+	case "object":
+		schema.AdditionalProperties = &OpenAPISchema{}
+	case "array":
+		elem := StructField{Type: strings.TrimPrefix(field.Type, "[]")}
+		if elemType, ok := strings.CutPrefix(elem.Type, "*"); ok {
+			elem.Type, elem.IsPointer = elemType, true
+		}
+		schema.Items = fieldToOpenAPISchema(elem)
+	}
+	if strings.HasPrefix(field.Type, "uint") {
+		minimum := 0
+		schema.Minimum = &minimum
+	}
+	if field.IsPointer {
+		schema.Type = []string{typ, "null"}
+	}
+	return schema
+}
+
+func openAPIFieldName(field StructField) string {
+	if field.JSONTag != "" {
+		return field.JSONTag
+	}
+	return toJSONCamelCase(field.Name)
+}
+
+func hydraCollectionSchema(dtoName string) *OpenAPISchema {
+	nullableString := &OpenAPISchema{Type: []string{"string", "null"}}
+	return &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"@context":         {Type: "string"},
+			"@id":              {Type: "string"},
+			"@type":            {Type: "string"},
+			"hydra:totalItems": {Type: "integer"},
+			"hydra:member": {
+				Type:  "array",
+				Items: &OpenAPISchema{Ref: "#/components/schemas/" + dtoName},
+			},
+			"hydra:view": {
+				Type: "object",
+				Properties: map[string]*OpenAPISchema{
+					"@id":            {Type: "string"},
+					"@type":          {Type: "string"},
+					"hydra:first":    {Type: "string"},
+					"hydra:last":     nullableString,
+					"hydra:previous": nullableString,
+					"hydra:next":     nullableString,
+				},
+			},
+		},
+		Required: []string{"@context", "@id", "@type", "hydra:member", "hydra:view"},
+	}
+}
+
+func paginationParameters() map[string]*OpenAPIParameter {
+	minPage := 1
+	minSize := MinPageSize
+	maxSize := MaxPageSize
+	return map[string]*OpenAPIParameter{
+		"Page": {
+			Name:        "page",
+			In:          "query",
+			Description: "Page number, starting at 1",
+			Schema:      &OpenAPISchema{Type: "integer", Minimum: &minPage, Default: 1},
+		},
+		"Limit": {
+			Name:        "limit",
+			In:          "query",
+			Description: "Number of items per page",
+			Schema:      &OpenAPISchema{Type: "integer", Minimum: &minSize, Maximum: &maxSize, Default: DefaultPageSize},
+		},
+		"Count": {
+			Name:        "count",
+			In:          "query",
+			Description: "Include hydra:totalItems in the response",
+			Schema:      &OpenAPISchema{Type: "boolean", Default: true},
+		},
+	}
+}
+
+// filterOperators returns the filter operators supported for an OpenAPI type,
+// matching the operators parsed by filter.FilterSet
+func filterOperators(openAPIType, format string) []string {
+	switch {
+	case openAPIType == "integer" || openAPIType == "number" || format == "date-time":
+		return []string{"ne", "gt", "gte", "lt", "lte"}
+	case openAPIType == "string":
+		return []string{"ne", "like", "ilike"}
+	case openAPIType == "boolean":
+		return []string{"ne"}
+	}
+	return nil
+}
+
+// filterableFields returns the DTO fields of the table columns the generated
+// List handler accepts for filtering and ordering
+func filterableFields(table TableSchema, dto DTOSchema) []StructField {
+	byName := make(map[string]StructField, len(dto.Fields))
+	for _, field := range dto.Fields {
+		byName[field.Name] = field
+	}
+
+	var fields []StructField
+	for _, col := range table.Columns {
+		if col.Name == "password" {
+			continue
+		}
+		field, ok := byName[toPascalCase(col.Name)]
+		if !ok {
+			continue
+		}
+		typ, _ := GoTypeToOpenAPIType(field.Type)
+		if typ == "object" || typ == "array" {
+			continue
+		}
+		field.DBTag = col.Name
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func filterParameters(table TableSchema, dto DTOSchema) []*OpenAPIParameter {
+	columns := make(map[string]Column, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[col.Name] = col
+	}
+
+	var params []*OpenAPIParameter
+	for _, field := range filterableFields(table, dto) {
+		col := columns[field.DBTag]
+		typ, format := GoTypeToOpenAPIType(field.Type)
+		valueSchema := &OpenAPISchema{Type: typ, Format: format}
+		for _, value := range col.EnumValues {
+			valueSchema.Enum = append(valueSchema.Enum, value)
+		}
+
+		params = append(params, &OpenAPIParameter{
+			Name:        field.DBTag,
+			In:          "query",
+			Description: fmt.Sprintf("Filter by %s (equals)", field.DBTag),
+			Schema:      valueSchema,
+		})
+		for _, op := range filterOperators(typ, format) {
+			params = append(params, &OpenAPIParameter{
+				Name:        fmt.Sprintf("%s[%s]", field.DBTag, op),
+				In:          "query",
+				Description: fmt.Sprintf("Filter by %s (%s)", field.DBTag, op),
+				Schema:      valueSchema,
+			})
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:        field.DBTag + "[]",
+			In:          "query",
+			Description: fmt.Sprintf("Filter by %s (in)", field.DBTag),
+			Schema:      &OpenAPISchema{Type: "array", Items: valueSchema, UniqueItems: col.IsUnique},
+		})
+	}
+	return params
+}
+
+func orderParameter(table TableSchema, dto DTOSchema) *OpenAPIParameter {
+	fields := filterableFields(table, dto)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]*OpenAPISchema, len(fields))
+	for _, field := range fields {
+		properties[field.DBTag] = &OpenAPISchema{Type: "string", Enum: []any{"asc", "desc"}}
+	}
+
+	explode := true
+	return &OpenAPIParameter{
+		Name:        "order",
+		In:          "query",
+		Description: "Sort order, e.g. order[created_at]=desc",
+		Style:       "deepObject",
+		Explode:     &explode,
+		Schema:      &OpenAPISchema{Type: "object", Properties: properties},
+	}
+}
+
+// applyColumnMetadata documents the introspected constraints of a column on the
+// schema of its DTO field
+func applyColumnMetadata(schema *OpenAPISchema, col Column, field StructField) {
+	if schema.Ref != "" {
+		return
+	}
+	typ, _ := GoTypeToOpenAPIType(field.Type)
+
+	if col.Comment != "" {
+		schema.Description = col.Comment
+	}
+	if col.MaxLength > 0 && typ == "string" {
+		maxLength := col.MaxLength
+		schema.MaxLength = &maxLength
+	}
+	if len(col.EnumValues) > 0 {
+		for _, value := range col.EnumValues {
+			schema.Enum = append(schema.Enum, value)
+		}
+		if field.IsPointer {
+			schema.Enum = append(schema.Enum, nil)
+		}
+	}
+	if value, ok := columnDefaultValue(col.Default, typ); ok {
+		schema.Default = value
+	}
+}
+
+// columnDefaultValue converts a column default expression to a JSON value of
+// type typ. Expressions other than literals, like now(), have no JSON value.
+func columnDefaultValue(expr, typ string) (any, bool) {
+	expr = strings.TrimSpace(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	// PostgreSQL casts literals, like 'draft'::character varying
+	if i := strings.LastIndex(expr, "::"); i > 0 && !strings.Contains(expr[i:], "'") {
+		expr = strings.TrimSpace(expr[:i])
+	}
+	if expr == "" || strings.EqualFold(expr, "null") {
+		return nil, false
+	}
+
+	if strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'") && len(expr) >= 2 {
+		value := strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+		if typ == "string" {
+			return value, true
+		}
+		expr = value
+	}
+
+	switch typ {
+	case "boolean":
+		switch strings.ToLower(expr) {
+		case "true", "1":
+			return true, true
+		case "false", "0":
+			return false, true
+		}
+	case "integer":
+		if value, err := strconv.ParseInt(expr, 10, 64); err == nil {
+			return value, true
+		}
+	case "number":
+		if value, err := strconv.ParseFloat(expr, 64); err == nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// includeParameter returns the include query parameter listing the relations
+// List can embed, or nil for tables without foreign keys
+func includeParameter(foreignKeys []ForeignKey) *OpenAPIParameter {
+	if len(foreignKeys) == 0 {
+		return nil
+	}
+
+	names := make([]any, len(foreignKeys))
+	for i, fk := range foreignKeys {
+		names[i] = toCamelCase(fk.Name)
+	}
+
+	explode := false
+	return &OpenAPIParameter{
+		Name:        "include",
+		In:          "query",
+		Description: "Relations to embed, comma separated",
+		Style:       "form",
+		Explode:     &explode,
+		Schema: &OpenAPISchema{
+			Type:  "array",
+			Items: &OpenAPISchema{Type: "string", Enum: names},
+		},
+	}
+}
+
+// keyParameters returns the path parameters for the primary key columns, typed
+// after the matching DTO fields
+func keyParameters(keyColumns []string, dto DTOSchema) []*OpenAPIParameter {
+	params := make([]*OpenAPIParameter, 0, len(keyColumns))
+	for _, column := range keyColumns {
+		schema := &OpenAPISchema{Type: "string"}
+		for _, field := range dto.Fields {
+			if field.Name == toPascalCase(column) {
+				typ, format := GoTypeToOpenAPIType(field.Type)
+				schema = &OpenAPISchema{Type: typ, Format: format}
+				break
+			}
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:     column,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+func isDefaultKeyColumns(keyColumns []string) bool {
+	return len(keyColumns) == 1 && keyColumns[0] == FieldID
+}
+
+func jsonContent(schemaName string) map[string]*OpenAPIMediaType {
+	content := make(map[string]*OpenAPIMediaType, len(openAPIMediaTypes))
+	for _, mediaType := range openAPIMediaTypes {
+		content[mediaType] = &OpenAPIMediaType{
+			Schema: &OpenAPISchema{Ref: "#/components/schemas/" + schemaName},
+		}
+	}
+	return content
+}
+
+func jsonResponse(description, schemaName string) *OpenAPIResponse {
+	return &OpenAPIResponse{Description: description, Content: jsonContent(schemaName)}
+}
+
+func jsonRequestBody(description, schemaName string) *OpenAPIRequestBody {
+	return &OpenAPIRequestBody{
+		Description: description,
+		Required:    true,
+		Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: &OpenAPISchema{Ref: "#/components/schemas/" + schemaName}}},
+	}
+}
+
+// addValidationResponse documents the 422 response of an operation whose
+// request DTO has validation rules
+func addValidationResponse(doc *OpenAPIDocument, op *OpenAPIOperation, dto DTOSchema) {
+	if !slices.ContainsFunc(dto.Fields, func(f StructField) bool { return f.ValidateTag != "" }) {
+		return
+	}
+	doc.Components.Schemas["ValidationError"] = &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"error": {Type: "string"},
+			"fields": {
+				Type: "array",
+				Items: &OpenAPISchema{
+					Type: "object",
+					Properties: map[string]*OpenAPISchema{
+						"field": {Type: "string"},
+						"rule":  {Type: "string"},
+						"param": {Type: "string"},
+					},
+					Required: []string{"field", "rule"},
+				},
+			},
+		},
+		Required: []string{"error", "fields"},
+	}
+	op.Responses["422"] = &OpenAPIResponse{
+		Description: "Validation failed",
+		Content: map[string]*OpenAPIMediaType{
+			"application/json": {Schema: &OpenAPISchema{Ref: "#/components/schemas/ValidationError"}},
+		},
+	}
+}
+
+func errorResponse(description string) *OpenAPIResponse {
+	return &OpenAPIResponse{
+		Description: description,
+		Content: map[string]*OpenAPIMediaType{
+			"application/json": {Schema: &OpenAPISchema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+}
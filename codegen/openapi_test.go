@@ -0,0 +1,525 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func testOpenAPIInput() (map[string]TableSchema, map[string]ResourceDTOs) {
+	tables := map[string]TableSchema{
+		"users": {
+			TableName: "users",
+			Columns: []Column{
+				{Name: "id", Type: "integer"},
+				{Name: "email", Type: "text"},
+				{Name: "password", Type: "text"},
+				{Name: "age", Type: "integer", IsNullable: true},
+				{Name: "created_at", Type: "timestamp"},
+			},
+		},
+		"audit_logs": {
+			TableName: "audit_logs",
+			Columns:   []Column{{Name: "id", Type: "integer"}},
+		},
+	}
+
+	resources := map[string]ResourceDTOs{
+		"user": {
+			Name:       "user",
+			PluralName: "users",
+			DTOs: map[string]DTOSchema{
+				"UserDTO": {Name: "UserDTO", Fields: []StructField{
+					{Name: "Id", Type: "int", JSONTag: "id"},
+					{Name: "Email", Type: "string", JSONTag: "email"},
+					{Name: "Age", Type: "int", JSONTag: "age", IsPointer: true},
+					{Name: "CreatedAt", Type: "time.Time", JSONTag: "createdAt", IsPointer: true},
+				}},
+				"UserCreateDTO": {Name: "UserCreateDTO", Fields: []StructField{
+					{Name: "Email", Type: "string", JSONTag: "email"},
+					{Name: "Password", Type: "string", JSONTag: "password"},
+					{Name: "Age", Type: "int", JSONTag: "age", IsPointer: true},
+				}},
+				"UserUpdateDTO": {Name: "UserUpdateDTO", Fields: []StructField{
+					{Name: "Email", Type: "string", JSONTag: "email"},
+					{Name: "Age", Type: "int", JSONTag: "age", IsPointer: true},
+				}},
+			},
+		},
+	}
+
+	return tables, resources
+}
+
+func TestBuildOpenAPIDocumentPaths(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	if doc.OpenAPI != OpenAPIVersion {
+		t.Errorf("Expected openapi version %s, got %s", OpenAPIVersion, doc.OpenAPI)
+	}
+
+	collection, ok := doc.Paths["/users"]
+	if !ok {
+		t.Fatal("Expected /users path")
+	}
+	if collection.Get == nil || collection.Post == nil {
+		t.Error("Expected GET and POST operations on /users")
+	}
+
+	item, ok := doc.Paths["/users/{id}"]
+	if !ok {
+		t.Fatal("Expected /users/{id} path")
+	}
+	if item.Get == nil || item.Put == nil || item.Delete == nil {
+		t.Error("Expected GET, PUT and DELETE operations on /users/{id}")
+	}
+	if len(item.Parameters) != 1 || item.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("Expected integer id path parameter, got %+v", item.Parameters)
+	}
+
+	// Tables without generated DTOs have no endpoints
+	if _, ok := doc.Paths["/audit_logs"]; ok {
+		t.Error("Expected no path for table without DTOs")
+	}
+}
+
+func TestBuildOpenAPIDocumentSchemas(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	for _, name := range []string{"UserDTO", "UserCreateDTO", "UserUpdateDTO", "UserCollection", "Error"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("Expected component schema %s", name)
+		}
+	}
+
+	create := doc.Components.Schemas["UserCreateDTO"]
+	if strings.Join(create.Required, ",") != "email,password" {
+		t.Errorf("Expected non-pointer fields to be required, got %v", create.Required)
+	}
+
+	age := doc.Components.Schemas["UserDTO"].Properties["age"]
+	types, ok := age.Type.([]string)
+	if !ok || len(types) != 2 || types[1] != "null" {
+		t.Errorf("Expected nullable age type, got %v", age.Type)
+	}
+
+	createdAt := doc.Components.Schemas["UserDTO"].Properties["createdAt"]
+	if createdAt.Format != "date-time" {
+		t.Errorf("Expected date-time format for createdAt, got %q", createdAt.Format)
+	}
+}
+
+func TestBuildOpenAPIDocumentListParameters(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	params := make(map[string]*OpenAPIParameter)
+	for _, p := range doc.Paths["/users"].Get.Parameters {
+		key := p.Name
+		if p.Ref != "" {
+			key = p.Ref
+		}
+		params[key] = p
+	}
+
+	expected := []string{
+		"#/components/parameters/Page",
+		"#/components/parameters/Limit",
+		"#/components/parameters/Count",
+		"email",
+		"email[like]",
+		"email[]",
+		"age[gte]",
+		"created_at[lt]",
+		"order",
+	}
+	for _, name := range expected {
+		if _, ok := params[name]; !ok {
+			t.Errorf("Expected list parameter %s", name)
+		}
+	}
+
+	// Write-only and password columns are not filterable
+	if _, ok := params["password"]; ok {
+		t.Error("Expected password not to be filterable")
+	}
+	if _, ok := params["email[gt]"]; ok {
+		t.Error("Expected no range operators on string columns")
+	}
+
+	order := params["order"]
+	if order.Style != "deepObject" {
+		t.Errorf("Expected deepObject order parameter, got %q", order.Style)
+	}
+	if _, ok := order.Schema.Properties["created_at"]; !ok {
+		t.Error("Expected created_at to be orderable")
+	}
+}
+
+func TestBuildOpenAPIDocumentMarshalsToYAML(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	data, err := MarshalOpenAPIDocument(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+
+	content := string(data)
+	expectedStrings := []string{
+		"openapi: 3.1.0",
+		"/users/{id}:",
+		"$ref: '#/components/schemas/UserDTO'",
+		"- \"null\"",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected YAML to contain %q", expected)
+		}
+	}
+}
+
+func TestBuildOpenAPIDocumentCompositeKey(t *testing.T) {
+	tables := map[string]TableSchema{
+		"order_items": {
+			TableName:  "order_items",
+			PrimaryKey: []string{"order_id", "product_id"},
+			Columns: []Column{
+				{Name: "order_id", Type: "integer", IsPrimaryKey: true},
+				{Name: "product_id", Type: "uuid", IsPrimaryKey: true},
+				{Name: "quantity", Type: "integer"},
+			},
+		},
+	}
+	resources := map[string]ResourceDTOs{
+		"orderitem": {
+			Name:       "orderitem",
+			PluralName: "orderitems",
+			DTOs: map[string]DTOSchema{
+				"OrderItemDTO": {Name: "OrderItemDTO", Fields: []StructField{
+					{Name: "OrderId", Type: "int", JSONTag: "orderId"},
+					{Name: "ProductId", Type: "string", JSONTag: "productId"},
+					{Name: "Quantity", Type: "int", JSONTag: "quantity"},
+				}},
+				"OrderItemUpdateDTO": {Name: "OrderItemUpdateDTO", Fields: []StructField{
+					{Name: "Quantity", Type: "int", JSONTag: "quantity"},
+				}},
+			},
+		},
+	}
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	item, ok := doc.Paths["/order_items/{order_id}/{product_id}"]
+	if !ok {
+		t.Fatalf("Expected composite key path, got %v", doc.Paths)
+	}
+	if len(item.Parameters) != 2 {
+		t.Fatalf("Expected 2 key parameters, got %d", len(item.Parameters))
+	}
+	if item.Parameters[0].Name != "order_id" || item.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("Expected integer order_id parameter, got %+v", item.Parameters[0])
+	}
+	if item.Parameters[1].Name != "product_id" || item.Parameters[1].Schema.Type != "string" {
+		t.Errorf("Expected string product_id parameter, got %+v", item.Parameters[1])
+	}
+	if item.Put == nil {
+		t.Error("Expected PUT operation on composite key path")
+	}
+}
+
+func TestBuildOpenAPIDocumentRelations(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	tables["posts"] = TableSchema{
+		TableName: "posts",
+		Columns: []Column{
+			{Name: "id", Type: "integer"},
+			{Name: "author_id", Type: "integer"},
+		},
+		Relations: []Relation{{ChildTable: "posts", ChildColumn: "author_id", ParentTable: "users", ParentColumn: "id"}},
+	}
+	resources["post"] = ResourceDTOs{
+		Name:       "post",
+		PluralName: "posts",
+		DTOs: map[string]DTOSchema{
+			"PostDTO": {Name: "PostDTO", Fields: []StructField{
+				{Name: "Id", Type: "int", JSONTag: "id"},
+				{Name: "AuthorId", Type: "int", JSONTag: "authorId"},
+				{Name: "Author", Type: "UserDTO", JSONTag: "author", IsPointer: true},
+			}},
+		},
+	}
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	nested, ok := doc.Paths["/users/{id}/posts"]
+	if !ok || nested.Get == nil {
+		t.Fatalf("Expected nested posts path, got %v", doc.Paths)
+	}
+	if nested.Get.OperationID != "listPostByAuthor" {
+		t.Errorf("Expected operationId listPostByAuthor, got %s", nested.Get.OperationID)
+	}
+	if len(nested.Parameters) != 1 || nested.Parameters[0].Name != "id" || nested.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("Expected integer id path parameter, got %+v", nested.Parameters)
+	}
+
+	var include *OpenAPIParameter
+	for _, param := range doc.Paths["/posts"].Get.Parameters {
+		if param.Name == "include" {
+			include = param
+		}
+	}
+	if include == nil || len(include.Schema.Items.Enum) != 1 || include.Schema.Items.Enum[0] != "author" {
+		t.Errorf("Expected include parameter listing author, got %+v", include)
+	}
+
+	author := doc.Components.Schemas["PostDTO"].Properties["author"]
+	if author == nil || author.Ref != "#/components/schemas/UserDTO" {
+		t.Errorf("Expected author to reference UserDTO, got %+v", author)
+	}
+}
+
+func TestColumnDefaultValue(t *testing.T) {
+	tests := []struct {
+		expr     string
+		typ      string
+		expected any
+		ok       bool
+	}{
+		{expr: "'draft'::character varying", typ: "string", expected: "draft", ok: true},
+		{expr: "'it''s'", typ: "string", expected: "it's", ok: true},
+		{expr: "0", typ: "integer", expected: int64(0), ok: true},
+		{expr: "('1.5')", typ: "number", expected: 1.5, ok: true},
+		{expr: "true", typ: "boolean", expected: true, ok: true},
+		{expr: "1", typ: "boolean", expected: true, ok: true},
+		{expr: "now()", typ: "string"},
+		{expr: "nextval('users_id_seq'::regclass)", typ: "integer"},
+		{expr: "CURRENT_TIMESTAMP", typ: "string"},
+		{expr: "NULL::text", typ: "string"},
+		{expr: "", typ: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			value, ok := columnDefaultValue(tt.expr, tt.typ)
+			if ok != tt.ok || value != tt.expected {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.expected, tt.ok, value, ok)
+			}
+		})
+	}
+}
+
+func TestBuildOpenAPIDocumentColumnMetadata(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	users := tables["users"]
+	users.Columns[1].MaxLength = 255
+	users.Columns[1].IsUnique = true
+	users.Columns[1].Comment = "Login email"
+	users.Columns[3].Default = "18"
+	tables["users"] = users
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	email := doc.Components.Schemas["UserCreateDTO"].Properties["email"]
+	if email.MaxLength == nil || *email.MaxLength != 255 {
+		t.Errorf("Expected email maxLength 255, got %v", email.MaxLength)
+	}
+	if email.Description != "Login email" {
+		t.Errorf("Expected email description from the column comment, got %q", email.Description)
+	}
+	if age := doc.Components.Schemas["UserDTO"].Properties["age"]; age.Default != int64(18) {
+		t.Errorf("Expected age default 18, got %v", age.Default)
+	}
+
+	var emailIn *OpenAPIParameter
+	for _, param := range doc.Paths["/users"].Get.Parameters {
+		if param.Name == "email[]" {
+			emailIn = param
+		}
+	}
+	if emailIn == nil || !emailIn.Schema.UniqueItems {
+		t.Errorf("Expected unique email[] filter items, got %+v", emailIn)
+	}
+}
+
+func TestBuildOpenAPIDocumentEnumColumn(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	users := tables["users"]
+	users.Columns = append(users.Columns, Column{Name: "role", Type: "text", IsNullable: true, EnumValues: []string{"admin", "member"}})
+	tables["users"] = users
+	dto := resources["user"].DTOs["UserDTO"]
+	dto.Fields = append(dto.Fields, StructField{Name: "Role", Type: "string", JSONTag: "role", IsPointer: true})
+	resources["user"].DTOs["UserDTO"] = dto
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	role := doc.Components.Schemas["UserDTO"].Properties["role"]
+	if len(role.Enum) != 3 || role.Enum[0] != "admin" || role.Enum[1] != "member" || role.Enum[2] != nil {
+		t.Errorf("Expected nullable role enum [admin member null], got %v", role.Enum)
+	}
+	for _, param := range doc.Paths["/users"].Get.Parameters {
+		if param.Name == "role" && len(param.Schema.Enum) != 2 {
+			t.Errorf("Expected role filter enum [admin member], got %v", param.Schema.Enum)
+		}
+	}
+}
+
+func TestBuildOpenAPIDocumentArrayColumn(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	users := tables["users"]
+	users.Columns = append(users.Columns, Column{Name: "tags", Type: "ARRAY", TypeDef: "text[]"})
+	tables["users"] = users
+	dto := resources["user"].DTOs["UserDTO"]
+	dto.Fields = append(dto.Fields,
+		StructField{Name: "Tags", Type: "[]string", JSONTag: "tags"},
+		StructField{Name: "Stamps", Type: "[]*time.Time", JSONTag: "stamps"},
+	)
+	resources["user"].DTOs["UserDTO"] = dto
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	properties := doc.Components.Schemas["UserDTO"].Properties
+	if tags := properties["tags"]; tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" {
+		t.Errorf("Expected tags to be an array of strings, got %+v", tags)
+	}
+	stamps := properties["stamps"]
+	if stamps.Type != "array" || stamps.Items == nil || stamps.Items.Format != "date-time" {
+		t.Fatalf("Expected stamps to be an array of date-times, got %+v", stamps)
+	}
+	if types, ok := stamps.Items.Type.([]string); !ok || len(types) != 2 || types[1] != "null" {
+		t.Errorf("Expected nullable stamps items, got %v", stamps.Items.Type)
+	}
+	for _, param := range doc.Paths["/users"].Get.Parameters {
+		if strings.HasPrefix(param.Name, "tags") {
+			t.Errorf("Expected no filter on the tags array, got %s", param.Name)
+		}
+	}
+}
+
+func TestBuildOpenAPIDocumentNullableArrayColumn(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	users := tables["users"]
+	users.Columns = append(users.Columns,
+		Column{Name: "tags", Type: "ARRAY", TypeDef: "text[]", IsNullable: true},
+		Column{Name: "labels", Type: "ARRAY", TypeDef: "text[]"},
+	)
+	tables["users"] = users
+	for _, name := range []string{"UserDTO", "UserCreateDTO", "UserUpdateDTO"} {
+		dto := resources["user"].DTOs[name]
+		dto.Fields = append(dto.Fields,
+			StructField{Name: "Tags", Type: "[]string", JSONTag: "tags"},
+			StructField{Name: "Labels", Type: "[]string", JSONTag: "labels"},
+		)
+		resources["user"].DTOs[name] = dto
+	}
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	for _, name := range []string{"UserDTO", "UserCreateDTO", "UserUpdateDTO"} {
+		schema := doc.Components.Schemas[name]
+		tags := schema.Properties["tags"]
+		if types, ok := tags.Type.([]string); !ok || len(types) != 2 || types[0] != "array" || types[1] != "null" {
+			t.Errorf("Expected %s tags to be a nullable array, got %v", name, tags.Type)
+		}
+		if tags.Items == nil || tags.Items.Type != "string" {
+			t.Errorf("Expected %s tags to hold strings, got %+v", name, tags.Items)
+		}
+		if labels := schema.Properties["labels"]; labels.Type != "array" {
+			t.Errorf("Expected %s labels to be an array, got %v", name, labels.Type)
+		}
+		for _, required := range schema.Required {
+			if required == "tags" {
+				t.Errorf("Expected the nullable tags to be optional in %s, got %v", name, schema.Required)
+			}
+		}
+	}
+	if required := doc.Components.Schemas["UserCreateDTO"].Required; !strings.Contains(strings.Join(required, ","), "labels") {
+		t.Errorf("Expected the NOT NULL labels to be required on create, got %v", required)
+	}
+}
+
+func TestBuildOpenAPIDocumentValidation(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	dto := resources["user"].DTOs["UserUpdateDTO"]
+	dto.Fields[0].ValidateTag = "required,email"
+	resources["user"].DTOs["UserUpdateDTO"] = dto
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	update := doc.Components.Schemas["UserUpdateDTO"]
+	if update.Properties["email"].Format != "email" {
+		t.Errorf("Expected email format, got %q", update.Properties["email"].Format)
+	}
+	if len(update.Required) != 1 || update.Required[0] != "email" {
+		t.Errorf("Expected required [email], got %v", update.Required)
+	}
+	if _, ok := doc.Components.Schemas["ValidationError"]; !ok {
+		t.Error("Expected ValidationError schema")
+	}
+	if _, ok := doc.Paths["/users/{id}"].Put.Responses["422"]; !ok {
+		t.Error("Expected 422 response on update")
+	}
+	if _, ok := doc.Paths["/users"].Post.Responses["422"]; ok {
+		t.Error("Expected no 422 response on create without validation rules")
+	}
+}
+
+func TestBuildOpenAPIDocumentPatch(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	resources["user"].DTOs["UserPatchDTO"] = DTOSchema{Name: "UserPatchDTO", Fields: []StructField{
+		{Name: "Email", Type: "string", JSONTag: "email", IsPointer: true, ValidateTag: "omitempty,email"},
+	}}
+
+	doc := BuildOpenAPIDocument("example", tables, resources)
+
+	patch := doc.Paths["/users/{id}"].Patch
+	if patch == nil {
+		t.Fatal("Expected patch operation on /users/{id}")
+	}
+	if patch.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/UserPatchDTO" {
+		t.Errorf("Expected UserPatchDTO request body, got %s", patch.RequestBody.Content["application/json"].Schema.Ref)
+	}
+	if _, ok := patch.Responses["422"]; !ok {
+		t.Error("Expected 422 response on patch")
+	}
+	if len(doc.Components.Schemas["UserPatchDTO"].Required) != 0 {
+		t.Errorf("Expected no required patch fields, got %v", doc.Components.Schemas["UserPatchDTO"].Required)
+	}
+}
+
+func TestBuildOpenAPIDocumentWithOptions(t *testing.T) {
+	tables, resources := testOpenAPIInput()
+	resources["auditlog"] = ResourceDTOs{Name: "auditlog", DTOs: map[string]DTOSchema{
+		"AuditLogDTO": {Name: "AuditLogDTO", Fields: []StructField{{Name: "Id", Type: "int", JSONTag: "id"}}},
+	}}
+	opts := TableOptions{
+		Exclude: []string{"audit_*"},
+		Overrides: map[string]TableOverride{
+			"users": {Path: "people", Operations: []string{OperationList, OperationGet}},
+		},
+	}
+
+	doc := BuildOpenAPIDocumentWithOptions("example", tables, resources, opts)
+
+	if _, ok := doc.Components.Schemas["AuditLogDTO"]; ok {
+		t.Error("Expected no schema for an excluded table")
+	}
+	if _, ok := doc.Paths["/users"]; ok {
+		t.Error("Expected the users resource to be served at its overridden path")
+	}
+	collection, ok := doc.Paths["/people"]
+	if !ok {
+		t.Fatal("Expected path /people")
+	}
+	if collection.Get == nil || collection.Post != nil {
+		t.Errorf("Expected only the list operation on /people, got get=%v post=%v", collection.Get != nil, collection.Post != nil)
+	}
+	item := doc.Paths["/people/{id}"]
+	if item == nil || item.Get == nil {
+		t.Fatal("Expected get operation on /people/{id}")
+	}
+	if item.Put != nil || item.Delete != nil {
+		t.Error("Expected no update or delete operation on /people/{id}")
+	}
+}
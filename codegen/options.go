@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options holds the codegen settings read from the codegen section of
+// gorest.yaml that are not part of the gorest configuration schema
+type Options struct {
+	// Templates is a directory of templates overriding the embedded defaults,
+	// relative to the project root
+	Templates string `yaml:"templates"`
+	// Manifest is the path of the manifest of generated files, relative to
+	// the project root. Defaults to ManifestFile next to the models directory.
+	Manifest string `yaml:"manifest"`
+	// Tables selects the tables code is generated for and overrides the
+	// names derived from them
+	Tables TableOptions `yaml:"tables"`
+	// Columns overrides the model fields of columns, keyed by table.column
+	Columns map[string]ColumnOverride `yaml:"columns"`
+	// Types maps database types to Go types, overriding the dialect defaults
+	Types TypeMap `yaml:"types"`
+	// NullableStyle is how nullable columns are typed: pointer, the default,
+	// sql or optional
+	NullableStyle string `yaml:"nullable_style"`
+	// SchemaFiles lists the SQL files, or glob patterns relative to the
+	// project root, the schema is read from instead of the database
+	SchemaFiles []string `yaml:"schema_files"`
+	// Migrations lists the directories, relative to the project root, whose
+	// up migrations are applied to an in-memory SQLite database to read the
+	// schema from instead of the database
+	Migrations []string `yaml:"migrations"`
+	// SchemaSnapshot is the snapshot, relative to the project root, written
+	// by codegen schema dump and read instead of the schema files or database
+	SchemaSnapshot string `yaml:"schema_snapshot"`
+}
+
+// LoadOptions reads the codegen options from gorest.yaml in projectRoot. A
+// missing file yields empty options.
+func LoadOptions(projectRoot string) (*Options, error) {
+	path := filepath.Join(projectRoot, "gorest.yaml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Options{}, nil
+	}
+	if err != nil {
+		return nil, &ConfigError{Op: "read " + path, Err: err}
+	}
+
+	var file struct {
+		Codegen Options `yaml:"codegen"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+	}
+	if err := file.Codegen.Tables.validate(); err != nil {
+		return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+	}
+	if err := validateColumnOverrides(file.Codegen.Columns); err != nil {
+		return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+	}
+	if err := file.Codegen.Types.validate(); err != nil {
+		return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+	}
+	if style := file.Codegen.NullableStyle; style != "" && !slices.Contains(nullableStyles, style) {
+		err := fmt.Errorf("invalid nullable_style %q, expected one of %s", style, strings.Join(nullableStyles, ", "))
+		return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+	}
+	for _, pattern := range file.Codegen.SchemaFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			err = fmt.Errorf("invalid schema file pattern %q: %w", pattern, err)
+			return nil, &ConfigError{Op: "parse codegen options in " + path, Err: err}
+		}
+	}
+	return &file.Codegen, nil
+}
@@ -0,0 +1,218 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		templates string
+	}{
+		{
+			name:      "templates directory",
+			yaml:      "codegen:\n  output:\n    models: generated/models\n  templates: codegen/templates\n",
+			templates: "codegen/templates",
+		},
+		{
+			name:      "no codegen options",
+			yaml:      "server:\n  port: 8000\n",
+			templates: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(tt.yaml), 0644); err != nil {
+				t.Fatalf("Failed to write gorest.yaml: %v", err)
+			}
+
+			opts, err := LoadOptions(dir)
+			if err != nil {
+				t.Fatalf("Failed to load options: %v", err)
+			}
+			if opts.Templates != tt.templates {
+				t.Errorf("Expected templates %q, got %q", tt.templates, opts.Templates)
+			}
+		})
+	}
+}
+
+func TestLoadOptionsWithoutConfigFile(t *testing.T) {
+	opts, err := LoadOptions(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error for a missing gorest.yaml, got %v", err)
+	}
+	if opts.Templates != "" {
+		t.Errorf("Expected empty options, got %+v", opts)
+	}
+}
+
+func TestLoadOptionsInvalidYAML(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte("codegen: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write gorest.yaml: %v", err)
+	}
+
+	_, err := LoadOptions(dir)
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Errorf("Expected ConfigError, got %v", err)
+	}
+}
+
+func TestLoadOptionsTables(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `codegen:
+  tables:
+    include: ["*"]
+    exclude: ["schema_migrations", "_*"]
+    overrides:
+      users:
+        struct: Member
+        path: members
+        operations: [list, get]
+`
+	if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("Failed to write gorest.yaml: %v", err)
+	}
+
+	opts, err := LoadOptions(dir)
+	if err != nil {
+		t.Fatalf("Failed to load options: %v", err)
+	}
+	if !reflect.DeepEqual(opts.Tables.Exclude, []string{"schema_migrations", "_*"}) {
+		t.Errorf("Expected exclude patterns, got %v", opts.Tables.Exclude)
+	}
+	want := TableOverride{Struct: "Member", Path: "members", Operations: []string{"list", "get"}}
+	if got := opts.Tables.Overrides["users"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected override %+v, got %+v", want, got)
+	}
+}
+
+func TestLoadOptionsInvalidTables(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "codegen:\n  tables:\n    overrides:\n      users:\n        operations: [destroy]\n"
+	if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("Failed to write gorest.yaml: %v", err)
+	}
+
+	_, err := LoadOptions(dir)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("Expected a ConfigError for an unknown operation, got %v", err)
+	}
+}
+
+func TestLoadOptionsColumns(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `codegen:
+  columns:
+    users.password: {dto: write, json: pwd, go_type: "sql.NullString"}
+`
+	if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("Failed to write gorest.yaml: %v", err)
+	}
+
+	opts, err := LoadOptions(dir)
+	if err != nil {
+		t.Fatalf("Failed to load options: %v", err)
+	}
+	want := ColumnOverride{GoType: "sql.NullString", JSON: "pwd", DTO: "write"}
+	if got := opts.Columns["users.password"]; got != want {
+		t.Errorf("Expected override %+v, got %+v", want, got)
+	}
+}
+
+func TestLoadOptionsTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "valid", yaml: "codegen:\n  types:\n    numeric: \"github.com/shopspring/decimal.Decimal\"\n    uuid: \"uuid.UUID\"\n"},
+		{name: "unknown package", yaml: "codegen:\n  types:\n    uuid: \"pgtype.UUID\"\n", wantErr: "invalid Go type for database type uuid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(tt.yaml), 0644); err != nil {
+				t.Fatalf("Failed to write gorest.yaml: %v", err)
+			}
+
+			opts, err := LoadOptions(dir)
+			if tt.wantErr != "" {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected ConfigError containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load options: %v", err)
+			}
+			if opts.Types["numeric"] != "github.com/shopspring/decimal.Decimal" || opts.Types["uuid"] != "uuid.UUID" {
+				t.Errorf("Expected configured types, got %v", opts.Types)
+			}
+		})
+	}
+}
+
+func TestLoadOptionsNullableStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "default", yaml: "codegen: {}\n", expected: ""},
+		{name: "sql", yaml: "codegen:\n  nullable_style: sql\n", expected: NullableSQL},
+		{name: "invalid", yaml: "codegen:\n  nullable_style: nulls\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(tt.yaml), 0644); err != nil {
+				t.Fatalf("Failed to write gorest.yaml: %v", err)
+			}
+
+			opts, err := LoadOptions(dir)
+			if tt.wantErr {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || !strings.Contains(err.Error(), "invalid nullable_style") {
+					t.Fatalf("Expected ConfigError for nullable_style, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load options: %v", err)
+			}
+			if opts.NullableStyle != tt.expected {
+				t.Errorf("Expected nullable_style %q, got %q", tt.expected, opts.NullableStyle)
+			}
+		})
+	}
+}
+
+func TestLoadOptionsSchemaFiles(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "codegen:\n  schema_files: [\"db/[schema.sql\"]\n"
+	if err := os.WriteFile(filepath.Join(dir, "gorest.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("Failed to write gorest.yaml: %v", err)
+	}
+
+	_, err := LoadOptions(dir)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || !strings.Contains(err.Error(), "invalid schema file pattern") {
+		t.Fatalf("Expected ConfigError for the schema file pattern, got %v", err)
+	}
+}
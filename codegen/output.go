@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	ChangeCreated   ChangeKind = "created"
+	ChangeUpdated   ChangeKind = "updated"
+	ChangeUnchanged ChangeKind = "unchanged"
+	ChangeRemoved   ChangeKind = "removed"
+)
+
+// FileChange describes one file produced by a generator and how it compares
+// to what was on disk before the run
+type FileChange struct {
+	Path     string
+	Kind     ChangeKind
+	Previous []byte
+	Content  []byte
+}
+
+// Output receives every file the generators produce. In dry-run mode nothing
+// is written or removed: files are kept in memory and served back through
+// ReadFile and ReadDir, so later generation steps see the output of earlier ones.
+// Files whose content did not change are not rewritten, leaving their
+// modification time untouched.
+type Output struct {
+	DryRun bool
+
+	files   map[string]*FileChange
+	ordered []string
+}
+
+func NewOutput(dryRun bool) *Output {
+	return &Output{
+		DryRun: dryRun,
+		files:  make(map[string]*FileChange),
+	}
+}
+
+// WriteFile records a generated file and, unless in dry-run mode, writes it to
+// disk creating parent directories as needed
+func (o *Output) WriteFile(path string, data []byte) error {
+	path = filepath.Clean(path)
+
+	change, seen := o.files[path]
+	if !seen {
+		previous, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			change = &FileChange{Path: path, Kind: ChangeUpdated, Previous: previous}
+		case errors.Is(err, fs.ErrNotExist):
+			change = &FileChange{Path: path, Kind: ChangeCreated}
+		default:
+			return &WriteError{Path: path, Err: err}
+		}
+		o.files[path] = change
+		o.ordered = append(o.ordered, path)
+	}
+
+	removed := change.Kind == ChangeRemoved
+	change.Content = data
+	if change.Kind != ChangeCreated {
+		change.Kind = ChangeUpdated
+		if bytes.Equal(change.Previous, data) {
+			change.Kind = ChangeUnchanged
+		}
+	}
+
+	if o.DryRun || (change.Kind == ChangeUnchanged && !removed) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &WriteError{Path: filepath.Dir(path), Err: err}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return &WriteError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// RemoveFile records the removal of a previously generated file and, unless in
+// dry-run mode, deletes it from disk
+func (o *Output) RemoveFile(path string) error {
+	path = filepath.Clean(path)
+
+	change, seen := o.files[path]
+	if !seen {
+		previous, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return &WriteError{Path: path, Err: err}
+		}
+		change = &FileChange{Path: path, Previous: previous}
+		o.files[path] = change
+		o.ordered = append(o.ordered, path)
+	}
+	change.Kind = ChangeRemoved
+	change.Content = nil
+
+	if o.DryRun {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return &WriteError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// ReadFile returns the content generated during this run, falling back to disk
+func (o *Output) ReadFile(path string) ([]byte, error) {
+	if change, ok := o.files[filepath.Clean(path)]; ok {
+		if change.Kind == ChangeRemoved {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		}
+		return change.Content, nil
+	}
+	return os.ReadFile(path)
+}
+
+// ReadDir returns the sorted names of the regular files in dir, including files
+// generated during this run that are not on disk yet
+func (o *Output) ReadDir(dir string) ([]string, error) {
+	dir = filepath.Clean(dir)
+	names := make(map[string]bool)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !(o.DryRun && errors.Is(err, fs.ErrNotExist)) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+	for path, change := range o.files {
+		if filepath.Dir(path) == dir {
+			names[filepath.Base(path)] = change.Kind != ChangeRemoved
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for name, exists := range names {
+		if exists {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Changes returns the recorded files in the order they were first written
+func (o *Output) Changes() []FileChange {
+	changes := make([]FileChange, 0, len(o.ordered))
+	for _, path := range o.ordered {
+		changes = append(changes, *o.files[path])
+	}
+	return changes
+}
+
+// Paths returns the paths of the recorded files of the given kind, relative to
+// root when possible
+func (o *Output) Paths(root string, kind ChangeKind) []string {
+	var paths []string
+	for _, change := range o.Changes() {
+		if change.Kind == kind {
+			paths = append(paths, relativePath(root, change.Path))
+		}
+	}
+	return paths
+}
+
+// HasChanges reports whether any recorded file differs from what is on disk
+func (o *Output) HasChanges() bool {
+	for _, change := range o.files {
+		if change.Kind != ChangeUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns a unified diff of every created, updated or removed file against
+// its previous content, with paths shown relative to root
+func (o *Output) Diff(root string) string {
+	var b strings.Builder
+	for _, change := range o.Changes() {
+		if change.Kind == ChangeUnchanged {
+			continue
+		}
+		b.WriteString(UnifiedDiff(relativePath(root, change.Path), change.Previous, change.Content))
+	}
+	return b.String()
+}
+
+func relativePath(root, path string) string {
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,147 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputDryRunDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models", "user.go")
+
+	out := NewOutput(true)
+	if err := out.WriteFile(path, []byte("package models\n")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be written in dry-run mode", path)
+	}
+
+	src, err := out.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read pending file: %v", err)
+	}
+	if string(src) != "package models\n" {
+		t.Errorf("Expected pending content to be served, got %q", src)
+	}
+
+	names, err := out.ReadDir(filepath.Join(dir, "models"))
+	if err != nil {
+		t.Fatalf("Failed to read pending directory: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"user.go"}) {
+		t.Errorf("Expected [user.go], got %v", names)
+	}
+}
+
+func TestOutputChangeKinds(t *testing.T) {
+	dir := t.TempDir()
+	unchanged := filepath.Join(dir, "unchanged.go")
+	updated := filepath.Join(dir, "updated.go")
+	created := filepath.Join(dir, "created.go")
+
+	for path, content := range map[string]string{unchanged: "same\n", updated: "old\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to seed %s: %v", path, err)
+		}
+	}
+
+	out := NewOutput(true)
+	for path, content := range map[string]string{unchanged: "same\n", updated: "new\n", created: "new\n"} {
+		if err := out.WriteFile(path, []byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		kind     ChangeKind
+		expected []string
+	}{
+		{ChangeCreated, []string{"created.go"}},
+		{ChangeUpdated, []string{"updated.go"}},
+		{ChangeUnchanged, []string{"unchanged.go"}},
+	}
+	for _, tt := range tests {
+		if got := out.Paths(dir, tt.kind); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Expected %s paths %v, got %v", tt.kind, tt.expected, got)
+		}
+	}
+
+	if !out.HasChanges() {
+		t.Error("Expected output to report changes")
+	}
+}
+
+func TestOutputWritesToDisk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "file.go")
+
+	out := NewOutput(false)
+	if err := out.WriteFile(path, []byte("content\n")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected file to be written: %v", err)
+	}
+	if string(src) != "content\n" {
+		t.Errorf("Expected written content %q, got %q", "content\n", src)
+	}
+}
+
+func TestOutputSkipsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.go")
+	if err := os.WriteFile(path, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("Failed to seed file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	out := NewOutput(false)
+	if err := out.WriteFile(path, []byte("content\n")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("Expected unchanged file not to be rewritten, modification time is %v", info.ModTime())
+	}
+}
+
+func TestOutputRemoveFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.go")
+	if err := os.WriteFile(path, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("Failed to seed file: %v", err)
+	}
+
+	out := NewOutput(false)
+	if err := out.RemoveFile(path); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected file to be removed from disk")
+	}
+	if _, err := out.ReadFile(path); !os.IsNotExist(err) {
+		t.Errorf("Expected removed file not to be readable, got %v", err)
+	}
+	if got := out.Paths(dir, ChangeRemoved); !reflect.DeepEqual(got, []string{"file.go"}) {
+		t.Errorf("Expected removed [file.go], got %v", got)
+	}
+	if diff := out.Diff(dir); !strings.Contains(diff, "+++ /dev/null") {
+		t.Errorf("Expected removal diff, got:\n%s", diff)
+	}
+}
@@ -0,0 +1,183 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ForeignKey is a belongs-to relation from a column to the single column
+// primary key of a parent table
+type ForeignKey struct {
+	// Name names the relation, usually after the column without its _id
+	// suffix: author_id → author
+	Name         string
+	Column       string
+	ParentTable  string
+	ParentColumn string
+}
+
+// ForeignKeys returns the foreign keys of the table referencing the primary
+// key of a table in tables, in column order. Introspected relations also list
+// the columns of primary key and unique constraints, and composite foreign keys
+// as a cross product of their columns; those are left out.
+func (t TableSchema) ForeignKeys(tables map[string]TableSchema) []ForeignKey {
+	taken := make(map[string]bool, len(t.Columns))
+	for _, col := range t.Columns {
+		taken[col.Name] = true
+	}
+
+	var keys []ForeignKey
+	for _, col := range t.Columns {
+		for _, rel := range t.Relations {
+			if rel.ChildColumn != col.Name {
+				continue
+			}
+			parent, ok := tables[rel.ParentTable]
+			if !ok {
+				continue
+			}
+			parentKey := parent.KeyColumns()
+			if len(parentKey) != 1 || parentKey[0] != rel.ParentColumn {
+				continue
+			}
+			if rel.ParentTable == t.TableName &&
+				(rel.ChildColumn == rel.ParentColumn || slices.Contains(t.KeyColumns(), rel.ChildColumn)) {
+				continue
+			}
+
+			name := relationName(col.Name, rel.ParentTable, taken)
+			taken[name] = true
+			keys = append(keys, ForeignKey{
+				Name:         name,
+				Column:       col.Name,
+				ParentTable:  rel.ParentTable,
+				ParentColumn: rel.ParentColumn,
+			})
+			break
+		}
+	}
+	return keys
+}
+
+// relationName picks a relation name for a foreign key column which does not
+// clash with the taken column and relation names
+func relationName(column, parentTable string, taken map[string]bool) string {
+	parent := singularize(parentTable)
+	candidates := []string{parent, column + "_" + parent}
+	if name := strings.TrimSuffix(column, "_id"); name != column && name != "" {
+		candidates = append([]string{name}, candidates...)
+	}
+	for _, name := range candidates {
+		if !taken[name] {
+			return name
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// nestedRouteSegment returns the last path segment of the nested route listing
+// the children of a parent, like posts in /users/:id/posts. When a child has
+// several relations to the same parent, the segment is prefixed with the
+// foreign key column: /users/:id/reviewer_posts.
+func nestedRouteSegment(childPlural, column string, ambiguous bool) string {
+	if !ambiguous {
+		return childPlural
+	}
+	return strings.TrimSuffix(column, "_id") + "_" + childPlural
+}
+
+// modelRelation is a belongs-to relation declared on a model by a relation
+// field tagged rel:"<column>", the column itself being tagged fk:"table.column"
+type modelRelation struct {
+	Field        StructField
+	ForeignKey   StructField
+	ParentStruct string
+	ParentColumn string
+	ParentKey    StructField
+	// ParentPath is the route path of the parent resource
+	ParentPath string
+}
+
+// modelRelations returns the relations declared by the fields of a model.
+// Relations to excluded tables or to models that do not exist are ignored.
+func (g *Generator) modelRelations(fields []StructField) ([]modelRelation, error) {
+	opts := g.tableOptions()
+	var relations []modelRelation
+	for _, field := range fields {
+		if field.Relation == "" {
+			continue
+		}
+		idx := slices.IndexFunc(fields, func(f StructField) bool { return f.DBTag == field.Relation })
+		if idx < 0 {
+			continue
+		}
+		foreignKey := fields[idx]
+		parentTable, parentColumn, ok := strings.Cut(foreignKey.ForeignKey, ".")
+		if !ok || !opts.Includes(parentTable) {
+			continue
+		}
+
+		parentStruct := opts.StructName(parentTable)
+		parentFields, err := g.modelFields(parentStruct)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keyIdx := slices.IndexFunc(parentFields, func(f StructField) bool { return f.DBTag == parentColumn })
+		if keyIdx < 0 {
+			continue
+		}
+
+		relations = append(relations, modelRelation{
+			Field:        field,
+			ForeignKey:   foreignKey,
+			ParentStruct: parentStruct,
+			ParentColumn: parentColumn,
+			ParentKey:    parentFields[keyIdx],
+			ParentPath:   opts.Path(parentTable),
+		})
+	}
+	return relations, nil
+}
+
+// relationTemplateData returns the template data of the relations of a model
+// whose resource is served at childPath
+func relationTemplateData(childPath string, relations []modelRelation) []RelationTemplateData {
+	parents := make(map[string]int, len(relations))
+	for _, rel := range relations {
+		parents[rel.ParentStruct]++
+	}
+
+	result := make([]RelationTemplateData, len(relations))
+	for i, rel := range relations {
+		includeName := rel.Field.JSONTag
+		if includeName == "" {
+			includeName = toJSONCamelCase(rel.Field.Name)
+		}
+
+		segment := nestedRouteSegment(path.Base(childPath), rel.ForeignKey.DBTag, parents[rel.ParentStruct] > 1)
+
+		result[i] = RelationTemplateData{
+			Name:                 rel.Field.Name,
+			IncludeName:          includeName,
+			Field:                rel.ForeignKey.Name,
+			Column:               rel.ForeignKey.DBTag,
+			FieldIsPointer:       rel.ForeignKey.IsPointer,
+			FieldNullType:        rel.ForeignKey.NullType,
+			ParentStruct:         rel.ParentStruct,
+			ParentColumn:         rel.ParentColumn,
+			ParentField:          rel.ParentKey.Name,
+			ParentFieldIsPointer: rel.ParentKey.IsPointer,
+			ParentSwaggerType:    newKeyTemplateField(rel.ParentKey).SwaggerType,
+			Handler:              "ListBy" + rel.Field.Name,
+			Path:                 rel.ParentPath + "/:" + rel.ParentColumn + "/" + segment,
+			DocPath:              rel.ParentPath + "/{" + rel.ParentColumn + "}/" + segment,
+		}
+	}
+	return result
+}
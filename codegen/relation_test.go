@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestTableSchemaForeignKeys(t *testing.T) {
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{{Name: "id"}}},
+		"categories": {
+			TableName:  "categories",
+			PrimaryKey: []string{"id"},
+			Columns:    []Column{{Name: "id"}, {Name: "parent_id"}},
+			Relations: []Relation{
+				{ChildTable: "categories", ChildColumn: "id", ParentTable: "categories", ParentColumn: "id"},
+				{ChildTable: "categories", ChildColumn: "parent_id", ParentTable: "categories", ParentColumn: "id"},
+			},
+		},
+		"posts": {
+			TableName: "posts",
+			Columns:   []Column{{Name: "id"}, {Name: "owner"}, {Name: "author_id"}, {Name: "editor"}, {Name: "legacy_id"}},
+			Relations: []Relation{
+				{ChildTable: "posts", ChildColumn: "author_id", ParentTable: "users", ParentColumn: "id"},
+				{ChildTable: "posts", ChildColumn: "owner", ParentTable: "users", ParentColumn: "id"},
+				{ChildTable: "posts", ChildColumn: "editor", ParentTable: "users", ParentColumn: "id"},
+				{ChildTable: "posts", ChildColumn: "legacy_id", ParentTable: "archives", ParentColumn: "id"},
+			},
+		},
+		"order_items": {
+			TableName:  "order_items",
+			PrimaryKey: []string{"order_id", "product_id"},
+			Columns:    []Column{{Name: "order_id"}, {Name: "product_id"}},
+			Relations: []Relation{
+				{ChildTable: "order_items", ChildColumn: "order_id", ParentTable: "order_items", ParentColumn: "product_id"},
+				{ChildTable: "order_items", ChildColumn: "product_id", ParentTable: "order_items", ParentColumn: "order_id"},
+			},
+		},
+	}
+
+	tests := []struct {
+		table    string
+		expected []string
+	}{
+		{table: "users", expected: nil},
+		{table: "categories", expected: []string{"parent:parent_id->categories.id"}},
+		{table: "posts", expected: []string{
+			"user:owner->users.id",
+			"author:author_id->users.id",
+			"editor_user:editor->users.id",
+		}},
+		{table: "order_items", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			var keys []string
+			for _, fk := range tables[tt.table].ForeignKeys(tables) {
+				keys = append(keys, fk.Name+":"+fk.Column+"->"+fk.ParentTable+"."+fk.ParentColumn)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected foreign keys %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestGenerateResourceWithRelations(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = t.TempDir()
+	g := NewGenerator(cfg, NewOutput(true))
+
+	userModel := "package models\n\ntype User struct {\n\tId string `json:\"id,omitempty\" db:\"id\" pk:\"true\"`\n}\n"
+	if err := g.Output.WriteFile(filepath.Join(cfg.Codegen.Output.Models, "user.go"), []byte(userModel)); err != nil {
+		t.Fatalf("Failed to write parent model: %v", err)
+	}
+
+	fields := []StructField{
+		{Name: "Id", Type: "int", DBTag: "id", IsPrimaryKey: true},
+		{Name: "AuthorId", Type: "string", DBTag: "author_id", IsPointer: true, ForeignKey: "users.id"},
+		{Name: "CategoryId", Type: "int", DBTag: "category_id", ForeignKey: "categories.id"},
+		{Name: "Author", Type: "User", JSONTag: "author", DTOTag: "-", IsPointer: true, Relation: "author_id"},
+		{Name: "Category", Type: "Category", JSONTag: "category", DTOTag: "-", IsPointer: true, Relation: "category_id"},
+	}
+
+	code, err := g.generateResourceFromModel("Post", fields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+
+	expected := []string{
+		`router.Get("/users/:id/posts", res.ListByAuthor)`,
+		`return r.list(c, query.Eq("author_id", c.Params("id")))`,
+		`// @Router /users/{id}/posts [get]`,
+		`case "author":`,
+		`includes, err := r.parseIncludes(c.Query("include"))`,
+		`query.In("id", keys...)`,
+		`dtoItems[i].Author = &parent`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected resource to contain %q", want)
+		}
+	}
+	// Category has no model, so its relation is left out
+	if strings.Contains(code, "ListByCategory") || strings.Contains(code, `"category"`) {
+		t.Error("Expected relation to a missing model to be ignored")
+	}
+
+	dto, err := g.generateDTOsFromModel("Post", fields)
+	if err != nil {
+		t.Fatalf("Failed to generate DTOs: %v", err)
+	}
+	if !strings.Contains(dto, "Author *UserDTO `json:\"author,omitempty\"`") {
+		t.Errorf("Expected DTO to embed the author, got:\n%s", dto)
+	}
+}
@@ -1,82 +1,126 @@
 package codegen
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-func generateRouteWithAuth(method, path, handler, resource, httpMethod string, authCfg *AuthConfig) string {
-	requiresAuth := authCfg != nil && authCfg.RequiresAuth(resource, httpMethod)
+func (g *Generator) generateResourceForStruct(apiDir string, structName string, authCfg *AuthConfig) error {
+	resourceFile := filepath.Join(apiDir, strings.ToLower(structName)+".go")
 
-	if requiresAuth {
-		return fmt.Sprintf(`
-	if authMiddleware != nil {
-		router.%s("/%s", authMiddleware, %s)
-	} else {
-		router.%s("/%s", %s)
-	}
-`, method, path, handler, method, path, handler)
+	fields, err := g.modelFields(structName)
+	if err != nil {
+		return err
 	}
+	g.sqlNullFields = g.sqlNullFields || usesSQLNull(fields)
 
-	return fmt.Sprintf(`	router.%s("/%s", %s)
-`, method, path, handler)
+	code, err := g.generateResourceFromModel(structName, fields, authCfg)
+	if err != nil {
+		return err
+	}
+	written, err := g.writeGenerated(resourceFile, []byte(code))
+	if err != nil {
+		return err
+	}
+	if written && !g.Output.DryRun {
+		log.Printf("🧩 Generated API resource for model: %s → %s", structName, resourceFile)
+	}
+	return nil
 }
 
-func generateResourceForStruct(apiDir string, structName string, authCfg *AuthConfig) {
-	resourceFile := filepath.Join(apiDir, strings.ToLower(structName)+".go")
-
-	cfg, _ := LoadConfig()
-	projectRoot, _ := findProjectRoot()
-	modelsDir := cfg.Codegen.Output.Models
-	if !filepath.IsAbs(modelsDir) {
-		modelsDir = filepath.Join(projectRoot, modelsDir)
+func (g *Generator) generateResourceFromModel(structName string, fields []StructField, authCfg *AuthConfig) (string, error) {
+	lowerStructName := strings.ToLower(structName)
+	table, err := g.modelTable(structName)
+	if err != nil {
+		return "", err
 	}
-	modelPath := filepath.Join(modelsDir, strings.ToLower(structName)+".go")
-	fields := extractStructFields(modelPath, structName)
+	opts := g.tableOptions()
+	pluralResourceName := opts.Path(table)
+	operations := opts.operations(table)
 
-	code := generateResourceFromModel(structName, fields, authCfg)
-	if err := os.WriteFile(resourceFile, []byte(code), 0644); err != nil {
-		log.Fatalf("failed to write resource for %s: %v", structName, err)
+	modelRelations, err := g.modelRelations(fields)
+	if err != nil {
+		return "", err
 	}
-	log.Printf("🧩 Generated API resource for model: %s → %s", structName, resourceFile)
-}
+	relations := relationTemplateData(pluralResourceName, modelRelations)
 
-func generateResourceFromModel(structName string, fields []StructField, authCfg *AuthConfig) string {
-	resourceName := strings.ToLower(structName)
-	lowerStructName := strings.ToLower(structName)
-	pluralResourceName := Pluralize(resourceName)
+	keyFields := primaryKeyFields(fields)
+	defaultKey := isDefaultKey(keyFields)
 
-	// Generate routes with conditional auth middleware
-	listRoute := generateRouteWithAuth("Get", pluralResourceName, "res.List", pluralResourceName, "GET", authCfg)
-	getRoute := generateRouteWithAuth("Get", pluralResourceName+"/:id", "res.Get", pluralResourceName, "GET", authCfg)
-	postRoute := generateRouteWithAuth("Post", pluralResourceName, "res.Create", pluralResourceName, "POST", authCfg)
-	putRoute := generateRouteWithAuth("Put", pluralResourceName+"/:id", "res.Update", pluralResourceName, "PUT", authCfg)
-	deleteRoute := generateRouteWithAuth("Delete", pluralResourceName+"/:id", "res.Delete", pluralResourceName, "DELETE", authCfg)
+	var key []KeyTemplateField
+	var keyPath, keyDocPath []string
+	for _, field := range keyFields {
+		key = append(key, newKeyTemplateField(field))
+		keyPath = append(keyPath, ":"+field.DBTag)
+		keyDocPath = append(keyDocPath, "{"+field.DBTag+"}")
+	}
 
-	needsAuthContext := authCfg != nil && (authCfg.RequiresAuth(pluralResourceName, "GET") ||
-		authCfg.RequiresAuth(pluralResourceName, "POST") ||
-		authCfg.RequiresAuth(pluralResourceName, "PUT") ||
-		authCfg.RequiresAuth(pluralResourceName, "DELETE"))
+	var insertColumns, updateColumns []TemplateField
+	for _, field := range fields {
+		if field.DBTag == "" || field.IsPrimaryKey || field.DBTag == FieldCreatedAt {
+			continue
+		}
+		updateColumns = append(updateColumns, newTemplateField(field))
+		if field.DBTag != FieldUpdatedAt {
+			insertColumns = append(insertColumns, newTemplateField(field))
+		}
+	}
 
-	routesSignature := "router fiber.Router, db database.Database, paginationLimit, paginationMaxLimit int, pluginRegistry *plugin.PluginRegistry"
+	// PATCH updates the columns present in the body of an existing row
+	var patchFields []TemplateField
+	if len(key) > 0 {
+		patchFields = patchDTOFields(fields)
+	}
+	hasNullablePatchFields := slices.ContainsFunc(patchFields, func(f TemplateField) bool { return f.Nullable })
+	hasUpdatedAt := slices.ContainsFunc(fields, func(f StructField) bool { return f.DBTag == FieldUpdatedAt })
 
-	authMiddlewareSetup := ""
-	if needsAuthContext {
-		authMiddlewareSetup = `
-	var authMiddleware fiber.Handler
-	if authPlugin, ok := pluginRegistry.Get("auth"); ok {
-		authMiddleware = authPlugin.Handler()
+	// Generate routes with conditional auth middleware, for the operations
+	// the resource exposes. Nested routes are list operations.
+	var routes []RouteTemplateData
+	if operations.List {
+		routes = append(routes, RouteTemplateData{Method: "Get", Path: pluralResourceName, Handler: "res.List"})
+	}
+	if len(key) > 0 {
+		itemPath := pluralResourceName + "/" + strings.Join(keyPath, "/")
+		if operations.Get {
+			routes = append(routes, RouteTemplateData{Method: "Get", Path: itemPath, Handler: "res.Get"})
+		}
+		if operations.Create {
+			routes = append(routes, RouteTemplateData{Method: "Post", Path: pluralResourceName, Handler: "res.Create"})
+		}
+		if operations.Update && (defaultKey || len(updateColumns) > 0) {
+			routes = append(routes, RouteTemplateData{Method: "Put", Path: itemPath, Handler: "res.Update"})
+		}
+		if operations.Patch && len(patchFields) > 0 {
+			routes = append(routes, RouteTemplateData{Method: "Patch", Path: itemPath, Handler: "res.Patch"})
+		}
+		if operations.Delete {
+			routes = append(routes, RouteTemplateData{Method: "Delete", Path: itemPath, Handler: "res.Delete"})
+		}
+	}
+	if operations.List {
+		for _, rel := range relations {
+			routes = append(routes, RouteTemplateData{Method: "Get", Path: rel.Path, Handler: "res." + rel.Handler})
+		}
 	}
-`
+	needsAuthContext := false
+	for i, route := range routes {
+		httpMethod := strings.ToUpper(route.Method)
+		routes[i].RequiresAuth = authCfg != nil && authCfg.RequiresAuth(pluralResourceName, httpMethod)
+		if routes[i].RequiresAuth {
+			needsAuthContext = true
+		}
 	}
 
 	hasUserIdField := false
+	var userIDField TemplateField
 	for _, field := range fields {
-		if field.Name == "UserId" {
+		if isAuthUserIDField(field) {
 			hasUserIdField = true
+			userIDField = newTemplateField(field)
 			break
 		}
 	}
@@ -86,30 +130,23 @@ func generateResourceFromModel(structName string, fields []StructField, authCfg
 		contextFunc = "auth.Context(c)"
 	}
 
-	userIdAutoPopulate := ""
-	if hasUserIdField {
-		userIdAutoPopulate = `
-	// Auto-populate user_id from authenticated user
-	if user := auth.GetAuthenticatedUser(c); user != nil {
-		item.UserId = &user.UserID
-	}
-`
-	}
-
-	conversionFuncs := generateConversionFunctions(structName, fields)
-
 	var allowedFieldsList []string
 	for _, field := range fields {
 		if field.DBTag != "" {
 			if field.DBTag == "password" || field.DTOTag == "write" {
 				continue
 			}
-			allowedFieldsList = append(allowedFieldsList, fmt.Sprintf(`"%s"`, field.DBTag))
+			allowedFieldsList = append(allowedFieldsList, field.DBTag)
 		}
 	}
-	allowedFieldsStr := strings.Join(allowedFieldsList, ", ")
 
-	projectRoot, _ := findProjectRoot()
+	createFields := createDTOFields(fields)
+	updateFields := updateDTOFields(fields)
+
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return "", &ConfigError{Op: "find project root", Err: err}
+	}
 	hookFilePath := filepath.Join(projectRoot, "hooks", lowerStructName+".go")
 	hasHooks := false
 	if _, err := os.Stat(hookFilePath); err == nil {
@@ -117,327 +154,112 @@ func generateResourceFromModel(structName string, fields []StructField, authCfg
 	}
 
 	moduleName := getModuleName()
-	cfg, _ := LoadConfig()
-	_, _ = findProjectRoot()
+	modelsImport, dtosImport := g.packageImports()
 
-	modelsImport := moduleName
-	dtosImport := moduleName
-
-	if cfg.Codegen.Output.Models != "models" && cfg.Codegen.Output.Models != "" {
-		modelsPath := strings.TrimPrefix(cfg.Codegen.Output.Models, "./")
-		modelsImport = moduleName + "/" + strings.ReplaceAll(modelsPath, string(filepath.Separator), "/")
-	}
-	if cfg.Codegen.Output.DTOs != "dtos" && cfg.Codegen.Output.DTOs != "" {
-		dtosPath := strings.TrimPrefix(cfg.Codegen.Output.DTOs, "./")
-		dtosImport = moduleName + "/" + strings.ReplaceAll(dtosPath, string(filepath.Separator), "/")
-	}
-
-	modelsImport = strings.TrimSuffix(modelsImport, "/models") + "/models"
-	dtosImport = strings.TrimSuffix(dtosImport, "/dtos") + "/dtos"
-
-	importsSection := fmt.Sprintf(`import (
-	"net/url"
-
-	"%s"
-	"%s"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/nicolasbonnici/gorest/crud"
-	"github.com/nicolasbonnici/gorest/database"
-	"github.com/nicolasbonnici/gorest/filter"
-	"github.com/nicolasbonnici/gorest/logger"
-	"github.com/nicolasbonnici/gorest/pagination"
-	"github.com/nicolasbonnici/gorest/plugin"
-	"github.com/nicolasbonnici/gorest/response"`, dtosImport, modelsImport)
-
-	if needsAuthContext || hasUserIdField {
-		importsSection += `
-	auth "github.com/nicolasbonnici/gorest-auth"`
-	}
-	if hasHooks {
-		hooksImport := moduleName + "/hooks"
-		importsSection += fmt.Sprintf(`
-	"%s"`, hooksImport)
-	}
-	importsSection += `
-)`
-
-	crudInit := fmt.Sprintf("crud.New[models.%s](db)", structName)
+	hooksImport := ""
 	if hasHooks {
-		crudInit = fmt.Sprintf("crud.NewWithHooks[models.%s](db, &hooks.%sHooks{})", structName, structName)
-	}
-
-	return fmt.Sprintf(`// Code generated by GoREST. DO NOT EDIT.
-
-package resources
-
-%s
-
-type %sResource struct {
-	DB                 database.Database
-	CRUD               *crud.CRUD[models.%s]
-	PaginationLimit    int
-	PaginationMaxLimit int
-}
-
-func Register%sRoutes(%s) {
-	res := &%sResource{
-		DB:                 db,
-		CRUD:               %s,
-		PaginationLimit:    paginationLimit,
-		PaginationMaxLimit: paginationMaxLimit,
-	}
-%s%s%s%s%s%s
-}
-
-%s
-
-// List %s
-// @Summary List %s
-// @Tags %s
-// @Produce json,application/ld+json
-// @Success 200 {object} pagination.HydraCollection
-// @Router /%s [get]
-func (r *%sResource) List(c *fiber.Ctx) error {
-	limit := pagination.ParseIntQuery(c, "limit", r.PaginationLimit, r.PaginationMaxLimit)
-	page := pagination.ParseIntQuery(c, "page", 1, 10000)
-	if page < 1 {
-		page = 1
-	}
-	offset := (page - 1) * limit
-	includeCount := c.Query("count", "true") != "false"
-
-	allowedFields := []string{%s}
-
-	queryParams := make(url.Values)
-	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
-		queryParams.Add(string(key), string(value))
+		hooksImport = moduleName + "/hooks"
+	}
+
+	return g.renderTemplate(ResourceTemplate, ResourceTemplateData{
+		StructName:             structName,
+		LowerStructName:        lowerStructName,
+		PluralName:             pluralResourceName,
+		ModelsImport:           modelsImport,
+		DTOsImport:             dtosImport,
+		HooksImport:            hooksImport,
+		NeedsAuthMiddleware:    needsAuthContext,
+		UsesAuthContext:        needsAuthContext || hasUserIdField,
+		HasUserID:              hasUserIdField,
+		UserIDField:            userIDField,
+		UsesSQLNull:            usesSQLNull(fields),
+		Context:                contextFunc,
+		Routes:                 routes,
+		Operations:             operations,
+		AllowedFields:          allowedFieldsList,
+		DTOFields:              readDTOFields(fields),
+		CreateFields:           createFields,
+		UpdateFields:           updateFields,
+		ValidateCreate:         hasValidation(createFields),
+		ValidateUpdate:         hasValidation(updateFields),
+		PatchFields:            patchFields,
+		ValidatePatch:          hasValidation(patchFields),
+		HasNullablePatchFields: hasNullablePatchFields,
+		HasUpdatedAt:           hasUpdatedAt,
+		Key:                    key,
+		DefaultKey:             defaultKey,
+		KeyPath:                strings.Join(keyPath, "/"),
+		KeyDocPath:             strings.Join(keyDocPath, "/"),
+		AutoIncrementKey:       len(key) == 1 && isIntegerType(keyFields[0].Type) && !keyFields[0].IsPointer,
+		InsertColumns:          insertColumns,
+		UpdateColumns:          updateColumns,
+		Relations:              relations,
 	})
-
-	// Parse filters into conditions
-	filters := filter.NewFilterSet(allowedFields, r.DB.Dialect())
-	if err := filters.ParseFromQuery(queryParams); err != nil {
-		return pagination.SendPaginatedError(c, 400, err.Error())
-	}
-	conditions := filters.Conditions()
-
-	// Parse ordering into OrderBy clauses
-	ordering := filter.NewOrderSet(allowedFields)
-	if err := ordering.ParseFromQuery(queryParams); err != nil {
-		return pagination.SendPaginatedError(c, 400, err.Error())
-	}
-	orderClauses := ordering.OrderClauses()
-
-	// Convert filter.OrderClause to crud.OrderByClause
-	orderBy := make([]crud.OrderByClause, len(orderClauses))
-	for i, oc := range orderClauses {
-		orderBy[i] = crud.OrderByClause{
-			Column:    oc.Column,
-			Direction: oc.Direction,
-		}
-	}
-
-	result, err := r.CRUD.GetAllPaginated(%s, crud.PaginationOptions{
-		Limit:        limit,
-		Offset:       offset,
-		IncludeCount: includeCount,
-		Conditions:   conditions,
-		OrderBy:      orderBy,
-	})
-	if err != nil {
-		return pagination.SendPaginatedError(c, 500, err.Error())
-	}
-
-	dtoItems := make([]dtos.%sDTO, len(result.Items))
-	for i, item := range result.Items {
-		dtoItems[i] = modelTo%sDTO(item)
-	}
-
-	return pagination.SendHydraCollection(c, dtoItems, result.Total, limit, page, r.PaginationLimit)
-}
-
-// Get %s by ID
-// @Summary Get %s
-// @Tags %s
-// @Produce json,application/ld+json
-// @Param id path int true "ID"
-// @Success 200 {object} dtos.%sDTO
-// @Router /%s/{id} [get]
-func (r *%sResource) Get(c *fiber.Ctx) error {
-	id := c.Params("id")
-	item, err := r.CRUD.GetByID(%s, id)
-	if err != nil {
-		if crud.IsInvalidIDError(err) {
-			return response.SendError(c, 400, err.Error())
-		}
-		return response.SendError(c, 404, "Not found")
-	}
-
-	dto := modelTo%sDTO(*item)
-	return response.SendFormatted(c,200, dto)
 }
 
-// Create %s
-// @Summary Create %s
-// @Tags %s
-// @Accept json
-// @Produce json,application/ld+json
-// @Param input body dtos.%sCreateDTO true "New %s"
-// @Success 201 {object} dtos.%sDTO
-// @Router /%s [post]
-func (r *%sResource) Create(c *fiber.Ctx) error {
-	var createDTO dtos.%sCreateDTO
-	if err := c.BodyParser(&createDTO); err != nil {
-		logger.Log.Error("Failed to parse request body", "error", err, "path", c.Path())
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+func newKeyTemplateField(field StructField) KeyTemplateField {
+	typeStr := field.Type
+	if field.IsPointer {
+		typeStr = "*" + typeStr
 	}
 
-	item := %sCreateDTOToModel(createDTO)
-%s
-	ctx := %s
-	if err := r.CRUD.Create(ctx, item); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	swaggerType := "string"
+	if isIntegerType(field.Type) {
+		swaggerType = "int"
 	}
 
-	created, err := r.CRUD.GetByID(ctx, item.Id)
-	if err != nil {
-		dto := modelTo%sDTO(item)
-		return response.SendFormatted(c,201, dto)
+	description := field.DBTag
+	if strings.ToLower(field.DBTag) == FieldID {
+		description = "ID"
 	}
 
-	dto := modelTo%sDTO(*created)
-	return response.SendFormatted(c,201, dto)
-}
-
-// Update %s
-// @Summary Update %s
-// @Tags %s
-// @Accept json
-// @Produce json,application/ld+json
-// @Param id path int true "ID"
-// @Param input body dtos.%sUpdateDTO true "Updated %s"
-// @Success 200 {object} dtos.%sDTO
-// @Router /%s/{id} [put]
-func (r *%sResource) Update(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var updateDTO dtos.%sUpdateDTO
-	if err := c.BodyParser(&updateDTO); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	zero := ""
+	switch {
+	case field.IsPointer:
+		zero = "nil"
+	case field.Type == "string":
+		zero = `""`
+	case isIntegerType(field.Type) || strings.HasPrefix(field.Type, "float"):
+		zero = "0"
 	}
 
-	item := %sUpdateDTOToModel(updateDTO)
-%s
-	if err := r.CRUD.Update(%s, id, item); err != nil {
-		if crud.IsInvalidIDError(err) {
-			return response.SendError(c, 400, err.Error())
-		}
-		if crud.IsNotFoundError(err) {
-			return response.SendError(c, 404, "Not found")
-		}
-		return response.SendError(c, 500, err.Error())
+	return KeyTemplateField{
+		Name:        field.Name,
+		Column:      field.DBTag,
+		Type:        typeStr,
+		SwaggerType: swaggerType,
+		Description: description,
+		Zero:        zero,
 	}
-
-	dto := modelTo%sDTO(item)
-	return response.SendFormatted(c,200, dto)
 }
 
-// Delete %s
-// @Summary Delete %s
-// @Tags %s
-// @Param id path int true "ID"
-// @Success 204
-// @Router /%s/{id} [delete]
-func (r *%sResource) Delete(c *fiber.Ctx) error {
-	id := c.Params("id")
-	if err := r.CRUD.Delete(%s, id); err != nil {
-		if crud.IsInvalidIDError(err) {
-			return response.SendError(c, 400, err.Error())
-		}
-		if crud.IsNotFoundError(err) {
-			return response.SendError(c, 404, "Not found")
-		}
-		return response.SendError(c, 500, err.Error())
+func isIntegerType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
 	}
-	return c.SendStatus(204)
-}
-`,
-		importsSection,
-		structName, structName,
-		structName, routesSignature, structName,
-		crudInit,
-		authMiddlewareSetup,
-		listRoute, getRoute, postRoute, putRoute, deleteRoute,
-		conversionFuncs,
-		structName, structName, structName, pluralResourceName, structName,
-		allowedFieldsStr,
-		contextFunc,
-		structName, structName,
-		structName, structName, structName, structName, pluralResourceName, structName,
-		contextFunc,
-		structName,
-		structName, structName, structName, structName, structName, structName, pluralResourceName, structName,
-		structName,
-		lowerStructName,
-		userIdAutoPopulate,
-		contextFunc,
-		structName, structName,
-		structName, structName, structName, structName, structName, structName, pluralResourceName, structName,
-		structName,
-		lowerStructName,
-		userIdAutoPopulate,
-		contextFunc,
-		structName,
-		structName, structName, structName, pluralResourceName, structName,
-		contextFunc)
+	return false
 }
 
-func generateConversionFunctions(structName string, fields []StructField) string {
-	var modelToDTOFields strings.Builder
-	for _, field := range fields {
-		if field.DTOTag == "-" || field.DTOTag == "write" {
-			continue
-		}
-		modelToDTOFields.WriteString(fmt.Sprintf("\t\t%s: m.%s,\n", field.Name, field.Name))
-	}
+// packageImports returns the import paths of the models and DTOs packages used
+// by the generated resources
+func (g *Generator) packageImports() (modelsImport, dtosImport string) {
+	moduleName := getModuleName()
+	cfg := g.Config
 
-	var createDTOToModelFields strings.Builder
-	for _, field := range fields {
-		dbTag := strings.ToLower(field.DBTag)
-		if dbTag != FieldID && dbTag != FieldCreatedAt && dbTag != FieldUpdatedAt {
-			if field.DTOTag == "-" || field.DTOTag == "read" {
-				continue
-			}
-			createDTOToModelFields.WriteString(fmt.Sprintf("\t\t%s: dto.%s,\n", field.Name, field.Name))
-		}
-	}
+	modelsImport = moduleName
+	dtosImport = moduleName
 
-	var updateDTOToModelFields strings.Builder
-	for _, field := range fields {
-		dbTag := strings.ToLower(field.DBTag)
-		if dbTag != FieldID && dbTag != FieldCreatedAt && dbTag != FieldUpdatedAt {
-			if field.DTOTag == "-" || field.DTOTag == "read" {
-				continue
-			}
-			updateDTOToModelFields.WriteString(fmt.Sprintf("\t\t%s: dto.%s,\n", field.Name, field.Name))
-		}
+	if cfg.Codegen.Output.Models != "models" && cfg.Codegen.Output.Models != "" {
+		modelsPath := strings.TrimPrefix(cfg.Codegen.Output.Models, "./")
+		modelsImport = moduleName + "/" + strings.ReplaceAll(modelsPath, string(filepath.Separator), "/")
+	}
+	if cfg.Codegen.Output.DTOs != "dtos" && cfg.Codegen.Output.DTOs != "" {
+		dtosPath := strings.TrimPrefix(cfg.Codegen.Output.DTOs, "./")
+		dtosImport = moduleName + "/" + strings.ReplaceAll(dtosPath, string(filepath.Separator), "/")
 	}
 
-	lowerStructName := strings.ToLower(structName)
-	return fmt.Sprintf(`func modelTo%sDTO(m models.%s) dtos.%sDTO {
-	return dtos.%sDTO{
-%s	}
-}
-
-func %sCreateDTOToModel(dto dtos.%sCreateDTO) models.%s {
-	return models.%s{
-%s	}
-}
-
-func %sUpdateDTOToModel(dto dtos.%sUpdateDTO) models.%s {
-	return models.%s{
-%s	}
-}
-`, structName, structName, structName, structName, modelToDTOFields.String(),
-		lowerStructName, structName, structName, structName, createDTOToModelFields.String(),
-		lowerStructName, structName, structName, structName, updateDTOToModelFields.String())
+	modelsImport = strings.TrimSuffix(modelsImport, "/models") + "/models"
+	dtosImport = strings.TrimSuffix(dtosImport, "/dtos") + "/dtos"
+	return modelsImport, dtosImport
 }
@@ -0,0 +1,301 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestPrimaryKeyFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []StructField
+		expected []string
+		isID     bool
+	}{
+		{
+			name: "tagged composite key",
+			fields: []StructField{
+				{Name: "OrderId", DBTag: "order_id", IsPrimaryKey: true},
+				{Name: "ProductId", DBTag: "product_id", IsPrimaryKey: true},
+				{Name: "Quantity", DBTag: "quantity"},
+			},
+			expected: []string{"OrderId", "ProductId"},
+		},
+		{
+			name: "untagged id column",
+			fields: []StructField{
+				{Name: "Id", DBTag: "id"},
+				{Name: "Title", DBTag: "title"},
+			},
+			expected: []string{"Id"},
+			isID:     true,
+		},
+		{
+			name: "tagged key takes precedence over id",
+			fields: []StructField{
+				{Name: "Id", DBTag: "id"},
+				{Name: "UserUuid", DBTag: "user_uuid", IsPrimaryKey: true},
+			},
+			expected: []string{"UserUuid"},
+		},
+		{
+			name:     "no key",
+			fields:   []StructField{{Name: "Message", DBTag: "message"}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := primaryKeyFields(tt.fields)
+			var names []string
+			for _, field := range key {
+				names = append(names, field.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected key %v, got %v", tt.expected, names)
+			}
+			if isDefaultKey(key) != tt.isID {
+				t.Errorf("Expected isDefaultKey %v, got %v", tt.isID, isDefaultKey(key))
+			}
+		})
+	}
+}
+
+func TestGenerateResourceWithCompositeKey(t *testing.T) {
+	g := NewGenerator(&config.Config{}, NewOutput(true))
+	fields := []StructField{
+		{Name: "OrderId", Type: "int", DBTag: "order_id", IsPrimaryKey: true},
+		{Name: "ProductId", Type: "string", DBTag: "product_id", IsPrimaryKey: true},
+		{Name: "Quantity", Type: "int", DBTag: "quantity"},
+	}
+
+	code, err := g.generateResourceFromModel("OrderItem", fields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+
+	expected := []string{
+		`router.Get("/order_items/:order_id/:product_id", res.Get)`,
+		`router.Put("/order_items/:order_id/:product_id", res.Update)`,
+		`router.Delete("/order_items/:order_id/:product_id", res.Delete)`,
+		`// @Param order_id path int true "order_id"`,
+		`// @Param product_id path string true "product_id"`,
+		`// @Router /order_items/{order_id}/{product_id} [get]`,
+		`query.Eq("order_id", c.Params("order_id")),`,
+		`Set("quantity", item.Quantity).`,
+		`qb = qb.Returning("order_id", "product_id")`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected resource to contain %q", want)
+		}
+	}
+	if strings.Contains(code, "GetByID") {
+		t.Error("Expected composite key resource not to use CRUD.GetByID")
+	}
+}
+
+func TestGenerateResourceValidation(t *testing.T) {
+	g := NewGenerator(&config.Config{}, NewOutput(true))
+	fields := []StructField{
+		{Name: "Id", Type: "int", DBTag: "id"},
+		{Name: "Status", Type: "string", DBTag: "status", ValidateTag: "oneof=draft published"},
+		{Name: "UserId", Type: "string", JSONTag: "user_id", DBTag: "user_id", IsPointer: true, ValidateTag: "required,max=36"},
+	}
+
+	code, err := g.generateResourceFromModel("Post", fields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+	for _, want := range []string{
+		"if err := response.ValidateStruct(&createDTO); err != nil {\n\t\treturn sendValidationError(c, createDTO, err)",
+		"if err := response.ValidateStruct(&updateDTO); err != nil {\n\t\treturn sendValidationError(c, updateDTO, err)",
+		"// @Failure 422 {object} ValidationError",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected resource to contain %q", want)
+		}
+	}
+
+	dto, err := g.generateDTOsFromModel("Post", fields)
+	if err != nil {
+		t.Fatalf("Failed to generate DTOs: %v", err)
+	}
+	if !strings.Contains(dto, "Status string `json:\"status\" validate:\"oneof=draft published\"`") {
+		t.Errorf("Expected write DTOs to carry the validate tag, got:\n%s", dto)
+	}
+	// user_id is filled in from the authenticated user
+	if !strings.Contains(dto, "UserId *string `json:\"user_id\" validate:\"omitempty,max=36\"`") {
+		t.Errorf("Expected UserId not to be required in write DTOs, got:\n%s", dto)
+	}
+}
+
+func TestGenerateResourceWithoutKey(t *testing.T) {
+	g := NewGenerator(&config.Config{}, NewOutput(true))
+	fields := []StructField{{Name: "Message", Type: "string", DBTag: "message"}}
+
+	code, err := g.generateResourceFromModel("AuditLog", fields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+
+	if !strings.Contains(code, `router.Get("/audit_logs", res.List)`) {
+		t.Error("Expected List route for a table without primary key")
+	}
+	for _, handler := range []string{"res.Get", "res.Create", "res.Update", "res.Patch", "res.Delete"} {
+		if strings.Contains(code, handler) {
+			t.Errorf("Expected no %s route for a table without primary key", handler)
+		}
+	}
+}
+
+func TestGenerateResourcePatch(t *testing.T) {
+	g := NewGenerator(&config.Config{}, NewOutput(true))
+	fields := []StructField{
+		{Name: "Id", Type: "int", DBTag: "id"},
+		{Name: "Title", Type: "string", DBTag: "title", ValidateTag: "required,max=120"},
+		{Name: "Body", Type: "string", DBTag: "body", IsPointer: true},
+		{Name: "UpdatedAt", Type: "time.Time", DBTag: "updated_at", IsPointer: true},
+	}
+	authCfg := &AuthConfig{Enabled: true, RequireAuth: map[string][]string{"posts": {"PATCH"}}}
+
+	code, err := g.generateResourceFromModel("Post", fields, authCfg)
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+	for _, want := range []string{
+		"router.Patch(\"/posts/:id\", authMiddleware, res.Patch)",
+		"func (r *PostResource) Patch(c *fiber.Ctx) error {",
+		"if err := response.ValidateStruct(&patchDTO); err != nil {",
+		"if patchDTO.Title != nil {\n\t\tqb.Set(\"title\", patchDTO.Title)",
+		"if _, ok := present[\"body\"]; ok {\n\t\tqb.Set(\"body\", patchDTO.Body)",
+		"qb.Set(\"updated_at\", time.Now())",
+		"// @Router /posts/{id} [patch]",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected resource to contain %q", want)
+		}
+	}
+	if strings.Contains(code, "router.Put(\"/posts/:id\", authMiddleware") {
+		t.Error("Expected PUT not to require auth when only PATCH does")
+	}
+
+	dto, err := g.generateDTOsFromModel("Post", fields)
+	if err != nil {
+		t.Fatalf("Failed to generate DTOs: %v", err)
+	}
+	for _, want := range []string{
+		"type PostPatchDTO struct {",
+		"\tTitle *string `json:\"title,omitempty\" validate:\"omitempty,max=120\"`",
+		"\tBody *string `json:\"body,omitempty\"`",
+	} {
+		if !strings.Contains(dto, want) {
+			t.Errorf("Expected DTOs to contain %q, got:\n%s", want, dto)
+		}
+	}
+}
+
+func TestTableSchemaKeyColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    TableSchema
+		expected []string
+	}{
+		{
+			name:     "introspected key",
+			table:    TableSchema{PrimaryKey: []string{"order_id", "product_id"}, Columns: []Column{{Name: "id"}}},
+			expected: []string{"order_id", "product_id"},
+		},
+		{
+			name:     "id fallback",
+			table:    TableSchema{Columns: []Column{{Name: "id"}, {Name: "title"}}},
+			expected: []string{"id"},
+		},
+		{
+			name:     "no key",
+			table:    TableSchema{Columns: []Column{{Name: "message"}}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.table.KeyColumns()
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected key columns %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestGenerateResourceUserID checks that the user_id filled in from the
+// authenticated user, whose ID is a string, compiles for every type of field
+func TestGenerateResourceUserID(t *testing.T) {
+	tests := []struct {
+		name  string
+		field StructField
+		// modelType is the type of the field in the model
+		modelType string
+		assign    string
+	}{
+		{"not null", StructField{Type: "string"}, "string", "item.UserId = user.UserID"},
+		{"nullable", StructField{Type: "string", IsPointer: true}, "*string", "item.UserId = &user.UserID"},
+		{"sql", StructField{Type: "string", IsPointer: true, NullType: "sql.NullString"}, "sql.NullString", "item.UserId = sql.NullString{String: user.UserID, Valid: true}"},
+		{"integer", StructField{Type: "int64"}, "int64", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(&config.Config{}, NewOutput(true))
+			userID := tt.field
+			userID.Name, userID.DBTag = "UserId", "user_id"
+			fields := []StructField{{Name: "Id", Type: "int", DBTag: "id", IsPrimaryKey: true}, userID}
+
+			code, err := g.generateResourceFromModel("Post", fields, NoAuthConfig())
+			if err != nil {
+				t.Fatalf("Failed to generate resource: %v", err)
+			}
+
+			var assigns []string
+			for _, line := range strings.Split(code, "\n") {
+				if line = strings.TrimSpace(line); strings.HasPrefix(line, "item.UserId = ") {
+					assigns = append(assigns, line)
+				}
+			}
+			if tt.assign == "" {
+				if len(assigns) > 0 || strings.Contains(code, `qb.Set("user_id", user.UserID)`) {
+					t.Errorf("Expected no user_id from the authenticated user, got:\n%s", code)
+				}
+				return
+			}
+			if len(assigns) != 2 || assigns[0] != tt.assign || assigns[1] != tt.assign {
+				t.Fatalf("Expected Create and Update to set %q, got %q", tt.assign, assigns)
+			}
+
+			src := "package resources\n\n"
+			if strings.HasPrefix(tt.modelType, "sql.") {
+				src += "import \"database/sql\"\n\n"
+			}
+			src += "type Post struct {\n\tUserId " + tt.modelType + "\n}\n\n" +
+				"type AuthUser struct {\n\tUserID string\n}\n\n" +
+				"func populate(item Post, user *AuthUser) {\n\t" + tt.assign + "\n\t_ = item\n}\n"
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "post.go", src, 0)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", tt.assign, err)
+			}
+			conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+			if _, err := conf.Check("resources", fset, []*ast.File{file}, nil); err != nil {
+				t.Errorf("Expected %q to compile, got %v", tt.assign, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nicolasbonnici/gorest/database"
+)
+
+// LoadSchema returns the schema code is generated from: the snapshot of
+// Options.SchemaSnapshot when set, the SQL files of Options.SchemaFiles, the
+// migrations of Options.Migrations, or else the schema introspected from db.
+// A snapshot sets the dialect it was dumped in, and migrations the SQLite
+// dialect they are applied in.
+func (g *Generator) LoadSchema(db database.Database) (map[string]TableSchema, error) {
+	if g.Options.SchemaSnapshot != "" {
+		path, err := g.snapshotPath()
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := LoadSchemaSnapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Dialect != "" {
+			g.Dialect = snapshot.Dialect
+		}
+		return snapshot.Schema(), nil
+	}
+	return g.loadSourceSchema(db)
+}
+
+// loadSourceSchema returns the schema of the SQL files of Options.SchemaFiles
+// or of the migrations of Options.Migrations when set, or else the schema
+// introspected from db
+func (g *Generator) loadSourceSchema(db database.Database) (map[string]TableSchema, error) {
+	if len(g.Options.SchemaFiles) == 0 && len(g.Options.Migrations) == 0 {
+		return LoadSchema(db)
+	}
+
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return nil, &ConfigError{Op: "find project root", Err: err}
+	}
+	if len(g.Options.SchemaFiles) > 0 {
+		paths, err := resolveSchemaFiles(projectRoot, g.Options.SchemaFiles)
+		if err != nil {
+			return nil, err
+		}
+		return LoadDDLSchema(g.Dialect, paths...)
+	}
+
+	dirs := make([]string, len(g.Options.Migrations))
+	for i, dir := range g.Options.Migrations {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(projectRoot, dir)
+		}
+		dirs[i] = dir
+	}
+	tables, err := LoadMigrationSchema(dirs...)
+	if err != nil {
+		return nil, err
+	}
+	if g.Dialect != DialectSQLite {
+		g.targetDialect = g.Dialect
+	}
+	g.Dialect = DialectSQLite
+	return tables, nil
+}
+
+// resolveSchemaFiles expands the glob patterns of SQL schema files relative
+// to dir, in pattern order and file name order within a pattern
+func resolveSchemaFiles(dir string, patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, &ConfigError{Op: "resolve schema files", Err: fmt.Errorf("invalid pattern %q: %w", pattern, err)}
+		}
+		if len(matches) == 0 {
+			return nil, &ConfigError{Op: "resolve schema files", Err: fmt.Errorf("no file matches %s: %w", pattern, os.ErrNotExist)}
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, &ConfigError{Op: "resolve schema files", Err: errors.New("no schema files")}
+	}
+	return paths, nil
+}
@@ -0,0 +1,259 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nicolasbonnici/gorest/database"
+	"gopkg.in/yaml.v3"
+)
+
+// SnapshotFile is the default path of the schema snapshot, relative to the
+// project root
+const SnapshotFile = "schema.snapshot.json"
+
+// SnapshotVersion is the version of the snapshot format. Snapshots of another
+// version are refused rather than misread.
+const SnapshotVersion = 1
+
+// SchemaSnapshot is the schema serialized by codegen schema dump, so that code
+// can be generated from a committed file instead of the database
+type SchemaSnapshot struct {
+	Version int `json:"version" yaml:"version"`
+	// Dialect is the dialect the column types were introspected in
+	Dialect string `json:"dialect,omitempty" yaml:"dialect,omitempty"`
+	// Tables are sorted by name
+	Tables []TableSnapshot `json:"tables" yaml:"tables"`
+}
+
+// TableSnapshot is a table of a schema snapshot
+type TableSnapshot struct {
+	Name       string             `json:"name" yaml:"name"`
+	PrimaryKey []string           `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	Columns    []ColumnSnapshot   `json:"columns" yaml:"columns"`
+	Relations  []RelationSnapshot `json:"relations,omitempty" yaml:"relations,omitempty"`
+	Indexes    []string           `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+}
+
+// ColumnSnapshot is a column of a schema snapshot, whose primary key columns
+// are listed by the table
+type ColumnSnapshot struct {
+	Name          string   `json:"name" yaml:"name"`
+	Type          string   `json:"type" yaml:"type"`
+	TypeDef       string   `json:"type_def,omitempty" yaml:"type_def,omitempty"`
+	Nullable      bool     `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Default       string   `json:"default,omitempty" yaml:"default,omitempty"`
+	MaxLength     int      `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Precision     int      `json:"precision,omitempty" yaml:"precision,omitempty"`
+	Scale         int      `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Unique        bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Comment       string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	EnumType      string   `json:"enum_type,omitempty" yaml:"enum_type,omitempty"`
+	EnumValues    []string `json:"enum_values,omitempty" yaml:"enum_values,omitempty"`
+	AutoIncrement bool     `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+}
+
+// RelationSnapshot is a relation from a column of the table holding it
+type RelationSnapshot struct {
+	Column       string `json:"column" yaml:"column"`
+	ParentTable  string `json:"parent_table" yaml:"parent_table"`
+	ParentColumn string `json:"parent_column" yaml:"parent_column"`
+}
+
+// NewSchemaSnapshot returns the snapshot of a schema. Tables and relations are
+// sorted, so that dumping an unchanged schema yields the same file.
+func NewSchemaSnapshot(dialect string, tables map[string]TableSchema) *SchemaSnapshot {
+	snapshot := &SchemaSnapshot{Version: SnapshotVersion, Dialect: dialect, Tables: []TableSnapshot{}}
+	for _, table := range tables {
+		t := TableSnapshot{Name: table.TableName, PrimaryKey: table.PrimaryKey, Columns: []ColumnSnapshot{}, Indexes: table.Indexes}
+		for _, col := range table.Columns {
+			t.Columns = append(t.Columns, ColumnSnapshot{
+				Name:          col.Name,
+				Type:          col.Type,
+				TypeDef:       col.TypeDef,
+				Nullable:      col.IsNullable,
+				Default:       col.Default,
+				MaxLength:     col.MaxLength,
+				Precision:     col.Precision,
+				Scale:         col.Scale,
+				Unique:        col.IsUnique,
+				Comment:       col.Comment,
+				EnumType:      col.EnumType,
+				EnumValues:    col.EnumValues,
+				AutoIncrement: col.AutoIncrement,
+			})
+		}
+		for _, rel := range table.Relations {
+			t.Relations = append(t.Relations, RelationSnapshot{
+				Column:       rel.ChildColumn,
+				ParentTable:  rel.ParentTable,
+				ParentColumn: rel.ParentColumn,
+			})
+		}
+		sort.Slice(t.Relations, func(i, j int) bool {
+			a, b := t.Relations[i], t.Relations[j]
+			if a.Column != b.Column {
+				return a.Column < b.Column
+			}
+			if a.ParentTable != b.ParentTable {
+				return a.ParentTable < b.ParentTable
+			}
+			return a.ParentColumn < b.ParentColumn
+		})
+		snapshot.Tables = append(snapshot.Tables, t)
+	}
+	sort.Slice(snapshot.Tables, func(i, j int) bool {
+		return snapshot.Tables[i].Name < snapshot.Tables[j].Name
+	})
+	return snapshot
+}
+
+// Schema returns the tables of the snapshot, keyed by name like LoadSchema
+func (s *SchemaSnapshot) Schema() map[string]TableSchema {
+	tables := make(map[string]TableSchema, len(s.Tables))
+	for _, t := range s.Tables {
+		table := TableSchema{
+			TableName:  t.Name,
+			PrimaryKey: t.PrimaryKey,
+			Columns:    make([]Column, len(t.Columns)),
+			Relations:  make([]Relation, len(t.Relations)),
+			Indexes:    t.Indexes,
+		}
+		for i, col := range t.Columns {
+			isKey := false
+			for _, key := range t.PrimaryKey {
+				isKey = isKey || key == col.Name
+			}
+			table.Columns[i] = Column{
+				Name:          col.Name,
+				Type:          col.Type,
+				TypeDef:       col.TypeDef,
+				IsNullable:    col.Nullable,
+				IsPrimaryKey:  isKey,
+				Default:       col.Default,
+				MaxLength:     col.MaxLength,
+				Precision:     col.Precision,
+				Scale:         col.Scale,
+				IsUnique:      col.Unique,
+				Comment:       col.Comment,
+				EnumType:      col.EnumType,
+				EnumValues:    col.EnumValues,
+				AutoIncrement: col.AutoIncrement,
+			}
+		}
+		for i, rel := range t.Relations {
+			table.Relations[i] = Relation{
+				ChildTable:   t.Name,
+				ChildColumn:  rel.Column,
+				ParentTable:  rel.ParentTable,
+				ParentColumn: rel.ParentColumn,
+			}
+		}
+		tables[t.Name] = table
+	}
+	return tables
+}
+
+// isYAMLPath reports whether a snapshot at path is written in YAML rather than
+// JSON
+func isYAMLPath(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// MarshalSchemaSnapshot encodes the snapshot in YAML when path ends with .yaml
+// or .yml, and in indented JSON otherwise
+func MarshalSchemaSnapshot(path string, snapshot *SchemaSnapshot) ([]byte, error) {
+	if !isYAMLPath(path) {
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(snapshot); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadSchemaSnapshot reads the snapshot at path, in YAML or JSON depending on
+// its extension. Unknown fields and versions are reported as a ParseError.
+func LoadSchemaSnapshot(path string) (*SchemaSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ParseError{Path: path, Err: err}
+	}
+
+	snapshot := &SchemaSnapshot{}
+	if isYAMLPath(path) {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(snapshot)
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(snapshot)
+	}
+	if err != nil {
+		return nil, &ParseError{Path: path, Err: err}
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, &ParseError{Path: path, Err: fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)}
+	}
+	return snapshot, nil
+}
+
+// snapshotPath returns the path of the schema snapshot: the configured one,
+// relative to the project root, or SnapshotFile
+func (g *Generator) snapshotPath() (string, error) {
+	path := g.Options.SchemaSnapshot
+	if path == "" {
+		path = SnapshotFile
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return "", &ConfigError{Op: "find project root", Err: err}
+	}
+	return filepath.Join(projectRoot, path), nil
+}
+
+// DumpSchema writes the snapshot of the schema read from the schema files or,
+// when there are none, introspected from db. The configured snapshot is the
+// file written, never the schema read.
+func (g *Generator) DumpSchema(db database.Database) error {
+	path, err := g.snapshotPath()
+	if err != nil {
+		return err
+	}
+	tables, err := g.loadSourceSchema(db)
+	if err != nil {
+		return err
+	}
+
+	data, err := MarshalSchemaSnapshot(path, NewSchemaSnapshot(g.Dialect, tables))
+	if err != nil {
+		return &WriteError{Path: path, Err: err}
+	}
+	if err := g.Output.WriteFile(path, data); err != nil {
+		return err
+	}
+	if !g.Output.DryRun {
+		fmt.Printf("✅ Dumped schema snapshot of %d table(s) → %s\n", len(tables), path)
+	}
+	return nil
+}
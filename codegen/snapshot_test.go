@@ -0,0 +1,160 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestSchemaSnapshotRoundTrip(t *testing.T) {
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", PrimaryKey: []string{"id"}, Relations: []Relation{}, Columns: []Column{
+			{Name: "id", Type: "integer", TypeDef: "integer", IsPrimaryKey: true, Default: "nextval('users_id_seq'::regclass)"},
+			{Name: "mood", Type: "USER-DEFINED", TypeDef: "enum('happy','sad')", IsNullable: true, EnumType: "mood", EnumValues: []string{"happy", "sad"}},
+		}},
+		"posts": {TableName: "posts", PrimaryKey: []string{"id"}, Columns: []Column{
+			{Name: "id", Type: "uuid", TypeDef: "uuid", IsPrimaryKey: true},
+			{Name: "price", Type: "numeric", TypeDef: "numeric", Precision: 10, Scale: 2, Comment: "In cents"},
+			{Name: "user_id", Type: "integer", TypeDef: "integer", IsUnique: true},
+		}, Relations: []Relation{
+			{ChildTable: "posts", ChildColumn: "user_id", ParentTable: "users", ParentColumn: "id"},
+		}},
+	}
+
+	for _, name := range []string{"schema.json", "schema.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			data, err := MarshalSchemaSnapshot(path, NewSchemaSnapshot(DialectPostgres, tables))
+			if err != nil {
+				t.Fatalf("Failed to marshal snapshot: %v", err)
+			}
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatalf("Failed to write snapshot: %v", err)
+			}
+
+			snapshot, err := LoadSchemaSnapshot(path)
+			if err != nil {
+				t.Fatalf("Failed to load snapshot: %v", err)
+			}
+			if snapshot.Dialect != DialectPostgres || snapshot.Tables[0].Name != "posts" {
+				t.Errorf("Expected postgres tables sorted by name, got %+v", snapshot)
+			}
+			if got := snapshot.Schema(); !reflect.DeepEqual(got, tables) {
+				t.Errorf("Expected schema\n%+v\ngot\n%+v", tables, got)
+			}
+		})
+	}
+}
+
+func TestLoadSchemaSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"schema.json", `{"version": 2, "tables": []}`, "unsupported snapshot version 2"},
+		{"schema.json", `{"tables": []}`, "unsupported snapshot version 0"},
+		{"schema.json", `{"version": 1, "tables": [{"name": "users", "colums": []}]}`, "unknown field \"colums\""},
+		{"schema.yaml", "version: 1\ntables:\n  - name: users\n    colums: []\n", "field colums not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatalf("Failed to write snapshot: %v", err)
+			}
+			_, err := LoadSchemaSnapshot(path)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected ParseError containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGenerateFromSchemaSnapshot(t *testing.T) {
+	snapshot, err := filepath.Abs(filepath.Join("testdata", "schema.json"))
+	if err != nil {
+		t.Fatalf("Failed to resolve snapshot: %v", err)
+	}
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	cfg.Codegen.Output.DTOs = filepath.Join(dir, "dtos")
+	cfg.Codegen.Output.Resources = filepath.Join(dir, "resources")
+	cfg.Codegen.Output.OpenAPI = filepath.Join(dir, "openapi")
+	g := NewGenerator(cfg, nil)
+	g.Options.SchemaSnapshot = snapshot
+
+	// No database is needed to generate from a snapshot
+	tables, err := g.LoadSchema(nil)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if g.Dialect != DialectPostgres || len(tables) != 2 {
+		t.Fatalf("Expected 2 postgres tables, got %s %+v", g.Dialect, tables)
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+	if err := g.GenerateAPI(NoAuthConfig()); err != nil {
+		t.Fatalf("Failed to generate resources: %v", err)
+	}
+	if err := g.GenerateOpenAPI(tables); err != nil {
+		t.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+
+	files := map[string][]string{
+		filepath.Join("models", "user.go"):          {"type User struct", "Bio   *string"},
+		filepath.Join("models", "todo.go"):          {"type Todo struct", "UserId int"},
+		filepath.Join("dtos", "user.go"):            {"type UserCreateDTO struct"},
+		filepath.Join("resources", "todo.go"):       {"func RegisterTodoRoutes"},
+		filepath.Join("openapi", OpenAPISchemaFile): {"openapi: 3.1.0", "/users:", "/todos/{id}:", "Short biography"},
+	}
+	for file, wants := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("Failed to read %s: %v", file, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", file, want, data)
+			}
+		}
+	}
+}
+
+func TestDumpSchema(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "schema.sql")
+	ddl := "CREATE TABLE users (id serial PRIMARY KEY, email text NOT NULL UNIQUE);"
+	if err := os.WriteFile(src, []byte(ddl), 0644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "schema.snapshot.yaml")
+	g := NewGenerator(&config.Config{}, nil)
+	g.Dialect = DialectPostgres
+	g.Options.SchemaFiles = []string{src}
+	g.Options.SchemaSnapshot = path
+
+	if err := g.DumpSchema(nil); err != nil {
+		t.Fatalf("Failed to dump schema: %v", err)
+	}
+	snapshot, err := LoadSchemaSnapshot(path)
+	if err != nil {
+		t.Fatalf("Failed to load dumped snapshot: %v", err)
+	}
+	want, err := LoadDDLSchema(DialectPostgres, src)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	if got := snapshot.Schema(); !reflect.DeepEqual(got["users"].Columns, want["users"].Columns) {
+		t.Errorf("Expected columns\n%+v\ngot\n%+v", want["users"].Columns, got["users"].Columns)
+	}
+}
@@ -0,0 +1,141 @@
+package codegen
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Operations of a generated resource, as listed in a table override
+const (
+	OperationList   = "list"
+	OperationGet    = "get"
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationPatch  = "patch"
+	OperationDelete = "delete"
+)
+
+var resourceOperations = []string{OperationList, OperationGet, OperationCreate, OperationUpdate, OperationPatch, OperationDelete}
+
+// TableOptions selects the tables code is generated for and overrides the
+// names derived from them
+type TableOptions struct {
+	// Include lists glob patterns of the tables to generate. Defaults to
+	// every table.
+	Include []string `yaml:"include"`
+	// Exclude lists glob patterns of the tables to leave out, like migration
+	// or internal tables
+	Exclude []string `yaml:"exclude"`
+	// Overrides is keyed by table name
+	Overrides map[string]TableOverride `yaml:"overrides"`
+}
+
+// TableOverride replaces the defaults derived from a table name
+type TableOverride struct {
+	// Struct is the name of the model struct, users → User by default
+	Struct string `yaml:"struct"`
+	// Path is the route path of the resource, the pluralized table name by
+	// default
+	Path string `yaml:"path"`
+	// Operations lists the operations the resource exposes, all by default
+	Operations []string `yaml:"operations"`
+}
+
+// validate checks the patterns and operations, so that mistakes are reported
+// instead of silently matching nothing
+func (o TableOptions) validate() error {
+	for _, pattern := range append(slices.Clone(o.Include), o.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	for table, override := range o.Overrides {
+		for _, op := range override.Operations {
+			if !slices.Contains(resourceOperations, op) {
+				return fmt.Errorf("unknown operation %q for table %s, expected one of %s", op, table, strings.Join(resourceOperations, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Includes reports whether code is generated for the table
+func (o TableOptions) Includes(table string) bool {
+	if len(o.Include) > 0 && !matchesAny(o.Include, table) {
+		return false
+	}
+	return !matchesAny(o.Exclude, table)
+}
+
+// StructName returns the name of the model struct of the table
+func (o TableOptions) StructName(table string) string {
+	if name := o.Overrides[table].Struct; name != "" {
+		return name
+	}
+	return toPascalCase(singularize(table))
+}
+
+// Path returns the route path of the resource of the table, without slashes.
+// Defaults to the pluralized lowercase table name, order_items for the
+// OrderItem model.
+func (o TableOptions) Path(table string) string {
+	if p := strings.Trim(o.Overrides[table].Path, "/"); p != "" {
+		return p
+	}
+	return Pluralize(singularize(strings.ToLower(table)))
+}
+
+// Allows reports whether the resource of the table exposes the operation
+func (o TableOptions) Allows(table, operation string) bool {
+	ops := o.Overrides[table].Operations
+	return len(ops) == 0 || slices.Contains(ops, operation)
+}
+
+func (o TableOptions) operations(table string) ResourceOperations {
+	return ResourceOperations{
+		List:   o.Allows(table, OperationList),
+		Get:    o.Allows(table, OperationGet),
+		Create: o.Allows(table, OperationCreate),
+		Update: o.Allows(table, OperationUpdate),
+		Patch:  o.Allows(table, OperationPatch),
+		Delete: o.Allows(table, OperationDelete),
+	}
+}
+
+// filter returns the tables code is generated for
+func (o TableOptions) filter(tables map[string]TableSchema) map[string]TableSchema {
+	included := make(map[string]TableSchema, len(tables))
+	for name, table := range tables {
+		if o.Includes(name) {
+			included[name] = table
+		}
+	}
+	return included
+}
+
+func matchesAny(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	})
+}
+
+// tableOptions returns the table options of the generator
+func (g *Generator) tableOptions() TableOptions {
+	if g.Options == nil {
+		return TableOptions{}
+	}
+	return g.Options.Tables
+}
+
+// selected reports whether the files of the table are written in this run:
+// the table is included and, when tables were selected on the command line,
+// part of the selection
+func (g *Generator) selected(table string) bool {
+	if !g.tableOptions().Includes(table) {
+		return false
+	}
+	return len(g.Tables) == 0 || matchesAny(g.Tables, table)
+}
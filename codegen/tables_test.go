@@ -0,0 +1,224 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestTableOptionsIncludes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     TableOptions
+		table    string
+		included bool
+	}{
+		{name: "no patterns", opts: TableOptions{}, table: "users", included: true},
+		{name: "excluded", opts: TableOptions{Exclude: []string{"schema_migrations"}}, table: "schema_migrations", included: false},
+		{name: "excluded by glob", opts: TableOptions{Exclude: []string{"_*"}}, table: "_internal", included: false},
+		{name: "not excluded", opts: TableOptions{Exclude: []string{"_*"}}, table: "users", included: true},
+		{name: "included", opts: TableOptions{Include: []string{"user*"}}, table: "user_roles", included: true},
+		{name: "not included", opts: TableOptions{Include: []string{"user*"}}, table: "posts", included: false},
+		{name: "exclude wins", opts: TableOptions{Include: []string{"*"}, Exclude: []string{"posts"}}, table: "posts", included: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Includes(tt.table); got != tt.included {
+				t.Errorf("Expected Includes(%q) %v, got %v", tt.table, tt.included, got)
+			}
+		})
+	}
+}
+
+func TestTableOptionsOverrides(t *testing.T) {
+	opts := TableOptions{Overrides: map[string]TableOverride{
+		"users":    {Struct: "Member", Path: "/members/"},
+		"accounts": {Struct: "Customer"},
+		"posts":    {Operations: []string{OperationList, OperationGet}},
+	}}
+
+	tests := []struct {
+		table      string
+		structName string
+		path       string
+	}{
+		{table: "users", structName: "Member", path: "members"},
+		{table: "accounts", structName: "Customer", path: "accounts"},
+		{table: "blog_posts", structName: "BlogPost", path: "blog_posts"},
+	}
+	for _, tt := range tests {
+		structName := opts.StructName(tt.table)
+		if structName != tt.structName {
+			t.Errorf("Expected struct %s for %s, got %s", tt.structName, tt.table, structName)
+		}
+		if path := opts.Path(tt.table); path != tt.path {
+			t.Errorf("Expected path %s for %s, got %s", tt.path, tt.table, path)
+		}
+	}
+
+	want := ResourceOperations{List: true, Get: true}
+	if got := opts.operations("posts"); got != want {
+		t.Errorf("Expected operations %+v, got %+v", want, got)
+	}
+	all := ResourceOperations{List: true, Get: true, Create: true, Update: true, Patch: true, Delete: true}
+	if got := opts.operations("users"); got != all {
+		t.Errorf("Expected every operation by default, got %+v", got)
+	}
+}
+
+func TestTableOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TableOptions
+		wantErr string
+	}{
+		{name: "valid", opts: TableOptions{Include: []string{"*"}, Overrides: map[string]TableOverride{"users": {Operations: []string{"list"}}}}},
+		{name: "bad pattern", opts: TableOptions{Exclude: []string{"[a"}}, wantErr: `invalid table pattern "[a"`},
+		{name: "unknown operation", opts: TableOptions{Overrides: map[string]TableOverride{"users": {Operations: []string{"destroy"}}}}, wantErr: `unknown operation "destroy" for table users`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerateStructsTableOptions(t *testing.T) {
+	dir := t.TempDir()
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{{Name: "id", Type: "integer"}}},
+		"posts": {
+			TableName: "posts",
+			Columns:   []Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}, {Name: "audit_id", Type: "integer"}},
+			Relations: []Relation{
+				{ChildColumn: "user_id", ParentTable: "users", ParentColumn: "id"},
+				{ChildColumn: "audit_id", ParentTable: "_audits", ParentColumn: "id"},
+			},
+		},
+		"_audits":           {TableName: "_audits", Columns: []Column{{Name: "id", Type: "integer"}}},
+		"schema_migrations": {TableName: "schema_migrations", Columns: []Column{{Name: "version", Type: "bigint"}}},
+	}
+
+	g := newManifestTestGenerator(t, dir, false)
+	g.Options.Tables = TableOptions{
+		Exclude:   []string{"schema_migrations", "_*"},
+		Overrides: map[string]TableOverride{"users": {Struct: "Member"}},
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "models"))
+	if err != nil {
+		t.Fatalf("Failed to read models: %v", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	if strings.Join(files, ",") != "member.go,post.go" {
+		t.Errorf("Expected models member.go and post.go, got %v", files)
+	}
+
+	post, err := os.ReadFile(filepath.Join(dir, "models", "post.go"))
+	if err != nil {
+		t.Fatalf("Failed to read post model: %v", err)
+	}
+	if !strings.Contains(string(post), "*Member") {
+		t.Errorf("Expected the relation to use the overridden struct name, got:\n%s", post)
+	}
+	if strings.Contains(string(post), "_audits") {
+		t.Errorf("Expected no relation towards an excluded table, got:\n%s", post)
+	}
+}
+
+func TestGenerateStructsSelectedTables(t *testing.T) {
+	dir := t.TempDir()
+	tables := manifestTestTables()
+	if err := newManifestTestGenerator(t, dir, false).GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	// Files of the tables left out of the selection are neither written nor removed
+	g := newManifestTestGenerator(t, dir, false)
+	g.Tables = []string{"users"}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to regenerate models: %v", err)
+	}
+	if got := g.Output.Paths(dir, ChangeUnchanged); strings.Join(got, ",") != "models/user.go" {
+		t.Errorf("Expected only models/user.go to be generated, got %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "models", "post.go")); err != nil {
+		t.Errorf("Expected the model of an unselected table to be kept: %v", err)
+	}
+	if _, ok := readTestManifest(t, dir).Files["models/post.go"]; !ok {
+		t.Error("Expected the manifest to keep the model of an unselected table")
+	}
+}
+
+func TestGenerateResourceTableOverrides(t *testing.T) {
+	dir := t.TempDir()
+	modelsDir := filepath.Join(dir, "models")
+	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+		t.Fatalf("Failed to create models directory: %v", err)
+	}
+	model := "package models\n\ntype Member struct {\n\tId int `json:\"id\" db:\"id\"`\n}\n\nfunc (Member) TableName() string {\n\treturn \"users\"\n}\n"
+	if err := os.WriteFile(filepath.Join(modelsDir, "member.go"), []byte(model), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = modelsDir
+	g := NewGenerator(cfg, NewOutput(true))
+	g.Options.Tables.Overrides = map[string]TableOverride{
+		"users": {Path: "people", Operations: []string{OperationList, OperationGet}},
+	}
+
+	fields := []StructField{{Name: "Id", Type: "int", DBTag: "id"}}
+	code, err := g.generateResourceFromModel("Member", fields, NoAuthConfig())
+	if err != nil {
+		t.Fatalf("Failed to generate resource: %v", err)
+	}
+	for _, want := range []string{
+		`router.Get("/people", res.List)`,
+		`router.Get("/people/:id", res.Get)`,
+		"// @Router /people [get]",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected resource to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"router.Post(", "router.Put(", "router.Patch(", "router.Delete(", "[post]", "[delete]"} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("Expected resource not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestExtractTableNamesSource(t *testing.T) {
+	src := "package models\n\ntype Member struct{}\n\nfunc (Member) TableName() string {\n\treturn \"users\"\n}\n\ntype AuditLog struct{}\n"
+	tables, err := extractTableNamesSource("member.go", src)
+	if err != nil {
+		t.Fatalf("Failed to parse model: %v", err)
+	}
+	if tables["Member"] != "users" {
+		t.Errorf("Expected Member table users, got %q", tables["Member"])
+	}
+	if tables["AuditLog"] != "audit_logs" {
+		t.Errorf("Expected AuditLog table audit_logs, got %q", tables["AuditLog"])
+	}
+}
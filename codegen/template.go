@@ -0,0 +1,341 @@
+package codegen
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Names of the templates used by the generators. A file with the same name in
+// the codegen.templates directory overrides the embedded default.
+const (
+	ModelTemplate      = "model.go.tmpl"
+	DTOTemplate        = "dto.go.tmpl"
+	ResourceTemplate   = "resource.go.tmpl"
+	RoutesTemplate     = "routes.go.tmpl"
+	ValidationTemplate = "validation.go.tmpl"
+	EnumTemplate       = "enum.go.tmpl"
+	OptionalTemplate   = "optional.go.tmpl"
+	NullableTemplate   = "nullable.go.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+var templateFuncs = template.FuncMap{
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+	"pascal":      toPascalCase,
+	"camel":       toCamelCase,
+	"pluralize":   Pluralize,
+	"singularize": singularize,
+	"join":        strings.Join,
+}
+
+// ModelTemplateData is passed to the model template
+type ModelTemplateData struct {
+	StructName string
+	TableName  string
+	NeedsTime  bool
+	// Imports lists the import paths needed by field types, besides time
+	Imports []string
+	Fields  []ModelTemplateField
+	// Relations are the optional belongs-to fields loaded on demand, like
+	// Author *User for an author_id foreign key
+	Relations []ModelTemplateField
+}
+
+// AllImports returns the sorted import paths of the model file
+func (d ModelTemplateData) AllImports() []string {
+	return mergeImports(d.NeedsTime, d.Imports)
+}
+
+type ModelTemplateField struct {
+	Name         string
+	Type         string
+	Column       string
+	IsPrimaryKey bool
+	// Tag is the complete struct tag, backquotes included
+	Tag string
+}
+
+// EnumTemplateData is passed to the enum template
+type EnumTemplateData struct {
+	// TypeName is the Go type, like Mood, and EnumType the database type,
+	// like mood
+	TypeName string
+	EnumType string
+	// Values lists the labels in database order
+	Values []EnumTemplateValue
+}
+
+// EnumTemplateValue is an enum label and the name of its constant, like
+// MoodHappy for happy
+type EnumTemplateValue struct {
+	Name  string
+	Value string
+}
+
+// DTOTemplateData is passed to the DTO template. Fields lists the fields of the
+// read DTO, CreateFields and UpdateFields those of the write DTOs.
+type DTOTemplateData struct {
+	StructName string
+	NeedsTime  bool
+	// Imports lists the import paths needed by field types, besides time
+	Imports      []string
+	Fields       []TemplateField
+	CreateFields []TemplateField
+	UpdateFields []TemplateField
+	PatchFields  []TemplateField
+	// Relations embed the parent DTOs requested with ?include=
+	Relations []RelationTemplateData
+}
+
+// AllImports returns the sorted import paths of the DTO file
+func (d DTOTemplateData) AllImports() []string {
+	return mergeImports(d.NeedsTime, d.Imports)
+}
+
+// mergeImports returns imports, with time when needsTime is set, sorted and
+// without duplicates
+func mergeImports(needsTime bool, imports []string) []string {
+	merged := slices.Clone(imports)
+	if needsTime {
+		merged = append(merged, "time")
+	}
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// TemplateField is a model field as seen by the DTO and resource templates
+type TemplateField struct {
+	Name    string
+	Type    string
+	JSONTag string
+	DBTag   string
+	// Nullable is set for pointer fields of the model, which PATCH sets to
+	// null when the body holds null
+	Nullable bool
+	// Validate holds the validation rules of the write DTOs
+	Validate string
+	// NullType is the model type of a field stored as a sql.Null* or Optional
+	// type, the DTOs holding a pointer
+	NullType string
+}
+
+// DTOValue returns the expression converting the model field expr, like
+// m.Bio, to the type of the DTO field
+func (f TemplateField) DTOValue(expr string) string {
+	return nullablePointer(expr, f.NullType)
+}
+
+// ModelValue returns the expression converting the DTO value expr, like
+// dto.Bio, to the type of the model field
+func (f TemplateField) ModelValue(expr string) string {
+	return nullableValue(expr, f.NullType)
+}
+
+// ModelValueOf returns the expression of the model field holding the value
+// expr, like user.UserID
+func (f TemplateField) ModelValueOf(expr string) string {
+	return nullableValueOf(expr, f.NullType, f.Nullable)
+}
+
+// ResourceTemplateData is passed to the resource template
+type ResourceTemplateData struct {
+	StructName      string
+	LowerStructName string
+	PluralName      string
+	ModelsImport    string
+	DTOsImport      string
+	// HooksImport is set when a hooks file exists for the resource
+	HooksImport string
+	// NeedsAuthMiddleware is set when at least one route requires authentication
+	NeedsAuthMiddleware bool
+	// UsesAuthContext is set when handlers need the gorest-auth package
+	UsesAuthContext bool
+	HasUserID       bool
+	// Context is the expression handlers pass as context to the CRUD layer
+	Context string
+	Routes  []RouteTemplateData
+	// Operations lists the operations exposed as routes and documented. The
+	// handlers of the other operations are still generated.
+	Operations    ResourceOperations
+	AllowedFields []string
+	DTOFields     []TemplateField
+	CreateFields  []TemplateField
+	UpdateFields  []TemplateField
+	// PatchFields are the fields of the patch DTO, empty when the resource
+	// has no Patch handler
+	PatchFields []TemplateField
+	// ValidateCreate, ValidateUpdate and ValidatePatch are set when the write
+	// DTOs carry validation rules
+	ValidateCreate bool
+	ValidateUpdate bool
+	ValidatePatch  bool
+	// HasNullablePatchFields is set when PATCH reads the body to tell null
+	// values from absent fields
+	HasNullablePatchFields bool
+	// HasUpdatedAt is set when the model has an updated_at column, refreshed
+	// by PATCH
+	HasUpdatedAt bool
+	// UserIDField is the UserId field filled in from the authenticated user
+	// when HasUserID is set
+	UserIDField TemplateField
+	// UsesSQLNull is set when model fields are stored in sql.Null* types
+	UsesSQLNull bool
+
+	// Key lists the primary key fields, empty for tables without a key which
+	// only get a List handler
+	Key []KeyTemplateField
+	// DefaultKey is set when the key is the single "id" column handled by the
+	// crud package. Other keys are read and written with the query builder.
+	DefaultKey bool
+	// KeyPath is the key part of item routes, like :order_id/:product_id, and
+	// KeyDocPath the same in Swagger form, like {order_id}/{product_id}
+	KeyPath    string
+	KeyDocPath string
+	// AutoIncrementKey is set for single integer keys, read back through
+	// LastInsertId on databases without RETURNING
+	AutoIncrementKey bool
+	// InsertColumns and UpdateColumns are the non-key columns written by the
+	// query builder handlers
+	InsertColumns []TemplateField
+	UpdateColumns []TemplateField
+	// Relations are the belongs-to relations of the model, listed from their
+	// parent by a nested route and embedded by List with ?include=
+	Relations []RelationTemplateData
+}
+
+// ResourceOperations flags the operations a resource exposes
+type ResourceOperations struct {
+	List   bool
+	Get    bool
+	Create bool
+	Update bool
+	Patch  bool
+	Delete bool
+}
+
+// RelationTemplateData is a belongs-to relation from a model to a parent model
+type RelationTemplateData struct {
+	// Name is the relation field, like Author, and IncludeName its JSON name
+	// accepted by ?include=
+	Name        string
+	IncludeName string
+	// Field and Column are the foreign key field and column, like AuthorId
+	// and author_id
+	Field          string
+	Column         string
+	FieldIsPointer bool
+	// FieldNullType is the sql.Null* or Optional type of a nullable foreign
+	// key field
+	FieldNullType string
+	ParentStruct  string
+	ParentColumn  string
+	ParentField   string
+	// ParentFieldIsPointer is set when the parent key field is a pointer
+	ParentFieldIsPointer bool
+	ParentSwaggerType    string
+	// Handler lists the children of a parent, like ListByAuthor. It is served
+	// at Path, like users/:id/posts, documented as DocPath, like users/{id}/posts.
+	Handler string
+	Path    string
+	DocPath string
+}
+
+// FieldRef returns the expression of the foreign key field of the model v,
+// a pointer when FieldIsPointer is set
+func (r RelationTemplateData) FieldRef(v string) string {
+	return nullablePointer(v+"."+r.Field, r.FieldNullType)
+}
+
+// KeyTemplateField is a primary key field of a resource
+type KeyTemplateField struct {
+	Name        string
+	Column      string
+	Type        string
+	SwaggerType string
+	Description string
+	// Zero is the Go zero value of Type, compared against to leave unset keys
+	// to the database default. Empty when the type has no comparable zero.
+	Zero string
+}
+
+type RouteTemplateData struct {
+	Method       string
+	Path         string
+	Handler      string
+	RequiresAuth bool
+}
+
+// RoutesTemplateData is passed to the routes template
+type RoutesTemplateData struct {
+	Resources []string
+}
+
+// templatesDir returns the directory holding template overrides, or an empty
+// string when none is configured
+func (g *Generator) templatesDir() (string, error) {
+	if g.Options == nil || g.Options.Templates == "" {
+		return "", nil
+	}
+	return g.outputDir(g.Options.Templates)
+}
+
+// loadTemplate returns the named template, preferring an override from the
+// configured templates directory over the embedded default
+func (g *Generator) loadTemplate(name string) (*template.Template, error) {
+	if tmpl, ok := g.templates[name]; ok {
+		return tmpl, nil
+	}
+
+	dir, err := g.templatesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var src []byte
+	if dir != "" {
+		src, err = os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, &TemplateError{Name: name, Err: err}
+		}
+	}
+	if src == nil {
+		src, err = defaultTemplates.ReadFile("templates/" + name)
+		if err != nil {
+			return nil, &TemplateError{Name: name, Err: err}
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(src))
+	if err != nil {
+		return nil, &TemplateError{Name: name, Err: err}
+	}
+
+	if g.templates == nil {
+		g.templates = make(map[string]*template.Template)
+	}
+	g.templates[name] = tmpl
+	return tmpl, nil
+}
+
+func (g *Generator) renderTemplate(name string, data any) (string, error) {
+	tmpl, err := g.loadTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", &TemplateError{Name: name, Err: err}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func newTemplateTestGenerator(t *testing.T, templatesDir string) *Generator {
+	t.Helper()
+	g := NewGenerator(&config.Config{}, NewOutput(true))
+	g.Options.Templates = templatesDir
+	return g
+}
+
+func TestRenderDefaultTemplates(t *testing.T) {
+	g := newTemplateTestGenerator(t, "")
+
+	tests := []struct {
+		name     string
+		data     any
+		expected []string
+	}{
+		{
+			name: ModelTemplate,
+			data: ModelTemplateData{
+				StructName: "User",
+				TableName:  "users",
+				NeedsTime:  true,
+				Fields: []ModelTemplateField{
+					{Name: "Id", Type: "int", Column: "id", Tag: "`json:\"id,omitempty\" db:\"id\"`"},
+				},
+			},
+			expected: []string{"package models", `import "time"`, "type User struct {", "\tId int `json:\"id,omitempty\" db:\"id\"`", `return "users"`},
+		},
+		{
+			name: DTOTemplate,
+			data: DTOTemplateData{
+				StructName:   "User",
+				Fields:       []TemplateField{{Name: "Id", Type: "int", JSONTag: "id"}},
+				CreateFields: []TemplateField{{Name: "Email", Type: "string", JSONTag: "email"}},
+			},
+			expected: []string{"package dtos", "type UserDTO struct {\n\tId int `json:\"id\"`\n}", "type UserCreateDTO struct {\n\tEmail string `json:\"email\"`\n}", "type UserUpdateDTO struct {\n}"},
+		},
+		{
+			name:     RoutesTemplate,
+			data:     RoutesTemplateData{Resources: []string{"User", "Todo"}},
+			expected: []string{"\tRegisterUserRoutes(app, db, paginationLimit, paginationMaxLimit, pluginRegistry)\n\tRegisterTodoRoutes("},
+		},
+		{
+			name:     ValidationTemplate,
+			expected: []string{"package resources", "func sendValidationError(c *fiber.Ctx, dto any, err error) error {"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := g.renderTemplate(tt.name, tt.data)
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(code, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, code)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	override := "// house header\npackage models\n\ntype {{.StructName}} struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, ModelTemplate), []byte(override), 0644); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+
+	g := newTemplateTestGenerator(t, dir)
+
+	code, err := g.renderTemplate(ModelTemplate, ModelTemplateData{StructName: "User"})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	if code != "// house header\npackage models\n\ntype User struct{}\n" {
+		t.Errorf("Expected overridden template output, got:\n%s", code)
+	}
+
+	// Templates missing from the directory fall back to the embedded defaults
+	code, err = g.renderTemplate(RoutesTemplate, RoutesTemplateData{})
+	if err != nil {
+		t.Fatalf("Failed to render default template: %v", err)
+	}
+	if !strings.Contains(code, "func RegisterGeneratedRoutes(") {
+		t.Errorf("Expected default routes template output, got:\n%s", code)
+	}
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ModelTemplate), []byte("{{.StructName"), 0644); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DTOTemplate), []byte("{{.Missing}}"), 0644); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+
+	g := newTemplateTestGenerator(t, dir)
+
+	for _, name := range []string{ModelTemplate, DTOTemplate} {
+		_, err := g.renderTemplate(name, ModelTemplateData{})
+		var tmplErr *TemplateError
+		if !errors.As(err, &tmplErr) {
+			t.Fatalf("Expected TemplateError for %s, got %v", name, err)
+		}
+		if tmplErr.Name != name {
+			t.Errorf("Expected template name %s, got %s", name, tmplErr.Name)
+		}
+	}
+}
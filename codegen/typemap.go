@@ -0,0 +1,107 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeMapper maps a column to the Go type of its model field. The type is
+// written like a go_type column override, with the import path of its package
+// unless it is registered, and is made nullable by the generator. ok is false
+// when the column is left to the next mapper.
+type TypeMapper interface {
+	GoType(col Column) (goType string, ok bool)
+}
+
+// TypeMapperFunc adapts a function to a TypeMapper
+type TypeMapperFunc func(col Column) (string, bool)
+
+func (f TypeMapperFunc) GoType(col Column) (string, bool) {
+	return f(col)
+}
+
+// TypeMap maps database type names, like numeric or a custom enum, to Go
+// types. Names are lower case and match the column type definition first, then
+// its type and finally the type without its length and attributes, so that
+// numeric(10,2) takes precedence over numeric. Configured names are lowered
+// when the options are loaded.
+type TypeMap map[string]string
+
+func (m TypeMap) GoType(col Column) (string, bool) {
+	if len(m) == 0 {
+		return "", false
+	}
+	base, _, _ := splitTypeDef(col.TypeDef)
+	for _, name := range []string{col.TypeDef, col.Type, base} {
+		if name == "" {
+			continue
+		}
+		if goType, ok := m[normalizeTypeName(name)]; ok {
+			return goType, true
+		}
+	}
+	return "", false
+}
+
+// validate checks the Go types of the mapping and lowers its database type
+// names. Names differing only in case must map to the same Go type.
+func (m *TypeMap) validate() error {
+	normalized := make(TypeMap, len(*m))
+	for _, dbType := range sortedKeys(*m) {
+		goType := (*m)[dbType]
+		if strings.TrimSpace(dbType) == "" {
+			return fmt.Errorf("missing database type for Go type %q", goType)
+		}
+		if _, _, err := parseGoType(goType); err != nil {
+			return fmt.Errorf("invalid Go type for database type %s: %w", dbType, err)
+		}
+		name := normalizeTypeName(dbType)
+		if other, ok := normalized[name]; ok && other != goType {
+			return fmt.Errorf("database type %s is mapped to both %s and %s", name, other, goType)
+		}
+		normalized[name] = goType
+	}
+	*m = normalized
+	return nil
+}
+
+// normalizeTypeName lowers a database type name and removes the spaces around
+// its attributes, so that NUMERIC(10, 2) reads numeric(10,2)
+func normalizeTypeName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.NewReplacer(", ", ",", "( ", "(", " )", ")").Replace(name)
+}
+
+// typeMappers returns the mappers of the generator in priority order: the Go
+// mappers, the configured types, the generated enum types, then the dialect
+// defaults
+func (g *Generator) typeMappers() []TypeMapper {
+	mappers := append([]TypeMapper(nil), g.TypeMappers...)
+	if g.Options != nil && len(g.Options.Types) > 0 {
+		mappers = append(mappers, g.Options.Types)
+	}
+	if len(g.enumTypes) > 0 {
+		mappers = append(mappers, TypeMapperFunc(func(col Column) (string, bool) {
+			name, ok := g.enumTypes[col.EnumType]
+			return name, ok
+		}))
+	}
+	return append(mappers, DialectTypeMapper(g.Dialect))
+}
+
+// columnGoType returns the Go type of a column's model field and the import
+// path it needs, empty for builtin types
+func (g *Generator) columnGoType(col Column) (goType, importPath string, err error) {
+	for _, mapper := range g.typeMappers() {
+		mapped, ok := mapper.GoType(col)
+		if !ok {
+			continue
+		}
+		typ, importPath, err := parseGoType(mapped)
+		if err != nil {
+			return "", "", err
+		}
+		return g.nullableGoType(typ, col.IsNullable), importPath, nil
+	}
+	return "", "", fmt.Errorf("no Go type for column type %s", col.Type)
+}
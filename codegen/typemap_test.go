@@ -0,0 +1,140 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestTypeMap(t *testing.T) {
+	types := TypeMap{"numeric": "decimal.Decimal", "Numeric(10, 2)": "float64", "MOOD": "github.com/acme/app/enums.Mood"}
+	if err := types.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		col      Column
+		expected string
+		ok       bool
+	}{
+		{"type", Column{Type: "numeric"}, "decimal.Decimal", true},
+		{"case insensitive", Column{Type: "mood"}, "github.com/acme/app/enums.Mood", true},
+		{"type definition base", Column{Type: "", TypeDef: "NUMERIC(12,2)"}, "decimal.Decimal", true},
+		{"type definition first", Column{Type: "numeric", TypeDef: "NUMERIC(10,2)"}, "float64", true},
+		{"unmapped", Column{Type: "text"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goType, ok := types.GoType(tt.col)
+			if goType != tt.expected || ok != tt.ok {
+				t.Errorf("GoType(%+v) = %s, %v; want %s, %v", tt.col, goType, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTypeMapValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		types TypeMap
+		err   string
+	}{
+		{"same Go type", TypeMap{"UUID": "uuid.UUID", "uuid": "uuid.UUID"}, ""},
+		{"conflicting case", TypeMap{"UUID": "uuid.UUID", "uuid": "string"}, "database type uuid is mapped to both uuid.UUID and string"},
+		{"missing database type", TypeMap{" ": "string"}, "missing database type"},
+		{"invalid Go type", TypeMap{"money": "acme.Money"}, "invalid Go type for database type money"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.types.validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(tt.types) != 1 || tt.types["uuid"] != "uuid.UUID" {
+					t.Errorf("Expected names lowered to uuid, got %v", tt.types)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestColumnGoTypeMappers(t *testing.T) {
+	g := NewGenerator(&config.Config{}, nil)
+	g.Options.Types = TypeMap{"uuid": "uuid.UUID", "numeric": "github.com/shopspring/decimal.Decimal"}
+	g.TypeMappers = []TypeMapper{TypeMapperFunc(func(col Column) (string, bool) {
+		return "encoding/json.RawMessage", col.Type == "jsonb" || col.Name == "external_id"
+	})}
+
+	tests := []struct {
+		col        Column
+		goType     string
+		importPath string
+	}{
+		{Column{Name: "id", Type: "uuid"}, "uuid.UUID", "github.com/google/uuid"},
+		{Column{Name: "price", Type: "numeric", IsNullable: true}, "*decimal.Decimal", "github.com/shopspring/decimal"},
+		{Column{Name: "payload", Type: "jsonb"}, "json.RawMessage", "encoding/json"},
+		{Column{Name: "external_id", Type: "uuid"}, "json.RawMessage", "encoding/json"},
+		{Column{Name: "title", Type: "text"}, "string", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.col.Name, func(t *testing.T) {
+			goType, importPath, err := g.columnGoType(tt.col)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if goType != tt.goType || importPath != tt.importPath {
+				t.Errorf("Expected %s from %q, got %s from %q", tt.goType, tt.importPath, goType, importPath)
+			}
+		})
+	}
+
+	g.TypeMappers = []TypeMapper{TypeMapperFunc(func(Column) (string, bool) { return "pgtype.Text", true })}
+	if _, _, err := g.columnGoType(Column{Name: "title", Type: "text"}); err == nil {
+		t.Error("Expected an error for a type of an unknown package")
+	}
+}
+
+func TestGenerateStructsTypeMappings(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+	g := NewGenerator(cfg, nil)
+	g.Options.Types = TypeMap{"uuid": "uuid.UUID", "numeric": "decimal.Decimal", "jsonb": "json.RawMessage"}
+	tables := map[string]TableSchema{
+		"orders": {TableName: "orders", Columns: []Column{
+			{Name: "id", Type: "uuid"},
+			{Name: "total", Type: "numeric"},
+			{Name: "details", Type: "jsonb", IsNullable: true},
+		}},
+	}
+	if err := g.GenerateStructs(tables); err != nil {
+		t.Fatalf("Failed to generate models: %v", err)
+	}
+
+	model, err := os.ReadFile(filepath.Join(dir, "models", "order.go"))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+	for _, want := range []string{
+		"import (\n\t\"encoding/json\"\n\n\t\"github.com/google/uuid\"\n\t\"github.com/shopspring/decimal\"\n)",
+		"Id      uuid.UUID        `json:\"id,omitempty\" db:\"id\" pk:\"true\"`",
+		"Total   decimal.Decimal  `json:\"total\" db:\"total\"`",
+		"Details *json.RawMessage `json:\"details,omitempty\" db:\"details\"`",
+	} {
+		if !strings.Contains(string(model), want) {
+			t.Errorf("Expected model to contain %q, got:\n%s", want, model)
+		}
+	}
+}
@@ -2,24 +2,58 @@ package codegen
 
 import "strings"
 
+// goTypeInfo describes a Go type fields are generated with: the import path
+// of its package and how it is documented in OpenAPI
+type goTypeInfo struct {
+	importPath    string
+	openAPIType   string
+	openAPIFormat string
+}
+
+// goTypes registers the Go types known to the generators, so that type
+// mappings can name them without their import path and OpenAPI documents
+// describe them
+var goTypes = map[string]goTypeInfo{
+	"int":                    {"", "integer", "int32"},
+	"int32":                  {"", "integer", "int32"},
+	"int64":                  {"", "integer", "int64"},
+	"int16":                  {"", "integer", "int32"},
+	"int8":                   {"", "integer", "int32"},
+	"uint":                   {"", "integer", "int64"},
+	"uint8":                  {"", "integer", "int32"},
+	"uint16":                 {"", "integer", "int32"},
+	"uint32":                 {"", "integer", "int64"},
+	"uint64":                 {"", "integer", "int64"},
+	"[]byte":                 {"", "string", "byte"},
+	"float32":                {"", "number", "float"},
+	"float64":                {"", "number", "double"},
+	"string":                 {"", "string", ""},
+	"bool":                   {"", "boolean", ""},
+	"time.Time":              {"time", "string", "date-time"},
+	"interface{}":            {"", "object", ""},
+	"map[string]interface{}": {"", "object", ""},
+	"json.RawMessage":        {"encoding/json", "object", ""},
+	"sql.NullString":         {"database/sql", "string", ""},
+	"sql.NullBool":           {"database/sql", "boolean", ""},
+	"sql.NullInt16":          {"database/sql", "integer", "int32"},
+	"sql.NullInt32":          {"database/sql", "integer", "int32"},
+	"sql.NullInt64":          {"database/sql", "integer", "int64"},
+	"sql.NullFloat64":        {"database/sql", "number", "double"},
+	"sql.NullTime":           {"database/sql", "string", "date-time"},
+	"uuid.UUID":              {"github.com/google/uuid", "string", "uuid"},
+	"decimal.Decimal":        {"github.com/shopspring/decimal", "string", "decimal"},
+}
+
+// GoTypeToOpenAPIType returns the OpenAPI type and format of a Go type.
+// Unknown types are documented as strings.
 func GoTypeToOpenAPIType(goType string) (string, string) {
 	goType = strings.TrimPrefix(goType, "*")
 
-	typeMap := map[string]struct{ typ, format string }{
-		"int":         {"integer", "int32"},
-		"int32":       {"integer", "int32"},
-		"int64":       {"integer", "int64"},
-		"int16":       {"integer", "int32"},
-		"float32":     {"number", "float"},
-		"float64":     {"number", "double"},
-		"string":      {"string", ""},
-		"bool":        {"boolean", ""},
-		"time.Time":   {"string", "date-time"},
-		"interface{}": {"object", ""},
+	if info, ok := goTypes[goType]; ok && info.openAPIType != "" {
+		return info.openAPIType, info.openAPIFormat
 	}
-
-	if mapping, ok := typeMap[goType]; ok {
-		return mapping.typ, mapping.format
+	if strings.HasPrefix(goType, "[]") {
+		return "array", ""
 	}
 	return "string", ""
 }
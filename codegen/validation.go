@@ -0,0 +1,97 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// columnValidation returns the validate tag rules enforcing the constraints of
+// a column, empty when there is nothing to check
+func columnValidation(col Column, goType string) string {
+	isPointer := strings.HasPrefix(goType, "*")
+	isString := strings.TrimPrefix(goType, "*") == "string"
+
+	var rules []string
+	// A zero number or boolean cannot be told apart from a missing value, so
+	// only strings and pointers are required
+	if !col.IsNullable && col.Default == "" && (isString || isPointer) {
+		rules = append(rules, "required")
+	}
+	if isString {
+		if isEmailColumn(col.Name) {
+			rules = append(rules, "email")
+		}
+		if oneOf := oneOfRule(col.EnumValues); oneOf != "" {
+			rules = append(rules, oneOf)
+		} else if col.MaxLength > 0 {
+			rules = append(rules, fmt.Sprintf("max=%d", col.MaxLength))
+		}
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+
+	// Nil pointers are left to the database
+	if isPointer && rules[0] != "required" {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+func isEmailColumn(name string) bool {
+	return name == "email" || strings.HasSuffix(name, "_email")
+}
+
+// hasRule reports whether the validate tag rules include rule
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutRule returns the validate tag rules without rule
+func withoutRule(rules, rule string) string {
+	var kept []string
+	for _, r := range strings.Split(rules, ",") {
+		if r != rule && r != "" {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 1 && kept[0] == "omitempty" {
+		return ""
+	}
+	return strings.Join(kept, ",")
+}
+
+// oneOfRule returns the oneof rule allowing values. Values which cannot be
+// written in a struct tag yield no rule.
+func oneOfRule(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		if value == "" || strings.ContainsAny(value, ",|'\"`\\\t\n") {
+			return ""
+		}
+		quoted[i] = value
+		if strings.Contains(value, " ") {
+			quoted[i] = "'" + value + "'"
+		}
+	}
+	return "oneof=" + strings.Join(quoted, " ")
+}
+
+// hasValidation reports whether any of fields carries validation rules
+func hasValidation(fields []TemplateField) bool {
+	for _, field := range fields {
+		if field.Validate != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package codegen
+
+import "testing"
+
+func TestColumnValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		col      Column
+		goType   string
+		expected string
+	}{
+		{name: "max length", col: Column{MaxLength: 255}, goType: "string", expected: "required,max=255"},
+		{name: "nullable max length", col: Column{IsNullable: true, MaxLength: 80}, goType: "*string", expected: "omitempty,max=80"},
+		{name: "enum", col: Column{MaxLength: 20, Default: "'draft'", EnumValues: []string{"draft", "in review"}}, goType: "string", expected: "oneof=draft 'in review'"},
+		{name: "enum value not expressible in a tag", col: Column{MaxLength: 20, Default: "'a,b'", EnumValues: []string{"a,b"}}, goType: "string", expected: "max=20"},
+		{name: "email", col: Column{Name: "email", MaxLength: 255}, goType: "string", expected: "required,email,max=255"},
+		{name: "nullable email", col: Column{Name: "contact_email", IsNullable: true}, goType: "*string", expected: "omitempty,email"},
+		{name: "not null pointer", col: Column{Name: "due_at"}, goType: "*time.Time", expected: "required"},
+		{name: "not null number", col: Column{MaxLength: 10}, goType: "int", expected: ""},
+		{name: "column with a default", col: Column{Default: "''"}, goType: "string", expected: ""},
+		{name: "nullable column", col: Column{IsNullable: true}, goType: "*string", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnValidation(tt.col, tt.goType); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VerifyProblem is a compile error found in the generated code, mapped back to
+// the table and column it was generated from when known
+type VerifyProblem struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+	// Table is the table of the model the file was generated from
+	Table string
+	// TableColumn is the column of the struct field holding the error
+	TableColumn string
+}
+
+func (p VerifyProblem) String() string {
+	s := fmt.Sprintf("%s:%d:%d: %s", p.Path, p.Line, p.Column, p.Message)
+	switch {
+	case p.TableColumn != "":
+		s += fmt.Sprintf(" (column %s.%s)", p.Table, p.TableColumn)
+	case p.Table != "":
+		s += fmt.Sprintf(" (table %s)", p.Table)
+	}
+	return s
+}
+
+// verifiedPackage is a package of generated code to type-check
+type verifiedPackage struct {
+	importPath string
+	files      []*ast.File
+}
+
+// verifiedModel locates the model a generated file belongs to
+type verifiedModel struct {
+	table string
+	// columns maps the field names of the model to their column
+	columns map[string]string
+}
+
+// Verify type-checks the generated models, DTOs and resources packages,
+// including the files generated earlier in the same run, and returns a
+// VerifyError listing the compile errors found. Packages of the project they
+// import, like hooks, are checked from source as well, so that they see the
+// generated types. Other dependencies are loaded from the export data built by
+// go list.
+func (g *Generator) Verify() error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return &ConfigError{Op: "find project root", Err: err}
+	}
+	moduleName := getModuleName()
+	modelsImport, dtosImport := g.packageImports()
+
+	dirs := []struct {
+		dir        func() (string, error)
+		importPath string
+	}{
+		{g.modelsDir, modelsImport},
+		{g.dtosDir, dtosImport},
+		{g.resourcesDir, ""},
+	}
+
+	var pkgs []*verifiedPackage
+	var modelFiles []*ast.File
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	for i, d := range dirs {
+		dir, err := d.dir()
+		if err != nil {
+			return err
+		}
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		files, err := g.parsePackage(fset, dir)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if i == 0 {
+			modelFiles = files
+		}
+		importPath := d.importPath
+		if importPath == "" {
+			importPath = moduleName + "/" + files[0].Name.Name
+		}
+		pkgs = append(pkgs, &verifiedPackage{importPath: importPath, files: files})
+	}
+
+	// Project packages imported by the generated code are loaded from source,
+	// the others are dependencies
+	sources := make(map[string]*verifiedPackage, len(pkgs))
+	for _, pkg := range pkgs {
+		sources[pkg.importPath] = pkg
+	}
+	var deps []string
+	visited := make(map[string]bool)
+	for queue := pkgs; len(queue) > 0; queue = queue[1:] {
+		for _, file := range queue[0].files {
+			for _, spec := range file.Imports {
+				importPath, err := strconv.Unquote(spec.Path.Value)
+				if err != nil || sources[importPath] != nil || visited[importPath] {
+					continue
+				}
+				visited[importPath] = true
+
+				if rel, ok := strings.CutPrefix(importPath, moduleName+"/"); ok {
+					files, err := g.parsePackage(fset, filepath.Join(projectRoot, filepath.FromSlash(rel)))
+					if err != nil {
+						return err
+					}
+					if len(files) > 0 {
+						pkg := &verifiedPackage{importPath: importPath, files: files}
+						sources[importPath] = pkg
+						queue = append(queue, pkg)
+						continue
+					}
+				}
+				deps = append(deps, importPath)
+			}
+		}
+	}
+	sort.Strings(deps)
+	exports, err := exportFiles(projectRoot, deps)
+	if err != nil {
+		return err
+	}
+
+	var problems []VerifyProblem
+	imp := &verifyImporter{
+		fset:    fset,
+		sources: sources,
+		checked: make(map[string]*types.Package),
+		gc: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			file, ok := exports[path]
+			if !ok || file == "" {
+				return nil, fmt.Errorf("package %s not found, run go mod tidy", path)
+			}
+			return os.Open(file)
+		}),
+	}
+	imp.conf = &types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			var typeErr types.Error
+			if errors.As(err, &typeErr) {
+				pos := typeErr.Fset.Position(typeErr.Pos)
+				problems = append(problems, VerifyProblem{
+					Path:    pos.Filename,
+					Line:    pos.Line,
+					Column:  pos.Column,
+					Message: typeErr.Msg,
+				})
+				return
+			}
+			problems = append(problems, VerifyProblem{Message: err.Error()})
+		},
+	}
+	// Packages are checked before the packages importing them
+	for _, pkg := range pkgs {
+		imp.check(pkg)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	models := g.verifiedModels(modelFiles)
+	for i, problem := range problems {
+		problems[i].Table, problems[i].TableColumn = locateProblem(fset, pkgs, models, problem)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Line < problems[j].Line
+	})
+	return &VerifyError{Problems: problems}
+}
+
+// parsePackage parses the Go files of dir, skipping tests. A missing
+// directory yields no files.
+func (g *Generator) parsePackage(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	names, err := g.Output.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &ParseError{Path: dir, Err: err}
+	}
+
+	var files []*ast.File
+	for _, name := range names {
+		if filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		src, err := g.Output.ReadFile(path)
+		if err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// exportFiles builds the dependencies with go list and returns the export data
+// file of every package they need, keyed by import path. Packages which cannot
+// be built have no export data and are reported as failed imports.
+func exportFiles(projectRoot string, deps []string) (map[string]string, error) {
+	exports := make(map[string]string)
+	if len(deps) == 0 {
+		return exports, nil
+	}
+
+	args := append([]string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}"}, deps...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, &ConfigError{Op: "load dependencies of generated code", Err: fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if importPath, file, ok := strings.Cut(scanner.Text(), "\t"); ok {
+			exports[importPath] = file
+		}
+	}
+	return exports, nil
+}
+
+// verifyImporter checks the packages loaded from source on their first import
+// and loads every other package from its export data
+type verifyImporter struct {
+	fset    *token.FileSet
+	conf    *types.Config
+	sources map[string]*verifiedPackage
+	checked map[string]*types.Package
+	gc      types.Importer
+}
+
+func (imp *verifyImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := imp.sources[path]; ok {
+		return imp.check(pkg)
+	}
+	return imp.gc.Import(path)
+}
+
+// check type-checks a package once, reporting its errors through the config
+func (imp *verifyImporter) check(pkg *verifiedPackage) (*types.Package, error) {
+	if checked, ok := imp.checked[pkg.importPath]; ok {
+		if checked == nil {
+			return nil, fmt.Errorf("import cycle through %s", pkg.importPath)
+		}
+		return checked, nil
+	}
+	imp.checked[pkg.importPath] = nil
+	// Errors are reported through the config, the package being usable anyway
+	checked, _ := imp.conf.Check(pkg.importPath, imp.fset, pkg.files, nil)
+	imp.checked[pkg.importPath] = checked
+	return checked, nil
+}
+
+// verifiedModels indexes the model structs of the models package by the base
+// name of their file, user.go holding the User model
+func (g *Generator) verifiedModels(files []*ast.File) map[string]verifiedModel {
+	models := make(map[string]verifiedModel)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+					continue
+				}
+				table, err := g.modelTable(typeSpec.Name.Name)
+				if err != nil {
+					continue
+				}
+				fields, err := g.modelFields(typeSpec.Name.Name)
+				if err != nil {
+					continue
+				}
+				model := verifiedModel{table: table, columns: make(map[string]string)}
+				for _, field := range fields {
+					if field.DBTag != "" {
+						model.columns[field.Name] = field.DBTag
+					}
+				}
+				models[strings.ToLower(typeSpec.Name.Name)] = model
+			}
+		}
+	}
+	return models
+}
+
+// locateProblem returns the table of the file holding the problem and the
+// column of the model field it involves: the innermost struct field, keyed
+// field or field selector, like item.Email in a handler or conversion
+// function, enclosing its position. Problems on other expressions of a line
+// referencing a single such field fall back to that field.
+func locateProblem(fset *token.FileSet, pkgs []*verifiedPackage, models map[string]verifiedModel, problem VerifyProblem) (table, column string) {
+	model, ok := models[strings.TrimSuffix(filepath.Base(problem.Path), ".go")]
+	if !ok {
+		return "", ""
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.files {
+			tokenFile := fset.File(file.Pos())
+			if tokenFile == nil || tokenFile.Name() != problem.Path || problem.Line < 1 || problem.Line > tokenFile.LineCount() {
+				continue
+			}
+			pos := tokenFile.LineStart(problem.Line) + token.Pos(max(problem.Column-1, 0))
+
+			var enclosing string
+			onLine := make(map[string]bool)
+			ast.Inspect(file, func(n ast.Node) bool {
+				if n == nil {
+					return false
+				}
+				var name string
+				switch n := n.(type) {
+				case *ast.Field:
+					if len(n.Names) > 0 {
+						name = n.Names[0].Name
+					}
+				case *ast.KeyValueExpr:
+					if key, ok := n.Key.(*ast.Ident); ok {
+						name = key.Name
+					}
+				case *ast.SelectorExpr:
+					name = n.Sel.Name
+				}
+				c, ok := model.columns[name]
+				if !ok {
+					return true
+				}
+				// Nodes are visited from the outside in
+				if n.Pos() <= pos && pos < n.End() {
+					enclosing = c
+				}
+				if start, end := fset.Position(n.Pos()), fset.Position(n.End()); problem.Line >= start.Line && problem.Line <= end.Line {
+					onLine[c] = true
+				}
+				return true
+			})
+			if enclosing != "" {
+				return model.table, enclosing
+			}
+			if len(onLine) == 1 {
+				for c := range onLine {
+					return model.table, c
+				}
+			}
+		}
+	}
+	return model.table, ""
+}
@@ -0,0 +1,129 @@
+package codegen
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolasbonnici/gorest/config"
+)
+
+func TestVerify(t *testing.T) {
+	tables := map[string]TableSchema{
+		"users": {TableName: "users", Columns: []Column{
+			{Name: "id", Type: "integer"},
+			{Name: "email", Type: "text"},
+			{Name: "created_at", Type: "timestamp", IsNullable: true},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		columns map[string]ColumnOverride
+		problem VerifyProblem
+	}{
+		{
+			name: "valid models",
+		},
+		{
+			name:    "unknown column type",
+			columns: map[string]ColumnOverride{"users.email": {GoType: "Email"}},
+			problem: VerifyProblem{Message: "undefined: Email", Table: "users", TableColumn: "email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &config.Config{}
+			cfg.Codegen.Output.Models = filepath.Join(dir, "models")
+			cfg.Codegen.Output.DTOs = filepath.Join(dir, "dtos")
+			cfg.Codegen.Output.Resources = filepath.Join(dir, "resources")
+			g := NewGenerator(cfg, NewOutput(true))
+			g.Options.Columns = tt.columns
+			if err := g.GenerateStructs(tables); err != nil {
+				t.Fatalf("Failed to generate models: %v", err)
+			}
+
+			err := g.Verify()
+			if tt.problem.Message == "" {
+				if err != nil {
+					t.Fatalf("Expected generated code to compile, got %v", err)
+				}
+				return
+			}
+
+			var verifyErr *VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("Expected VerifyError, got %v", err)
+			}
+			if len(verifyErr.Problems) != 1 {
+				t.Fatalf("Expected 1 problem, got %v", verifyErr.Problems)
+			}
+			problem := verifyErr.Problems[0]
+			if problem.Path != filepath.Join(dir, "models", "user.go") {
+				t.Errorf("Expected problem in the user model, got %s", problem.Path)
+			}
+			if problem.Message != tt.problem.Message {
+				t.Errorf("Expected message %q, got %q", tt.problem.Message, problem.Message)
+			}
+			if problem.Table != tt.problem.Table || problem.TableColumn != tt.problem.TableColumn {
+				t.Errorf("Expected problem mapped to %s.%s, got %s.%s", tt.problem.Table, tt.problem.TableColumn, problem.Table, problem.TableColumn)
+			}
+			if !strings.Contains(err.Error(), "(column users.email)") {
+				t.Errorf("Expected error to name the column, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestLocateProblem(t *testing.T) {
+	src := `package resources
+
+func (r *UserResource) Create(c *fiber.Ctx) error {
+	user := models.User{
+		Email: dto.Email,
+	}
+	qb := query.Insert("users").
+		Set("email", user.Email).
+		Set("age", convert(user.Age))
+	log(user.Id, user.Email)
+	return nil
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "/project/resources/user.go", src, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	pkgs := []*verifiedPackage{{importPath: "example/resources", files: []*ast.File{file}}}
+	models := map[string]verifiedModel{
+		"user": {table: "users", columns: map[string]string{"Id": "id", "Email": "email", "Age": "age"}},
+	}
+
+	tests := []struct {
+		name   string
+		line   int
+		column int
+		want   string
+	}{
+		{"keyed field", 5, 10, "email"},
+		{"selector", 8, 16, "email"},
+		{"other expression on the line", 9, 10, "age"},
+		{"several fields on the line", 10, 2, ""},
+		{"no field", 11, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := VerifyProblem{Path: "/project/resources/user.go", Line: tt.line, Column: tt.column}
+			table, column := locateProblem(fset, pkgs, models, problem)
+			if table != "users" || column != tt.want {
+				t.Errorf("Expected users.%s, got %s.%s", tt.want, table, column)
+			}
+		})
+	}
+}
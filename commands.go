@@ -1,10 +1,14 @@
 package codegen
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/nicolasbonnici/gorest-codegen/codegen"
 	"github.com/nicolasbonnici/gorest/config"
+	"github.com/nicolasbonnici/gorest/database"
 	"github.com/nicolasbonnici/gorest/plugin"
 )
 
@@ -22,19 +26,23 @@ func (c *ModelsCommand) Description() string {
 }
 
 func (c *ModelsCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
-	ctx.ProgressCallback("Loading database schema...")
-	tables := codegen.LoadSchema(c.plugin.db)
+	return c.plugin.runGeneration(ctx, c.Name(), "Model generation", c.run)
+}
+
+func (c *ModelsCommand) run(ctx *plugin.CommandContext, g *codegen.Generator) error {
+	ctx.ProgressCallback("Loading schema...")
+	tables, err := g.LoadSchema(c.plugin.db)
+	if err != nil {
+		return err
+	}
 
 	ctx.ProgressCallback("Generating model structs...")
-	codegen.GenerateStructs(tables)
+	if err := g.GenerateStructs(tables); err != nil {
+		return err
+	}
 
 	ctx.ProgressCallback("Models generated successfully")
-
-	return &plugin.CommandResult{
-		Success:      true,
-		FilesCreated: []string{"generated/models/*.go"},
-		Message:      "Model generation completed successfully",
-	}
+	return nil
 }
 
 // ResourcesCommand generates REST API resources and DTOs from models
@@ -51,46 +59,20 @@ func (c *ResourcesCommand) Description() string {
 }
 
 func (c *ResourcesCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
-	ctx.ProgressCallback("Loading configuration...")
-
-	// Try to get config from plugin first, fallback to context
-	var cfg *config.Config
-	if c.plugin.appConfig != nil {
-		cfg = c.plugin.appConfig
-	} else if ctx.Config != nil {
-		if contextCfg, ok := ctx.Config.(*config.Config); ok {
-			cfg = contextCfg
-		}
-	}
-
-	if cfg == nil {
-		// Load from current directory as fallback
-		loadedCfg, err := config.Load(".")
-		if err != nil {
-			return &plugin.CommandResult{
-				Success: false,
-				Error:   fmt.Errorf("failed to load config: %w", err),
-			}
-		}
-		cfg = loadedCfg
-	}
+	return c.plugin.runGeneration(ctx, c.Name(), "Resource generation", c.run)
+}
 
+func (c *ResourcesCommand) run(ctx *plugin.CommandContext, g *codegen.Generator) error {
 	ctx.ProgressCallback("Building authentication configuration...")
-	authCfg := codegen.GetAuthConfigFromConfig(cfg)
+	authCfg := codegen.GetAuthConfigFromConfig(g.Config)
 
 	ctx.ProgressCallback("Generating API resources...")
-	codegen.GenerateAPI(authCfg)
+	if err := g.GenerateAPI(authCfg); err != nil {
+		return err
+	}
 
 	ctx.ProgressCallback("Resources generated successfully")
-
-	return &plugin.CommandResult{
-		Success: true,
-		FilesCreated: []string{
-			"generated/resources/*.go",
-			"generated/dtos/*.go",
-		},
-		Message: "Resource generation completed successfully",
-	}
+	return nil
 }
 
 // OpenAPICommand generates OpenAPI schema file
@@ -107,17 +89,54 @@ func (c *OpenAPICommand) Description() string {
 }
 
 func (c *OpenAPICommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
+	return c.plugin.runGeneration(ctx, c.Name(), "OpenAPI generation", c.run)
+}
+
+func (c *OpenAPICommand) run(ctx *plugin.CommandContext, g *codegen.Generator) error {
 	ctx.ProgressCallback("Generating OpenAPI schema...")
-	tables := codegen.LoadSchema(c.plugin.db)
-	codegen.GenerateOpenAPI(tables)
+	tables, err := g.LoadSchema(c.plugin.db)
+	if err != nil {
+		return err
+	}
+	if err := g.GenerateOpenAPI(tables); err != nil {
+		return err
+	}
 
 	ctx.ProgressCallback("OpenAPI schema generated successfully")
+	return nil
+}
+
+// VerifyCommand type-checks the generated code
+type VerifyCommand struct {
+	plugin *CodegenPlugin
+}
 
-	return &plugin.CommandResult{
-		Success:      true,
-		FilesCreated: []string{"generated/openapi/schema.yaml"},
-		Message:      "OpenAPI generation completed successfully",
+func (c *VerifyCommand) Name() string {
+	return "verify"
+}
+
+func (c *VerifyCommand) Description() string {
+	return "Type-check the generated models, DTOs and resources"
+}
+
+func (c *VerifyCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
+	opts, err := parseCommandOptions(c.Name(), ctx.Args)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
 	}
+	g, _, err := c.plugin.newGenerator(ctx, opts)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	if err := c.run(ctx, g); err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	return &plugin.CommandResult{Success: true, Message: "Generated code compiles"}
+}
+
+func (c *VerifyCommand) run(ctx *plugin.CommandContext, g *codegen.Generator) error {
+	ctx.ProgressCallback("Type-checking generated code...")
+	return g.Verify()
 }
 
 // AllCommand runs all code generation steps
@@ -130,40 +149,367 @@ func (c *AllCommand) Name() string {
 }
 
 func (c *AllCommand) Description() string {
-	return "Run all code generation steps (models, resources, openapi)"
+	return "Run all code generation steps (models, resources, openapi), and verify the generated code with --verify"
 }
 
 func (c *AllCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
-	ctx.ProgressCallback("Running: models")
-	modelsCmd := &ModelsCommand{plugin: c.plugin}
-	modelsResult := modelsCmd.Run(ctx)
-	if !modelsResult.Success {
-		return modelsResult
+	opts, err := parseCommandOptions(c.Name(), ctx.Args)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
 	}
+	return c.plugin.runGeneration(ctx, c.Name(), "All code generation", func(ctx *plugin.CommandContext, g *codegen.Generator) error {
+		return c.run(ctx, g, opts.Verify)
+	})
+}
 
-	ctx.ProgressCallback("Running: resources")
-	resourcesCmd := &ResourcesCommand{plugin: c.plugin}
-	resourcesResult := resourcesCmd.Run(ctx)
-	if !resourcesResult.Success {
-		return resourcesResult
+func (c *AllCommand) run(ctx *plugin.CommandContext, g *codegen.Generator, verify bool) error {
+	// All steps share the generator so that, in dry-run mode, resources are
+	// generated from the models computed by the first step
+	steps := []struct {
+		name string
+		run  func(*plugin.CommandContext, *codegen.Generator) error
+	}{
+		{"models", (&ModelsCommand{plugin: c.plugin}).run},
+		{"resources", (&ResourcesCommand{plugin: c.plugin}).run},
+		{"openapi", (&OpenAPICommand{plugin: c.plugin}).run},
 	}
 
-	ctx.ProgressCallback("Running: openapi")
-	openapiCmd := &OpenAPICommand{plugin: c.plugin}
-	openapiResult := openapiCmd.Run(ctx)
-	if !openapiResult.Success {
-		return openapiResult
+	// Files modified by hand do not stop the following steps
+	var conflicts []string
+	for _, step := range steps {
+		ctx.ProgressCallback("Running: " + step.name)
+		err := step.run(ctx, g)
+		var conflictErr *codegen.ConflictError
+		if errors.As(err, &conflictErr) {
+			conflicts = append(conflicts, conflictErr.Paths...)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if len(conflicts) > 0 {
+		err = &codegen.ConflictError{Paths: conflicts}
+	}
+	// Type-checking needs the dependencies of the project to be downloaded,
+	// which a fresh project may not have yet, so it is opt-in
+	if verify {
+		ctx.ProgressCallback("Running: verify")
+		verifyErr := (&VerifyCommand{plugin: c.plugin}).run(ctx, g)
+		var problems *codegen.VerifyError
+		if errors.As(verifyErr, &problems) {
+			return errors.Join(err, verifyErr)
+		}
+		if verifyErr != nil {
+			return verifyErr
+		}
 	}
+	return err
+}
 
-	// Combine all created files
-	allFiles := make([]string, 0)
-	allFiles = append(allFiles, modelsResult.FilesCreated...)
-	allFiles = append(allFiles, resourcesResult.FilesCreated...)
-	allFiles = append(allFiles, openapiResult.FilesCreated...)
+// SchemaCommand manages the schema snapshot code can be generated from
+type SchemaCommand struct {
+	plugin *CodegenPlugin
+}
+
+func (c *SchemaCommand) Name() string {
+	return "schema"
+}
+
+func (c *SchemaCommand) Description() string {
+	return "Dump the database schema to a snapshot file (schema dump)"
+}
+
+func (c *SchemaCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
+	if len(ctx.Args) == 0 || ctx.Args[0] != "dump" {
+		return &plugin.CommandResult{Success: false, Error: errors.New("expected subcommand: schema dump")}
+	}
+	dumpCtx := *ctx
+	dumpCtx.Args = ctx.Args[1:]
+	return c.plugin.runGeneration(&dumpCtx, c.Name()+" dump", "Schema snapshot dump", c.dump)
+}
+
+func (c *SchemaCommand) dump(ctx *plugin.CommandContext, g *codegen.Generator) error {
+	ctx.ProgressCallback("Dumping schema snapshot...")
+	return g.DumpSchema(c.plugin.db)
+}
+
+// MigrateCommand generates SQL migrations from the changes made to the models
+type MigrateCommand struct {
+	plugin *CodegenPlugin
+}
+
+func (c *MigrateCommand) Name() string {
+	return "migrate"
+}
+
+func (c *MigrateCommand) Description() string {
+	return "Generate up and down SQL migrations taking the database schema to the models"
+}
+
+func (c *MigrateCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
+	opts, err := parseCommandOptions(c.Name(), ctx.Args)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	return c.plugin.runGeneration(ctx, c.Name(), "Migration generation", func(ctx *plugin.CommandContext, g *codegen.Generator) error {
+		return c.run(ctx, g, opts.Name)
+	})
+}
+
+func (c *MigrateCommand) run(ctx *plugin.CommandContext, g *codegen.Generator, name string) error {
+	ctx.ProgressCallback("Loading schema...")
+	tables, err := g.LoadSchema(c.plugin.db)
+	if err != nil {
+		return err
+	}
+
+	ctx.ProgressCallback("Comparing models with the schema...")
+	return g.GenerateMigration(tables, name)
+}
 
-	return &plugin.CommandResult{
-		Success:      true,
-		FilesCreated: allFiles,
-		Message:      "All code generation completed successfully",
+// CheckCommand reports the drift between the database schema and the models
+type CheckCommand struct {
+	plugin *CodegenPlugin
+}
+
+func (c *CheckCommand) Name() string {
+	return "check"
+}
+
+func (c *CheckCommand) Description() string {
+	return "Check that the models match the database schema, failing on drift"
+}
+
+func (c *CheckCommand) Run(ctx *plugin.CommandContext) *plugin.CommandResult {
+	opts, err := parseCommandOptions(c.Name(), ctx.Args)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	g, _, err := c.plugin.newGenerator(ctx, opts)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	if err := c.run(ctx, g); err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	return &plugin.CommandResult{Success: true, Message: "Models match the database schema"}
+}
+
+func (c *CheckCommand) run(ctx *plugin.CommandContext, g *codegen.Generator) error {
+	ctx.ProgressCallback("Loading schema...")
+	tables, err := g.LoadSchema(c.plugin.db)
+	if err != nil {
+		return err
+	}
+
+	ctx.ProgressCallback("Comparing models with the schema...")
+	return g.CheckSchema(tables)
+}
+
+// loadConfig returns the application config injected into the plugin, falling
+// back to the command context and finally to gorest.yaml in the current directory
+func (p *CodegenPlugin) loadConfig(ctx *plugin.CommandContext) (*config.Config, error) {
+	if p.appConfig != nil {
+		return p.appConfig, nil
+	}
+	if contextCfg, ok := ctx.Config.(*config.Config); ok && contextCfg != nil {
+		return contextCfg, nil
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return nil, &codegen.ConfigError{Op: "load config", Err: err}
+	}
+	return cfg, nil
+}
+
+// newGenerator loads the configuration and returns a generator set up from
+// the command options, along with the project root
+func (p *CodegenPlugin) newGenerator(ctx *plugin.CommandContext, opts *commandOptions) (*codegen.Generator, string, error) {
+	ctx.ProgressCallback("Loading configuration...")
+	cfg, err := p.loadConfig(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	projectRoot, err := codegen.FindProjectRoot()
+	if err != nil {
+		return nil, "", &codegen.ConfigError{Op: "find project root", Err: err}
+	}
+	codegenOpts, err := codegen.LoadOptions(projectRoot)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Schema files and migrations given on the command line are relative to
+	// the working directory and replace the schema sources of gorest.yaml
+	if len(opts.SchemaFiles) > 0 || len(opts.Migrations) > 0 {
+		codegenOpts.SchemaFiles, codegenOpts.Migrations = nil, nil
+		for _, pattern := range opts.SchemaFiles {
+			abs, err := filepath.Abs(pattern)
+			if err != nil {
+				return nil, "", &codegen.ConfigError{Op: "resolve schema files", Err: err}
+			}
+			codegenOpts.SchemaFiles = append(codegenOpts.SchemaFiles, abs)
+		}
+		for _, dir := range opts.Migrations {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, "", &codegen.ConfigError{Op: "resolve migrations", Err: err}
+			}
+			codegenOpts.Migrations = append(codegenOpts.Migrations, abs)
+		}
+	}
+
+	// Likewise for the snapshot
+	if opts.SchemaSnapshot != "" {
+		abs, err := filepath.Abs(opts.SchemaSnapshot)
+		if err != nil {
+			return nil, "", &codegen.ConfigError{Op: "resolve schema snapshot", Err: err}
+		}
+		codegenOpts.SchemaSnapshot = abs
+	}
+
+	g := codegen.NewGenerator(cfg, codegen.NewOutput(opts.DryRun))
+	g.Options = codegenOpts
+	g.TypeMappers = p.typeMappers
+	g.Force = opts.Force
+	g.DropTables = opts.DropTables
+	g.Tables = opts.Tables
+	switch {
+	case p.db != nil:
+		g.Dialect = p.db.DriverName()
+	case cfg.Database.URL != "":
+		// Schema files are read in the dialect of the configured database,
+		// without connecting to it
+		g.Dialect = database.DetectDriver(cfg.Database.URL)
+	}
+	return g, projectRoot, nil
+}
+
+// NeedsDatabase reports whether the command run with args reads the schema
+// from the database, so that the CLI only connects when it has to. Resources
+// are generated from the models on disk, and a schema snapshot, schema files
+// or migrations set with flags or in gorest.yaml replace the database. Schema
+// dumps read the schema files, migrations or database, never the snapshot
+// they write.
+func NeedsDatabase(command string, args []string) bool {
+	switch command {
+	case "models", "openapi", "all", "migrate", "check":
+	case "schema":
+		if len(args) > 0 {
+			args = args[1:]
+		}
+	default:
+		return false
+	}
+	dump := command == "schema"
+
+	// Invalid flags and options are reported when the command runs
+	opts, err := parseCommandOptions(command, args)
+	if err != nil {
+		return true
+	}
+	if len(opts.SchemaFiles) > 0 || len(opts.Migrations) > 0 || (opts.SchemaSnapshot != "" && !dump) {
+		return false
+	}
+	projectRoot, err := codegen.FindProjectRoot()
+	if err != nil {
+		return true
+	}
+	codegenOpts, err := codegen.LoadOptions(projectRoot)
+	if err != nil {
+		return true
+	}
+	offline := len(codegenOpts.SchemaFiles) > 0 || len(codegenOpts.Migrations) > 0 || (codegenOpts.SchemaSnapshot != "" && !dump)
+	return !offline
+}
+
+// runGeneration parses the command flags, runs the generation step named by
+// task and reports the files it produced
+func (p *CodegenPlugin) runGeneration(ctx *plugin.CommandContext, name, task string, run func(*plugin.CommandContext, *codegen.Generator) error) *plugin.CommandResult {
+	opts, err := parseCommandOptions(name, ctx.Args)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+	g, projectRoot, err := p.newGenerator(ctx, opts)
+	if err != nil {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+
+	// Files modified by hand and compile errors of the generated code are
+	// reported along with the files generated
+	var conflictErr *codegen.ConflictError
+	var verifyErr *codegen.VerifyError
+	if err := run(ctx, g); err != nil && !errors.As(err, &conflictErr) && !errors.As(err, &verifyErr) {
+		return &plugin.CommandResult{Success: false, Error: err}
+	}
+
+	out := g.Output
+	created := out.Paths(projectRoot, codegen.ChangeCreated)
+	updated := out.Paths(projectRoot, codegen.ChangeUpdated)
+	removed := out.Paths(projectRoot, codegen.ChangeRemoved)
+	unchanged := out.Paths(projectRoot, codegen.ChangeUnchanged)
+
+	var b strings.Builder
+	if out.DryRun {
+		fmt.Fprintf(&b, "Dry run: %d file(s) would be created, %d updated, %d removed, %d unchanged",
+			len(created), len(updated), len(removed), len(unchanged))
+		for _, path := range created {
+			fmt.Fprintf(&b, "\n  + %s", path)
+		}
+		for _, path := range updated {
+			fmt.Fprintf(&b, "\n  ~ %s", path)
+		}
+	} else {
+		status := "completed successfully"
+		if conflictErr != nil || verifyErr != nil {
+			status = "failed"
+		}
+		fmt.Fprintf(&b, "%s %s: %d file(s) created, %d updated, %d removed, %d unchanged",
+			task, status, len(created), len(updated), len(removed), len(unchanged))
+	}
+	for _, path := range removed {
+		fmt.Fprintf(&b, "\n  - %s", path)
+	}
+	if conflictErr != nil {
+		fmt.Fprintf(&b, "\n%d file(s) modified by hand left untouched, use --force to overwrite:", len(conflictErr.Paths))
+		for _, path := range conflictErr.Paths {
+			if rel, err := filepath.Rel(projectRoot, path); err == nil {
+				path = filepath.ToSlash(rel)
+			}
+			fmt.Fprintf(&b, "\n  ! %s", path)
+		}
+	}
+
+	result := &plugin.CommandResult{Success: true}
+	if !out.DryRun {
+		result.FilesCreated = created
+		result.FilesModified = updated
+	}
+	if opts.Diff {
+		if diff := out.Diff(projectRoot); diff != "" {
+			b.WriteString("\n\n")
+			b.WriteString(strings.TrimSuffix(diff, "\n"))
+		}
+		if out.HasChanges() {
+			// A non-empty diff means the generated code on disk is stale
+			result.Success = false
+			result.Error = &codegen.OutOfDateError{Paths: append(append(created, updated...), removed...)}
+		}
+	}
+	if conflictErr != nil {
+		// A stale diff is still reported along with the conflicts
+		result.Success = false
+		result.Error = errors.Join(result.Error, conflictErr)
+	}
+	if verifyErr != nil {
+		result.Success = false
+		result.Error = errors.Join(result.Error, verifyErr)
 	}
+	result.Message = b.String()
+	return result
 }
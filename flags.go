@@ -0,0 +1,92 @@
+package codegen
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// commandOptions holds the flags shared by the generation commands
+type commandOptions struct {
+	DryRun bool
+	Diff   bool
+	Force  bool
+	// Tables lists the glob patterns of the tables selected with --tables
+	Tables []string
+	// SchemaFiles lists the SQL files set with --schema-files, read instead
+	// of the database
+	SchemaFiles []string
+	// Migrations lists the migration directories set with --migrations
+	Migrations []string
+	// SchemaSnapshot is the snapshot set with --schema-snapshot
+	SchemaSnapshot string
+	// Name is the name of the migration written by migrate
+	Name string
+	// DropTables lets migrate drop the tables without a model
+	DropTables bool
+	// Verify type-checks the generated code at the end of all
+	Verify bool
+}
+
+func parseCommandOptions(name string, args []string) (*commandOptions, error) {
+	opts := &commandOptions{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "compute generated files without writing them")
+	fs.BoolVar(&opts.Diff, "diff", false, "print a unified diff of generated files against disk without writing them")
+	fs.BoolVar(&opts.Force, "force", false, "overwrite generated files modified since they were generated")
+	fs.Func("tables", "comma-separated glob patterns of the tables to generate", func(value string) error {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+			}
+			opts.Tables = append(opts.Tables, pattern)
+		}
+		return nil
+	})
+
+	fs.Func("schema-files", "comma-separated SQL files or glob patterns to read the schema from instead of the database", func(value string) error {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern == "" {
+				continue
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid schema file pattern %q: %w", pattern, err)
+			}
+			opts.SchemaFiles = append(opts.SchemaFiles, pattern)
+		}
+		return nil
+	})
+
+	fs.Func("migrations", "comma-separated migration directories to apply to an in-memory SQLite database instead of reading the database", func(value string) error {
+		for _, dir := range strings.Split(value, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				opts.Migrations = append(opts.Migrations, dir)
+			}
+		}
+		return nil
+	})
+
+	fs.StringVar(&opts.SchemaSnapshot, "schema-snapshot", "", "schema snapshot to generate from, or to write with schema dump")
+	fs.StringVar(&opts.Name, "name", "schema", "name of the migration written by migrate")
+	fs.BoolVar(&opts.DropTables, "drop-tables", false, "let migrate drop the tables without a model")
+	fs.BoolVar(&opts.Verify, "verify", false, "type-check the generated code once all steps ran")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// A diff is always computed without touching the files on disk
+	if opts.Diff {
+		opts.DryRun = true
+	}
+
+	return opts, nil
+}
@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/nicolasbonnici/gorest-codegen/codegen"
 	"github.com/nicolasbonnici/gorest/config"
 	"github.com/nicolasbonnici/gorest/database"
 	"github.com/nicolasbonnici/gorest/plugin"
@@ -9,8 +10,9 @@ import (
 
 // CodegenPlugin implements the plugin.Plugin interface
 type CodegenPlugin struct {
-	db        database.Database
-	appConfig *config.Config
+	db          database.Database
+	appConfig   *config.Config
+	typeMappers []codegen.TypeMapper
 }
 
 // NewPlugin creates a new instance of the codegen plugin
@@ -23,7 +25,8 @@ func (p *CodegenPlugin) Name() string {
 	return "codegen"
 }
 
-// Initialize initializes the plugin with configuration
+// Initialize initializes the plugin with configuration. Go type mappers given
+// as type_mappers take precedence over the types of gorest.yaml.
 func (p *CodegenPlugin) Initialize(cfg map[string]any) error {
 	// Extract injected dependencies
 	if db, ok := cfg["database"].(database.Database); ok {
@@ -32,6 +35,9 @@ func (p *CodegenPlugin) Initialize(cfg map[string]any) error {
 	if appConfig, ok := cfg["config"].(*config.Config); ok {
 		p.appConfig = appConfig
 	}
+	if mappers, ok := cfg["type_mappers"].([]codegen.TypeMapper); ok {
+		p.typeMappers = mappers
+	}
 	return nil
 }
 
@@ -48,6 +54,10 @@ func (p *CodegenPlugin) Commands() []plugin.Command {
 		&ModelsCommand{plugin: p},
 		&ResourcesCommand{plugin: p},
 		&OpenAPICommand{plugin: p},
+		&VerifyCommand{plugin: p},
 		&AllCommand{plugin: p},
+		&SchemaCommand{plugin: p},
+		&MigrateCommand{plugin: p},
+		&CheckCommand{plugin: p},
 	}
 }